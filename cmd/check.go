@@ -17,114 +17,228 @@ limitations under the License.
 package cmd
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
+	"time"
 
-	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/util/homedir"
 
+	"github.com/lightstep/collector-cluster-check/pkg/load"
+	"github.com/lightstep/collector-cluster-check/pkg/otlp"
+	"github.com/lightstep/collector-cluster-check/pkg/report"
 	"github.com/lightstep/collector-cluster-check/pkg/steps"
+	"github.com/lightstep/collector-cluster-check/pkg/steps/dependencies"
 	"github.com/lightstep/collector-cluster-check/pkg/steps/dns"
 	"github.com/lightstep/collector-cluster-check/pkg/steps/kubernetes"
+	"github.com/lightstep/collector-cluster-check/pkg/steps/logs"
 	"github.com/lightstep/collector-cluster-check/pkg/steps/metrics"
 	"github.com/lightstep/collector-cluster-check/pkg/steps/otel"
+	"github.com/lightstep/collector-cluster-check/pkg/steps/throughput"
 	"github.com/lightstep/collector-cluster-check/pkg/steps/traces"
 )
 
 var (
-	kubeConfig      string
-	accessToken     string
-	endpoint        string
-	http            bool
-	insecure        bool
+	kubeConfig  string
+	accessToken string
+	endpoint    string
+	http        bool
+	insecure    bool
+	parallelism int
+	timeout     time.Duration
+	attempts    int
+	output      string
+	outputFile  string
+	failOn      string
+	destination []string
+	proxy       string
+	probeImage  string
+	probeNS     string
+	header      []string
+	profile     string
+	caFile      string
+	certFile    string
+	keyFile     string
+	serverName  string
+	compression string
+	temporality string
+	loopbackImg string
+	colConfig   string
+	colMode     string
+	sidecarImg  string
+	colEnv      []string
+	colSecrets  []string
+	matrixTrans []string
+	matrixSec   []string
+	loadOpts    load.Options
+	// runID tags every signal sent by this invocation, so the loopback check can tell it apart from other runs'
+	runID = newRunID()
+
+	metricsSteps = []steps.Step{
+		metrics.CreateCounter{},
+		metrics.ShutdownMeter{},
+		metrics.ExportInstruments{},
+	}
+	tracingSteps = []steps.Step{
+		traces.StartTrace{},
+		traces.ShutdownTracer{},
+	}
+	logsSteps = []steps.Step{
+		logs.EmitLog{},
+		logs.ShutdownLogger{},
+	}
+	preflightSteps = []steps.Step{
+		kubernetes.Version{},
+		kubernetes.NewCrdExists(steps.CertManagerCrdName),
+		kubernetes.NewCrdExists(steps.OtelCrdName),
+		kubernetes.NewCrdExists(steps.ServiceMonitorCrdName),
+		kubernetes.NewPodRunning(steps.OtelOperatorSelector),
+		kubernetes.NewPodRunning(steps.CertManagerSelector),
+	}
+	dnsSteps = []steps.Step{
+		dns.IPLookup{},
+		dns.Ping{},
+		dns.Dial{},
+		dns.TLSHandshake{},
+		dns.ProxyDetection{},
+		dns.ProxyDial{},
+	}
+	// inflightSteps depend on each other through the collector they create, so they always run in order
+	inflightSteps = []steps.Step{
+		kubernetes.NewCrdExists(steps.OtelCrdName),
+		otel.LintCollector{},
+		otel.CreateCollector{},
+		otel.PodWatcher{},
+		kubernetes.StartPortForward{Port: 4317, LabelSelector: steps.LabelSelector},
+		metrics.NewCreateCounter("localhost:4317", true),
+		metrics.NewShutdownMeter("localhost:4317", true),
+		traces.NewStartTrace("localhost:4317", true),
+		traces.NewShutdownTracer("localhost:4317", true),
+		logs.NewEmitLog("localhost:4317", true),
+		logs.NewShutdownLogger("localhost:4317", true),
+		kubernetes.FinishPortForward{Port: 4317, LabelSelector: steps.LabelSelector},
+		kubernetes.StartPortForward{Port: 8888, LabelSelector: steps.LabelSelector},
+		otel.QueryCollector{},
+		kubernetes.FinishPortForward{Port: 8888, LabelSelector: steps.LabelSelector},
+	}
+
+	// collectorLoadSteps generate the same load as the load check through the test collector
+	collectorLoadSteps = []steps.Step{
+		kubernetes.NewCrdExists(steps.OtelCrdName),
+		otel.LintCollector{},
+		otel.CreateCollector{},
+		otel.PodWatcher{},
+		kubernetes.StartPortForward{Port: 4317, LabelSelector: steps.LabelSelector},
+		throughput.NewGenerateLoad("localhost:4317", true),
+		kubernetes.FinishPortForward{Port: 4317, LabelSelector: steps.LabelSelector},
+	}
+
+	// loopbackSteps send telemetry through the test collector, which exports a copy back to a receiver on this machine
+	loopbackSteps = []steps.Step{
+		kubernetes.NewCrdExists(steps.OtelCrdName),
+		otel.StartLoopback{},
+		otel.LintCollector{},
+		otel.CreateCollector{},
+		otel.PodWatcher{},
+		kubernetes.StartPortForward{Port: 4317, LabelSelector: steps.LabelSelector},
+		metrics.NewCreateCounter("localhost:4317", true),
+		metrics.NewShutdownMeter("localhost:4317", true),
+		traces.NewStartTrace("localhost:4317", true),
+		traces.NewShutdownTracer("localhost:4317", true),
+		logs.NewEmitLog("localhost:4317", true),
+		logs.NewShutdownLogger("localhost:4317", true),
+		kubernetes.FinishPortForward{Port: 4317, LabelSelector: steps.LabelSelector},
+		otel.VerifyLoopback{},
+	}
+
 	availableChecks = map[string]*steps.Check{
 		"metrics": steps.NewCheck(
 			"metrics",
-			"Initializes a meter, creates a counter, flushes metrics",
-			[]steps.Step{
-				metrics.CreateCounter{},
-				metrics.ShutdownMeter{},
-			}),
+			"Initializes a meter, creates a counter, flushes metrics, then exports every kind of instrument one at a time",
+			metricsSteps),
 		"tracing": steps.NewCheck(
 			"tracing",
 			"Initializes a trace provider, starts and finishes a trace, flushes the trace",
-			[]steps.Step{
-				traces.StartTrace{},
-				traces.ShutdownTracer{},
-			}),
+			tracingSteps),
+		"logs": steps.NewCheck(
+			"logs",
+			"Initializes a logger provider, emits a log record, flushes the log record",
+			logsSteps),
+		"otlp": steps.NewCheck(
+			"otlp",
+			"Sends empty OTLP export requests over gRPC and HTTP and explains how the endpoint answered",
+			[]steps.Step{otel.ExportProbe{}}),
+		"matrix": steps.NewCheck(
+			"matrix",
+			"Sends a span, a metric point and a log record over every --matrix-transports and --matrix-security setting and reports a grid of which the endpoint accepts and how fast",
+			[]steps.Step{otel.TransportMatrix{}}),
+		// the load through the collector waits for the direct load to finish, however it went, so they don't compete for
+		// this machine
+		"load": steps.NewCheck(
+			"load",
+			"Generates spans, metric points and log records at the --load rates, directly to the endpoint then through a test collector, and reports throughput, export latency and drops",
+			[]steps.Step{throughput.GenerateLoad{}}).
+			After(collectorLoadSteps).
+			WithFinalizers(otel.DeleteCollector{}),
 		"preflight": steps.NewCheck(
 			"preflight",
 			"Runs preflight checks to ensure that a collector CRD can be created",
-			[]steps.Step{
-				kubernetes.Version{},
-				kubernetes.NewCrdExists(steps.CertManagerCrdName),
-				kubernetes.NewCrdExists(steps.OtelCrdName),
-				kubernetes.NewCrdExists(steps.ServiceMonitorCrdName),
-				kubernetes.NewPodRunning(steps.OtelOperatorSelector),
-				kubernetes.NewPodRunning(steps.CertManagerSelector),
-			}),
+			steps.Independent(preflightSteps...)...),
 		"dns": steps.NewCheck(
 			"dns",
-			"Runs basic DNS checks to verify a connection to Lightstep from your local machine",
-			[]steps.Step{
-				dns.IPLookup{},
-				dns.Ping{},
-				dns.Dial{},
-			}),
+			"Runs basic DNS checks to verify a connection to the endpoint, and any extra destination, from your local machine",
+			steps.Independent(dnsSteps...)...),
+		"incluster": steps.NewCheck(
+			"incluster",
+			"Runs the DNS lookup, dial and TLS handshake from a probe pod inside the cluster, next to the same checks from your local machine",
+			[]steps.Step{dns.InClusterProbe{}}),
 		"inflight": steps.NewCheck(
 			"inflight",
 			"Creates a collector, sends telemetry, queries that the telemetry was sent successfully to Lightstep",
-			[]steps.Step{
-				kubernetes.NewCrdExists(steps.OtelCrdName),
-				otel.CreateCollector{},
-				otel.PodWatcher{},
-				kubernetes.StartPortForward{Port: 4317, LabelSelector: steps.LabelSelector},
-				metrics.NewCreateCounter("localhost:4317", true),
-				metrics.NewShutdownMeter("localhost:4317", true),
-				traces.NewStartTrace("localhost:4317", true),
-				traces.NewShutdownTracer("localhost:4317", true),
-				kubernetes.FinishPortForward{Port: 4317, LabelSelector: steps.LabelSelector},
-				kubernetes.StartPortForward{Port: 8888, LabelSelector: steps.LabelSelector},
-				otel.QueryCollector{},
-				kubernetes.FinishPortForward{Port: 8888, LabelSelector: steps.LabelSelector},
-				otel.DeleteCollector{},
-			}),
+			inflightSteps).
+			WithFinalizers(otel.DeleteCollector{}),
+		"lint": steps.NewCheck(
+			"lint",
+			"Checks the test collector's config, embedded or from --collector-config, without creating the collector",
+			[]steps.Step{otel.LintCollector{}}),
+		"loopback": steps.NewCheck(
+			"loopback",
+			"Creates a collector that also exports to a receiver on this machine, sends telemetry tagged with a run ID, and verifies that every signal came back intact",
+			loopbackSteps).
+			WithFinalizers(otel.DeleteCollector{}),
+		// the inflight steps replace the meter, tracer and logger providers, so they wait for the other lanes to finish,
+		// but not for them to succeed, and skip when the cluster has no collector CRD
 		"all": steps.NewCheck(
 			"all",
 			"Runs every available step",
-			[]steps.Step{
-				kubernetes.Version{},
-				kubernetes.NewCrdExists(steps.CertManagerCrdName),
-				kubernetes.NewCrdExists(steps.OtelCrdName),
-				kubernetes.NewCrdExists(steps.ServiceMonitorCrdName),
-				kubernetes.NewPodRunning(steps.OtelOperatorSelector),
-				kubernetes.NewPodRunning(steps.CertManagerSelector),
-				metrics.CreateCounter{},
-				metrics.ShutdownMeter{},
-				traces.StartTrace{},
-				traces.ShutdownTracer{},
-				dns.IPLookup{},
-				dns.Ping{},
-				dns.Dial{},
-				otel.CreateCollector{},
-				otel.PodWatcher{},
-				kubernetes.StartPortForward{Port: 4317, LabelSelector: steps.LabelSelector},
-				metrics.NewCreateCounter("localhost:4317", true),
-				metrics.NewShutdownMeter("localhost:4317", true),
-				traces.NewStartTrace("localhost:4317", true),
-				traces.NewShutdownTracer("localhost:4317", true),
-				kubernetes.FinishPortForward{Port: 4317, LabelSelector: steps.LabelSelector},
-				kubernetes.StartPortForward{Port: 8888, LabelSelector: steps.LabelSelector},
-				otel.QueryCollector{},
-				kubernetes.FinishPortForward{Port: 8888, LabelSelector: steps.LabelSelector},
-				otel.DeleteCollector{},
-			}),
+			allLanes()...).
+			After(inflightSteps).
+			WithFinalizers(otel.DeleteCollector{}),
 	}
 )
 
+// allLanes runs every preflight and dns step on its own, alongside the metrics, tracing, logs, export probe and in-cluster lanes
+func allLanes() [][]steps.Step {
+	lanes := steps.Independent(preflightSteps...)
+	lanes = append(lanes, steps.Independent(dnsSteps...)...)
+	return append(lanes, metricsSteps, tracingSteps, logsSteps, []steps.Step{otel.ExportProbe{}}, []steps.Step{dns.InClusterProbe{}})
+}
+
+// newRunID returns a random ID that is unique enough to tell runs apart
+func newRunID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
 func getValidChecks() string {
 	toReturn := "check ["
 	for k := range availableChecks {
@@ -166,70 +280,197 @@ var checkCmd = &cobra.Command{
 		}
 		return comps, cobra.ShellCompDirectiveNoFileComp
 	},
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := report.ParseFormat(output)
+		if err != nil {
+			return err
+		}
+		failOnStatus, err := report.ParseFailOn(failOn)
+		if err != nil {
+			return err
+		}
+		// Cancelling the context stops the remaining steps, finalizers still run before we exit
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			// Restore the default behavior so a second signal exits immediately
+			stop()
+		}()
+		r := report.New()
 		for _, c := range args {
+			if ctx.Err() != nil {
+				break
+			}
 			group := availableChecks[c]
-			conf := GetConfig()
+			conf, err := GetConfig(cmd)
+			if err != nil {
+				return err
+			}
 			deps := steps.NewDependencies()
-			depResults, checkResults := group.Run(cmd.Context(), deps, conf)
-			prettyPrintDependenciesResults(depResults)
-			prettyPrint(checkResults)
+			depResults, checkResults := group.Run(ctx, deps, conf)
+			r.Add(group.Name(), depResults, checkResults)
+		}
+		if err := writeReport(r, format); err != nil {
+			return err
 		}
+		code := r.ExitCode(failOnStatus)
+		if ctx.Err() != nil {
+			// an interrupted run is incomplete, whatever the steps that did run reported
+			code = report.ExitError
+		}
+		if code != report.ExitPass {
+			os.Exit(code)
+		}
+		return nil
 	},
 }
 
-func prettyPrintDependenciesResults(checkResults []steps.Results) {
-	t := table.NewWriter()
-	rowConfigAutoMerge := table.RowConfig{AutoMerge: true}
-	t.AppendHeader(table.Row{"dependency", "Result", "Message", "Error"})
-	t.SetColumnConfigs([]table.ColumnConfig{
-		{Number: 1, AutoMerge: true},
-	})
-	for _, results := range checkResults {
-		for _, result := range results.Steps() {
-			prettyResult := "🟩"
-			if !result.Successful() {
-				prettyResult = "🟥"
-			}
-			t.AppendRow(table.Row{results.StepName(), prettyResult, result.Message(), result.Err()}, rowConfigAutoMerge)
-		}
+// writeReport renders the report to the output file when one is set, or to stdout
+func writeReport(r *report.Report, format report.Format) error {
+	if outputFile == "" {
+		return r.Render(os.Stdout, format)
 	}
-	t.SetOutputMirror(os.Stdout)
-	t.SetStyle(table.StyleLight)
-	t.Style().Options.SeparateRows = true
-	t.Render()
+	f, err := os.Create(outputFile)
+	if err != nil {
+		return err
+	}
+	if err := r.Render(f, format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
 }
 
-func prettyPrint(checkResults []steps.Results) {
-	t := table.NewWriter()
-	rowConfigAutoMerge := table.RowConfig{AutoMerge: true}
-	t.AppendHeader(table.Row{"Checker", "Result", "Message", "Error"})
-	t.SetColumnConfigs([]table.ColumnConfig{
-		{Number: 1, AutoMerge: true},
-	})
-	for _, results := range checkResults {
-		for _, result := range results.Steps() {
-			prettyResult := "🟩"
-			if !result.Successful() {
-				prettyResult = "🟥"
-			}
-			t.AppendRow(table.Row{results.StepName(), prettyResult, result.Message(), result.Err()}, rowConfigAutoMerge)
+// namedPolicy is how a step or dependency policy is written in the config file, e.g.
+//
+//	policies:
+//	  - name: PodWatcher
+//	    timeout: 5m
+//	    maxAttempts: 2
+type namedPolicy struct {
+	Name         string `mapstructure:"name"`
+	steps.Policy `mapstructure:",squash"`
+}
+
+func getPolicies() (map[string]steps.Policy, error) {
+	var configured []namedPolicy
+	if err := viper.UnmarshalKey("policies", &configured); err != nil {
+		return nil, fmt.Errorf("invalid policies in config file: %w", err)
+	}
+	policies := map[string]steps.Policy{}
+	for _, p := range configured {
+		policies[p.Name] = p.Policy
+	}
+	return policies, nil
+}
+
+// getProfile returns the profile picked with --profile from the config file, or the default profile
+func getProfile() (steps.Profile, error) {
+	if profile == "" || profile == steps.DefaultProfile.Name {
+		return steps.DefaultProfile, nil
+	}
+	var configured []steps.Profile
+	if err := viper.UnmarshalKey("profiles", &configured); err != nil {
+		return steps.Profile{}, fmt.Errorf("invalid profiles in config file: %w", err)
+	}
+	for _, p := range configured {
+		if p.Name == profile {
+			return p, nil
 		}
 	}
-	t.SetOutputMirror(os.Stdout)
-	t.SetStyle(table.StyleLight)
-	t.Style().Options.SeparateRows = true
-	t.Render()
+	return steps.Profile{}, fmt.Errorf("profile %q not found in config file", profile)
 }
 
-func GetConfig() *steps.Config {
-	return &steps.Config{
-		Endpoint:   endpoint,
-		Insecure:   insecure,
-		Http:       http,
-		Token:      accessToken,
-		KubeConfig: kubeConfig,
+func GetConfig(cmd *cobra.Command) (*steps.Config, error) {
+	policies, err := getPolicies()
+	if err != nil {
+		return nil, err
+	}
+	p, err := getProfile()
+	if err != nil {
+		return nil, err
 	}
+	extra, err := steps.ParseHeaders(header)
+	if err != nil {
+		return nil, err
+	}
+	// the flags win over the profile when they're set
+	resolvedEndpoint := endpoint
+	if !cmd.Flags().Changed("endpoint") && p.Endpoint != "" {
+		resolvedEndpoint = p.Endpoint
+	}
+	c, err := steps.ParseCompression(compression)
+	if err != nil {
+		return nil, err
+	}
+	t, err := steps.ParseTemporality(temporality)
+	if err != nil {
+		return nil, err
+	}
+	transports := make([]otlp.Transport, len(matrixTrans))
+	for i, tr := range matrixTrans {
+		if transports[i], err = otlp.ParseTransport(tr); err != nil {
+			return nil, err
+		}
+	}
+	security := make([]steps.Security, len(matrixSec))
+	for i, sec := range matrixSec {
+		if security[i], err = steps.ParseSecurity(sec); err != nil {
+			return nil, err
+		}
+	}
+	mode, err := steps.ParseCollectorMode(colMode)
+	if err != nil {
+		return nil, err
+	}
+	collectorEnv, err := steps.ParseCollectorEnv(colEnv, colSecrets)
+	if err != nil {
+		return nil, err
+	}
+	// the flag wins over the config file's collectorConfig key
+	collectorConfigFile := colConfig
+	if !cmd.Flags().Changed("collector-config") {
+		collectorConfigFile = viper.GetString("collectorConfig")
+	}
+	token := accessToken
+	if !cmd.Flags().Changed("accessToken") {
+		token = p.Token()
+	}
+	return &steps.Config{
+		Endpoint: resolvedEndpoint,
+		RunID:    runID,
+		Insecure: insecure,
+		Http:     http,
+		Headers:  p.ExportHeaders(token, extra),
+		TLS: steps.ExporterTLS{
+			CAFile:     caFile,
+			CertFile:   certFile,
+			KeyFile:    keyFile,
+			ServerName: serverName,
+		},
+		Compression:         c,
+		Temporality:         t,
+		Load:                loadOpts,
+		KubeConfig:          kubeConfig,
+		Destinations:        destination,
+		Proxy:               proxy,
+		ProbeImage:          probeImage,
+		ProbeNamespace:      probeNS,
+		LoopbackImage:       loopbackImg,
+		CollectorConfigFile: collectorConfigFile,
+		CollectorEnv:        collectorEnv,
+		CollectorMode:       mode,
+		SidecarImage:        sidecarImg,
+		MatrixTransports:    transports,
+		MatrixSecurity:      security,
+		Parallelism:         parallelism,
+		DefaultPolicy: steps.Policy{
+			Timeout:     timeout,
+			MaxAttempts: attempts,
+		},
+		Policies: policies,
+	}, nil
 }
 
 func init() {
@@ -240,10 +481,42 @@ func init() {
 	} else {
 		checkCmd.PersistentFlags().StringVarP(&kubeConfig, "kubeconfig", "", "", "absolute path to the kubeconfig file")
 	}
-	checkCmd.PersistentFlags().StringVarP(&accessToken, "accessToken", "", os.Getenv("LS_TOKEN"), "access token to send data to Lightstep")
-	checkCmd.PersistentFlags().StringVarP(&endpoint, "endpoint", "", "ingest.lightstep.com:443", "destination for OTLP data")
+	checkCmd.PersistentFlags().StringVarP(&accessToken, "accessToken", "", os.Getenv("LS_TOKEN"), "access token sent in the profile's token header, read from the profile's tokenEnv when not set")
+	checkCmd.PersistentFlags().StringVarP(&endpoint, "endpoint", "", steps.DefaultProfile.Endpoint, "destination for OTLP data, the profile's endpoint is used when not set")
+	checkCmd.PersistentFlags().StringArrayVarP(&header, "header", "", nil, "header sent with every export request as key=value, may be repeated")
+	checkCmd.PersistentFlags().StringVarP(&profile, "profile", "", "", "backend profile from the config file, the default sends telemetry to Lightstep")
+	checkCmd.PersistentFlags().StringArrayVarP(&destination, "destination", "", nil, "extra destination for the dns checks to probe as host:port or a URL, may be repeated")
 	checkCmd.PersistentFlags().BoolVarP(&http, "http", "", false, "should telemetry be sent over http")
 	checkCmd.PersistentFlags().BoolVarP(&insecure, "insecure", "", false, "should telemetry be sent insecurely")
+	checkCmd.PersistentFlags().StringVarP(&caFile, "ca-file", "", "", "PEM CA bundle that verifies the endpoint instead of the system roots")
+	checkCmd.PersistentFlags().StringVarP(&certFile, "cert-file", "", "", "PEM client certificate for endpoints that require mTLS, needs --key-file")
+	checkCmd.PersistentFlags().StringVarP(&keyFile, "key-file", "", "", "PEM key of the client certificate")
+	checkCmd.PersistentFlags().StringVarP(&serverName, "server-name", "", "", "name the endpoint's certificate is verified against instead of its host")
+	checkCmd.PersistentFlags().StringVarP(&compression, "compression", "", "", "compression of export requests, one of none|gzip, every exporter's default when not set")
+	checkCmd.PersistentFlags().StringVarP(&temporality, "temporality", "", string(steps.TemporalityCumulative), "temporality of exported sums and histograms, one of cumulative|delta")
+	checkCmd.PersistentFlags().IntVarP(&loadOpts.SpansPerSecond, "load-spans", "", 100, "spans per second the load check generates")
+	checkCmd.PersistentFlags().IntVarP(&loadOpts.MetricPointsPerSecond, "load-metric-points", "", 100, "metric measurements per second the load check generates")
+	checkCmd.PersistentFlags().IntVarP(&loadOpts.LogRecordsPerSecond, "load-log-records", "", 100, "log records per second the load check generates")
+	checkCmd.PersistentFlags().DurationVarP(&loadOpts.Duration, "load-duration", "", 10*time.Second, "how long the load check generates telemetry for")
+	checkCmd.PersistentFlags().IntVarP(&loadOpts.Cardinality, "load-cardinality", "", 10, "distinct values of the series attribute of the generated telemetry")
+	checkCmd.PersistentFlags().IntVarP(&loadOpts.PayloadSize, "load-payload-size", "", 128, "bytes of payload in every generated span and log record")
+	checkCmd.PersistentFlags().StringVarP(&probeImage, "probe-image", "", dependencies.DefaultProbeImage, "image of the in-cluster probe pod, it must have sh and curl")
+	checkCmd.PersistentFlags().StringVarP(&probeNS, "probe-namespace", "", apiv1.NamespaceDefault, "namespace of the in-cluster probe pod and the loopback relay")
+	checkCmd.PersistentFlags().StringVarP(&loopbackImg, "loopback-image", "", dependencies.DefaultLoopbackImage, "image of the relay that sends the test collector's exports back to the loopback check, socat must be its entrypoint")
+	checkCmd.PersistentFlags().StringVarP(&colConfig, "collector-config", "", "", "collector config file the test collector runs instead of the embedded one, read from the config file's collectorConfig when not set")
+	checkCmd.PersistentFlags().StringVarP(&colMode, "collector-mode", "", string(steps.CollectorModeDeployment), "how the operator deploys the test collector, one of deployment|daemonset|statefulset|sidecar")
+	checkCmd.PersistentFlags().StringVarP(&sidecarImg, "sidecar-image", "", dependencies.DefaultSidecarImage, "image of the pod the test collector is injected into with --collector-mode sidecar")
+	checkCmd.PersistentFlags().StringArrayVarP(&colEnv, "collector-env", "", nil, "env var of the test collector as NAME=value, resolves ${NAME} in the collector config, may be repeated")
+	checkCmd.PersistentFlags().StringArrayVarP(&colSecrets, "collector-env-secret", "", nil, "env var of the test collector read from a Secret as NAME=secret/key, may be repeated")
+	checkCmd.PersistentFlags().StringSliceVarP(&matrixTrans, "matrix-transports", "", []string{string(otlp.TransportGRPC), string(otlp.TransportHTTPProtobuf), string(otlp.TransportHTTPJSON)}, "transports the matrix check sends every signal over, any of grpc|http/protobuf|http/json")
+	checkCmd.PersistentFlags().StringSliceVarP(&matrixSec, "matrix-security", "", []string{string(steps.SecurityTLS), string(steps.SecurityInsecure)}, "security settings the matrix check sends every signal with, any of tls|insecure")
+	checkCmd.PersistentFlags().StringVarP(&proxy, "proxy", "", "", "proxy for telemetry and the dns checks, replaces HTTPS_PROXY and HTTP_PROXY while NO_PROXY still applies")
+	checkCmd.PersistentFlags().IntVarP(&parallelism, "parallelism", "", 4, "how many independent steps may run at the same time")
+	checkCmd.PersistentFlags().StringVarP(&output, "output", "o", string(report.FormatTable), "output format, one of table|json|yaml|junit|markdown")
+	checkCmd.PersistentFlags().StringVarP(&outputFile, "output-file", "", "", "write the report to this file instead of stdout")
+	checkCmd.PersistentFlags().StringVarP(&failOn, "fail-on", "", "fail", "lowest status that makes the command exit as failed, one of warn|fail")
+	checkCmd.PersistentFlags().DurationVarP(&timeout, "timeout", "", 0, "default timeout for every attempt of a step, steps may declare their own")
+	checkCmd.PersistentFlags().IntVarP(&attempts, "attempts", "", 0, "default number of attempts for a failing step, steps may declare their own")
 	checkCmd.SetHelpFunc(func(command *cobra.Command, i []string) {
 		// If help was called only on the base command
 		command.Println(checkCmd.UsageString())
@@ -22,6 +22,8 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+
+	"github.com/lightstep/collector-cluster-check/pkg/report"
 )
 
 var cfgFile string
@@ -43,7 +45,7 @@ create OpenTelemetry Collectors, and send data to your desired destination.`,
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		os.Exit(report.ExitError)
 	}
 }
 
@@ -0,0 +1,387 @@
+// Package colconfig finds the mistakes in a collector configuration that would otherwise only show up as a collector
+// pod that never becomes ready.
+package colconfig
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Rule names a kind of finding
+type Rule string
+
+const (
+	RuleUndefinedComponent Rule = "undefined-component"
+	RuleUnusedComponent    Rule = "unused-component"
+	RuleUnsetEnv           Rule = "unset-env"
+	RulePortCollision      Rule = "port-collision"
+	RuleMemoryLimiter      Rule = "memory-limiter"
+	RuleBatch              Rule = "batch"
+)
+
+// Finding is a problem of the configuration. Fatal findings keep the collector from starting, the others are how
+// collectors are usually run in production.
+type Finding struct {
+	Rule    Rule
+	Fatal   bool
+	Message string
+	Help    string
+}
+
+// envPlaceholder matches ${NAME}, ${env:NAME} and ${env:NAME:-default}, placeholders with a default don't need a value
+var envPlaceholder = regexp.MustCompile(`\$\{(?:env:)?([A-Za-z_][A-Za-z0-9_]*)(:-[^}]*)?\}`)
+
+// defaultPorts are where components listen when their config has no endpoint
+var defaultPorts = map[string]string{
+	"health_check": "13133",
+	"zpages":       "55679",
+	"pprof":        "1777",
+}
+
+// defaultProtocolPorts are where the otlp receiver's protocols listen when they have no endpoint
+var defaultProtocolPorts = map[string]string{
+	"grpc": "4317",
+	"http": "4318",
+}
+
+// telemetryPort is where the collector serves its own metrics unless its telemetry says otherwise
+const telemetryPort = "8888"
+
+// Lint checks a collector configuration, env are the names of the environment variables the collector runs with.
+// Findings are grouped by what was checked, pipelines and components are checked in name order.
+func Lint(config map[string]interface{}, env []string) []Finding {
+	var findings []Finding
+	findings = append(findings, lintReferences(config)...)
+	findings = append(findings, lintEnv(config, env)...)
+	findings = append(findings, lintPorts(config)...)
+	findings = append(findings, lintProcessors(config)...)
+	return findings
+}
+
+// componentKinds are the sections a pipeline references, connectors are both exporters and receivers
+var componentKinds = []string{"receivers", "processors", "exporters"}
+
+// lintReferences finds pipelines that reference components that aren't defined and components that aren't used
+func lintReferences(config map[string]interface{}) []Finding {
+	var findings []Finding
+	connectors := section(config, "connectors")
+	all := pipelines(config)
+	used := map[string]map[string]bool{"connectors": {}}
+	for _, kind := range componentKinds {
+		used[kind] = map[string]bool{}
+		defined := section(config, kind)
+		for _, id := range sortedKeys(all) {
+			for _, name := range names(all[id][kind]) {
+				switch {
+				case hasKey(defined, name):
+					used[kind][name] = true
+				case kind != "processors" && hasKey(connectors, name):
+					used["connectors"][name] = true
+				default:
+					findings = append(findings, Finding{
+						Rule:    RuleUndefinedComponent,
+						Fatal:   true,
+						Message: fmt.Sprintf("pipeline %s uses %s %s, which isn't defined", id, strings.TrimSuffix(kind, "s"), name),
+						Help:    fmt.Sprintf("define %s under %s or remove it from the pipeline", name, kind),
+					})
+				}
+			}
+		}
+	}
+	extensions := section(config, "extensions")
+	used["extensions"] = map[string]bool{}
+	for _, name := range names(service(config)["extensions"]) {
+		if !hasKey(extensions, name) {
+			findings = append(findings, Finding{
+				Rule:    RuleUndefinedComponent,
+				Fatal:   true,
+				Message: fmt.Sprintf("service uses extension %s, which isn't defined", name),
+				Help:    fmt.Sprintf("define %s under extensions or remove it from service.extensions", name),
+			})
+			continue
+		}
+		used["extensions"][name] = true
+	}
+	for _, kind := range []string{"receivers", "processors", "exporters", "connectors", "extensions"} {
+		for _, name := range sortedKeys(section(config, kind)) {
+			if used[kind][name] {
+				continue
+			}
+			findings = append(findings, Finding{
+				Rule:    RuleUnusedComponent,
+				Message: fmt.Sprintf("%s %s isn't used", strings.TrimSuffix(kind, "s"), name),
+				Help:    unusedHelp(kind, name),
+			})
+		}
+	}
+	return findings
+}
+
+func unusedHelp(kind string, name string) string {
+	if kind == "extensions" {
+		return fmt.Sprintf("add %s to service.extensions or remove it", name)
+	}
+	return fmt.Sprintf("add %s to a pipeline or remove it", name)
+}
+
+// lintEnv finds placeholders without a default whose environment variable the collector's spec doesn't have. They're
+// only warnings, the image or the operator may still set them.
+func lintEnv(config map[string]interface{}, env []string) []Finding {
+	set := map[string]bool{}
+	for _, name := range env {
+		set[name] = true
+	}
+	referenced := map[string]bool{}
+	collectPlaceholders(config, referenced)
+	var findings []Finding
+	for _, name := range sortedKeys(referenced) {
+		if set[name] {
+			continue
+		}
+		findings = append(findings, Finding{
+			Rule:    RuleUnsetEnv,
+			Message: fmt.Sprintf("${%s} is used but the collector has no %s env var", name, name),
+			Help:    fmt.Sprintf("set it with --collector-env or --collector-env-secret, or give it a default with ${env:%s:-default}", name),
+		})
+	}
+	return findings
+}
+
+func collectPlaceholders(value interface{}, names map[string]bool) {
+	switch v := value.(type) {
+	case string:
+		for _, m := range envPlaceholder.FindAllStringSubmatchIndex(v, -1) {
+			if escaped(v, m[0]) || m[4] >= 0 {
+				continue
+			}
+			names[v[m[2]:m[3]]] = true
+		}
+	case map[string]interface{}:
+		for _, item := range v {
+			collectPlaceholders(item, names)
+		}
+	case []interface{}:
+		for _, item := range v {
+			collectPlaceholders(item, names)
+		}
+	}
+}
+
+// escaped is whether the placeholder at i is written as $${...}, every $$ is a literal $ so it's escaped when an odd
+// number of $ precede it
+func escaped(s string, i int) bool {
+	n := 0
+	for i > 0 && s[i-1] == '$' {
+		n++
+		i--
+	}
+	return n%2 == 1
+}
+
+// listener is a port a component of the collector listens on
+type listener struct {
+	owner string
+	port  string
+}
+
+// lintPorts finds ports that more than one of the receivers, extensions and telemetry the collector starts listen on
+func lintPorts(config map[string]interface{}) []Finding {
+	var listeners []listener
+	receivers := section(config, "receivers")
+	started := map[string]bool{}
+	for _, p := range pipelines(config) {
+		for _, name := range names(p["receivers"]) {
+			started[name] = true
+		}
+	}
+	for _, name := range sortedKeys(receivers) {
+		if started[name] {
+			listeners = append(listeners, componentListeners("receiver "+name, name, receivers[name])...)
+		}
+	}
+	extensions := section(config, "extensions")
+	for _, name := range names(service(config)["extensions"]) {
+		if hasKey(extensions, name) {
+			listeners = append(listeners, componentListeners("extension "+name, name, extensions[name])...)
+		}
+	}
+	listeners = append(listeners, telemetryListeners(config)...)
+
+	var findings []Finding
+	owners := map[string]string{}
+	for _, l := range listeners {
+		owner, ok := owners[l.port]
+		if !ok {
+			owners[l.port] = l.owner
+			continue
+		}
+		findings = append(findings, Finding{
+			Rule:    RulePortCollision,
+			Fatal:   true,
+			Message: fmt.Sprintf("%s and %s both listen on port %s", owner, l.owner, l.port),
+			Help:    "give one of them another endpoint",
+		})
+	}
+	return findings
+}
+
+// componentListeners returns the ports of a receiver's or extension's endpoints, or its default ports
+func componentListeners(owner string, name string, config interface{}) []listener {
+	componentType, _, _ := strings.Cut(name, "/")
+	settings, _ := config.(map[string]interface{})
+	if componentType == "otlp" {
+		var listeners []listener
+		protocols, _ := settings["protocols"].(map[string]interface{})
+		for _, protocol := range sortedKeys(protocols) {
+			p := defaultProtocolPorts[protocol]
+			protocolSettings, _ := protocols[protocol].(map[string]interface{})
+			if endpoint, ok := protocolSettings["endpoint"].(string); ok {
+				p = port(endpoint)
+			}
+			if p != "" {
+				listeners = append(listeners, listener{owner: fmt.Sprintf("%s (%s)", owner, protocol), port: p})
+			}
+		}
+		return listeners
+	}
+	p := defaultPorts[componentType]
+	if endpoint, ok := settings["endpoint"].(string); ok {
+		p = port(endpoint)
+	}
+	if p == "" {
+		return nil
+	}
+	return []listener{{owner: owner, port: p}}
+}
+
+// telemetryListeners returns the ports the collector serves its own metrics on
+func telemetryListeners(config map[string]interface{}) []listener {
+	telemetry, _ := service(config)["telemetry"].(map[string]interface{})
+	metrics, _ := telemetry["metrics"].(map[string]interface{})
+	if metrics["level"] == "none" {
+		return nil
+	}
+	if readers, ok := metrics["readers"].([]interface{}); ok {
+		var listeners []listener
+		for _, r := range readers {
+			reader, _ := r.(map[string]interface{})
+			pull, _ := reader["pull"].(map[string]interface{})
+			exporter, _ := pull["exporter"].(map[string]interface{})
+			prometheus, ok := exporter["prometheus"].(map[string]interface{})
+			if ok && prometheus["port"] != nil {
+				listeners = append(listeners, listener{owner: "service telemetry", port: fmt.Sprint(prometheus["port"])})
+			}
+		}
+		return listeners
+	}
+	p := telemetryPort
+	if address, ok := metrics["address"].(string); ok {
+		p = port(address)
+	}
+	return []listener{{owner: "service telemetry", port: p}}
+}
+
+// lintProcessors finds pipelines that don't start with a memory_limiter or don't batch
+func lintProcessors(config map[string]interface{}) []Finding {
+	var findings []Finding
+	all := pipelines(config)
+	for _, id := range sortedKeys(all) {
+		processors := names(all[id]["processors"])
+		limiter := -1
+		batch := false
+		for i, name := range processors {
+			switch componentType, _, _ := strings.Cut(name, "/"); componentType {
+			case "memory_limiter":
+				if limiter < 0 {
+					limiter = i
+				}
+			case "batch":
+				batch = true
+			}
+		}
+		switch {
+		case limiter < 0:
+			findings = append(findings, Finding{
+				Rule:    RuleMemoryLimiter,
+				Message: fmt.Sprintf("pipeline %s has no memory_limiter processor", id),
+				Help:    "add a memory_limiter as the first processor so the collector refuses data instead of running out of memory",
+			})
+		case limiter > 0:
+			findings = append(findings, Finding{
+				Rule:    RuleMemoryLimiter,
+				Message: fmt.Sprintf("pipeline %s runs %s after %s", id, processors[limiter], processors[0]),
+				Help:    "make the memory_limiter the first processor so it refuses data before any other processor uses memory",
+			})
+		}
+		if !batch {
+			findings = append(findings, Finding{
+				Rule:    RuleBatch,
+				Message: fmt.Sprintf("pipeline %s has no batch processor", id),
+				Help:    "add a batch processor so the exporters send fewer, larger requests",
+			})
+		}
+	}
+	return findings
+}
+
+func section(config map[string]interface{}, name string) map[string]interface{} {
+	s, _ := config[name].(map[string]interface{})
+	return s
+}
+
+func service(config map[string]interface{}) map[string]interface{} {
+	return section(config, "service")
+}
+
+// pipelines returns the config's pipelines by ID
+func pipelines(config map[string]interface{}) map[string]map[string]interface{} {
+	found := map[string]map[string]interface{}{}
+	all, _ := service(config)["pipelines"].(map[string]interface{})
+	for id, p := range all {
+		if pipeline, ok := p.(map[string]interface{}); ok {
+			found[id] = pipeline
+		}
+	}
+	return found
+}
+
+// names returns the component names of a list in the config
+func names(value interface{}) []string {
+	list, _ := value.([]interface{})
+	found := make([]string, 0, len(list))
+	for _, item := range list {
+		if name, ok := item.(string); ok {
+			found = append(found, name)
+		}
+	}
+	return found
+}
+
+func hasKey[V any](m map[string]V, key string) bool {
+	_, ok := m[key]
+	return ok
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// port returns the port of an endpoint written as host:port, or an empty string for anything else, e.g. a URL
+func port(endpoint string) string {
+	if strings.Contains(endpoint, "://") {
+		return ""
+	}
+	_, p, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return ""
+	}
+	return p
+}
@@ -0,0 +1,164 @@
+package colconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		env    []string
+		want   []Finding
+	}{
+		{
+			name: "production ready",
+			config: `
+receivers:
+  otlp:
+    protocols:
+      grpc: {}
+      http: {}
+processors:
+  memory_limiter:
+    check_interval: 1s
+    limit_percentage: 80
+  batch: {}
+exporters:
+  otlp:
+    endpoint: ${DESTINATION}
+    headers:
+      authorization: ${env:TOKEN:-none}
+      x-literal: $${env:LITERAL} and $$$${LITERAL}
+connectors:
+  spanmetrics: {}
+extensions:
+  health_check: {}
+service:
+  extensions: [health_check]
+  pipelines:
+    traces:
+      receivers: [otlp]
+      processors: [memory_limiter, batch]
+      exporters: [otlp, spanmetrics]
+    metrics:
+      receivers: [otlp, spanmetrics]
+      processors: [memory_limiter, batch]
+      exporters: [otlp]
+`,
+			env: []string{"DESTINATION"},
+		},
+		{
+			name: "references and env",
+			config: `
+receivers:
+  otlp:
+    protocols:
+      grpc: {}
+  prometheus: {}
+exporters:
+  otlp:
+    endpoint: ${env:DESTINATION}
+extensions:
+  zpages: {}
+service:
+  extensions: [pprof]
+  pipelines:
+    traces:
+      receivers: [otlp]
+      processors: [memory_limiter, batch]
+      exporters: [otlp, debug]
+`,
+			want: []Finding{
+				{Rule: RuleUndefinedComponent, Fatal: true, Message: "pipeline traces uses processor memory_limiter, which isn't defined", Help: "define memory_limiter under processors or remove it from the pipeline"},
+				{Rule: RuleUndefinedComponent, Fatal: true, Message: "pipeline traces uses processor batch, which isn't defined", Help: "define batch under processors or remove it from the pipeline"},
+				{Rule: RuleUndefinedComponent, Fatal: true, Message: "pipeline traces uses exporter debug, which isn't defined", Help: "define debug under exporters or remove it from the pipeline"},
+				{Rule: RuleUndefinedComponent, Fatal: true, Message: "service uses extension pprof, which isn't defined", Help: "define pprof under extensions or remove it from service.extensions"},
+				{Rule: RuleUnusedComponent, Message: "receiver prometheus isn't used", Help: "add prometheus to a pipeline or remove it"},
+				{Rule: RuleUnusedComponent, Message: "extension zpages isn't used", Help: "add zpages to service.extensions or remove it"},
+				{Rule: RuleUnsetEnv, Message: "${DESTINATION} is used but the collector has no DESTINATION env var", Help: "set it with --collector-env or --collector-env-secret, or give it a default with ${env:DESTINATION:-default}"},
+			},
+		},
+		{
+			name: "ports and processors",
+			config: `
+receivers:
+  otlp:
+    protocols:
+      grpc: {}
+      http:
+        endpoint: 0.0.0.0:8888
+  jaeger:
+    protocols:
+      grpc: {}
+  otlp/unused:
+    protocols:
+      grpc: {}
+processors:
+  memory_limiter:
+    check_interval: 1s
+    limit_mib: 400
+  batch: {}
+  attributes: {}
+exporters:
+  debug: {}
+extensions:
+  health_check:
+    endpoint: 0.0.0.0:4317
+service:
+  extensions: [health_check]
+  pipelines:
+    traces:
+      receivers: [otlp, jaeger]
+      processors: [attributes, memory_limiter, batch]
+      exporters: [debug]
+    logs:
+      receivers: [otlp]
+      processors: [memory_limiter]
+      exporters: [debug]
+`,
+			want: []Finding{
+				{Rule: RuleUnusedComponent, Message: "receiver otlp/unused isn't used", Help: "add otlp/unused to a pipeline or remove it"},
+				{Rule: RulePortCollision, Fatal: true, Message: "receiver otlp (grpc) and extension health_check both listen on port 4317", Help: "give one of them another endpoint"},
+				{Rule: RulePortCollision, Fatal: true, Message: "receiver otlp (http) and service telemetry both listen on port 8888", Help: "give one of them another endpoint"},
+				{Rule: RuleBatch, Message: "pipeline logs has no batch processor", Help: "add a batch processor so the exporters send fewer, larger requests"},
+				{Rule: RuleMemoryLimiter, Message: "pipeline traces runs memory_limiter after attributes", Help: "make the memory_limiter the first processor so it refuses data before any other processor uses memory"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := map[string]interface{}{}
+			require.NoError(t, yaml.Unmarshal([]byte(tt.config), config))
+			assert.Equal(t, tt.want, Lint(config, tt.env))
+		})
+	}
+}
+
+func TestLint_Telemetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		telemetry string
+		wantPorts []string
+	}{
+		{name: "default", telemetry: "{}", wantPorts: []string{"8888"}},
+		{name: "turned off", telemetry: "{metrics: {level: none}}", wantPorts: nil},
+		{name: "address", telemetry: "{metrics: {address: \"0.0.0.0:9999\"}}", wantPorts: []string{"9999"}},
+		{name: "readers", telemetry: "{metrics: {readers: [{pull: {exporter: {prometheus: {host: 0.0.0.0, port: 9464}}}}]}}", wantPorts: []string{"9464"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := map[string]interface{}{}
+			require.NoError(t, yaml.Unmarshal([]byte("service:\n  telemetry: "+tt.telemetry+"\n"), config))
+			var ports []string
+			for _, l := range telemetryListeners(config) {
+				ports = append(ports, l.port)
+			}
+			assert.Equal(t, tt.wantPorts, ports)
+		})
+	}
+}
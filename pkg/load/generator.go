@@ -0,0 +1,261 @@
+// Package load generates telemetry at a steady rate and measures how well the exporters keep up with it.
+package load
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/metric"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	instrumentation = "collector-cluster-check/load"
+	// QueueSize is the size of the span and log record queues, a backlog this large means items are being dropped
+	QueueSize = 2048
+	// tick is how often the generator catches up with its rates
+	tick = 10 * time.Millisecond
+	// collectInterval is how often metrics are exported
+	collectInterval = time.Second
+)
+
+// Options describe the load to generate, a rate of zero turns a signal off
+type Options struct {
+	SpansPerSecond        int
+	MetricPointsPerSecond int
+	LogRecordsPerSecond   int
+	Duration              time.Duration
+	// Cardinality is how many distinct values the series attribute takes
+	Cardinality int
+	// PayloadSize is how many bytes every span and log record carries in an attribute
+	PayloadSize int
+}
+
+func (o Options) Validate() error {
+	if o.SpansPerSecond < 0 || o.MetricPointsPerSecond < 0 || o.LogRecordsPerSecond < 0 {
+		return fmt.Errorf("load rates can't be negative")
+	}
+	if o.SpansPerSecond+o.MetricPointsPerSecond+o.LogRecordsPerSecond == 0 {
+		return fmt.Errorf("at least one load rate must be set")
+	}
+	if o.Duration <= 0 {
+		return fmt.Errorf("load duration must be positive")
+	}
+	if o.Cardinality < 1 {
+		return fmt.Errorf("load cardinality must be at least 1")
+	}
+	if o.PayloadSize < 0 {
+		return fmt.Errorf("load payload size can't be negative")
+	}
+	return nil
+}
+
+// Signal is what the generator sends
+type Signal string
+
+const (
+	SignalSpans        Signal = "spans"
+	SignalMetricPoints Signal = "metric points"
+	SignalLogRecords   Signal = "log records"
+)
+
+// Exporters are what the generator sends through, a nil exporter turns its signal off
+type Exporters struct {
+	Spans   sdktrace.SpanExporter
+	Metrics sdkmetric.Exporter
+	Logs    sdklog.Exporter
+}
+
+// Generator sends telemetry through its own providers so that every export can be measured
+type Generator struct {
+	opts     Options
+	res      *resource.Resource
+	exp      Exporters
+	spans    *Recorder
+	metrics  *Recorder
+	logs     *Recorder
+	measured int
+	// ran is set once Run took over the exporters, the providers shut them down from then on
+	ran bool
+}
+
+func NewGenerator(opts Options, res *resource.Resource, exp Exporters) *Generator {
+	return &Generator{opts: opts, res: res, exp: exp, spans: &Recorder{}, metrics: &Recorder{}, logs: &Recorder{}}
+}
+
+// Result is the outcome of generating one signal
+type Result struct {
+	Signal Signal
+	// Rate is how many items per second were asked for
+	Rate int
+	// Achieved is how many items per second were generated
+	Achieved float64
+	Stats    Stats
+	// Saturation is the peak backlog relative to the queue size, signals without a queue leave it at zero
+	Saturation float64
+}
+
+// Run generates load for the configured duration then flushes every provider. The context bounds both, cancelling it
+// stops the load early.
+func (g *Generator) Run(ctx context.Context) ([]Result, error) {
+	if err := g.opts.Validate(); err != nil {
+		return nil, err
+	}
+	g.ran = true
+	var shutdowns []func(context.Context) error
+	var generators []func(int)
+	var signals []Signal
+	var rates []int
+	payload := strings.Repeat("x", g.opts.PayloadSize)
+
+	if g.opts.SpansPerSecond > 0 && g.exp.Spans != nil {
+		tp := sdktrace.NewTracerProvider(
+			sdktrace.WithResource(g.res),
+			sdktrace.WithBatcher(spanExporter{SpanExporter: g.exp.Spans, r: g.spans}, sdktrace.WithMaxQueueSize(QueueSize)),
+		)
+		tracer := tp.Tracer(instrumentation)
+		shutdowns = append(shutdowns, tp.Shutdown)
+		generators = append(generators, func(i int) {
+			_, span := tracer.Start(ctx, "load", g.spanAttributes(i, payload))
+			span.End()
+			g.spans.Generated(1)
+		})
+		signals, rates = append(signals, SignalSpans), append(rates, g.opts.SpansPerSecond)
+	}
+	if g.opts.MetricPointsPerSecond > 0 && g.exp.Metrics != nil {
+		mp := sdkmetric.NewMeterProvider(
+			sdkmetric.WithResource(g.res),
+			sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter{Exporter: g.exp.Metrics, r: g.metrics}, sdkmetric.WithInterval(collectInterval))),
+		)
+		counter, err := mp.Meter(instrumentation).Int64Counter("load")
+		if err != nil {
+			return nil, err
+		}
+		shutdowns = append(shutdowns, mp.Shutdown)
+		generators = append(generators, func(i int) {
+			counter.Add(ctx, 1, metric.WithAttributes(attribute.Int("series", i%g.opts.Cardinality)))
+			g.measured++
+		})
+		signals, rates = append(signals, SignalMetricPoints), append(rates, g.opts.MetricPointsPerSecond)
+	}
+	if g.opts.LogRecordsPerSecond > 0 && g.exp.Logs != nil {
+		lp := sdklog.NewLoggerProvider(
+			sdklog.WithResource(g.res),
+			sdklog.WithProcessor(sdklog.NewBatchProcessor(logExporter{Exporter: g.exp.Logs, r: g.logs}, sdklog.WithMaxQueueSize(QueueSize))),
+		)
+		logger := lp.Logger(instrumentation)
+		shutdowns = append(shutdowns, lp.Shutdown)
+		generators = append(generators, func(i int) {
+			var record otellog.Record
+			record.SetTimestamp(time.Now())
+			record.SetSeverity(otellog.SeverityInfo)
+			record.SetBody(otellog.StringValue(payload))
+			record.AddAttributes(otellog.Int("series", i%g.opts.Cardinality))
+			logger.Emit(ctx, record)
+			g.logs.Generated(1)
+		})
+		signals, rates = append(signals, SignalLogRecords), append(rates, g.opts.LogRecordsPerSecond)
+	}
+	if len(generators) == 0 {
+		return nil, fmt.Errorf("no exporter for the signals that have a rate")
+	}
+
+	elapsed := g.generate(ctx, generators, rates)
+
+	// shutting down flushes whatever is left in the queues. Its errors are left out since failed exports are already
+	// recorded by the exporters, and items that never made it to an exporter are counted as dropped.
+	for _, shutdown := range shutdowns {
+		_ = shutdown(context.WithoutCancel(ctx))
+	}
+
+	results := make([]Result, 0, len(signals))
+	for i, signal := range signals {
+		r := Result{Signal: signal, Rate: rates[i]}
+		switch signal {
+		case SignalSpans:
+			r.Stats = g.spans.Stats(elapsed)
+			r.Saturation = float64(r.Stats.PeakBacklog) / QueueSize
+		case SignalMetricPoints:
+			r.Stats = g.metrics.Stats(elapsed)
+			r.Stats.Generated = g.measured
+		case SignalLogRecords:
+			r.Stats = g.logs.Stats(elapsed)
+			r.Saturation = float64(r.Stats.PeakBacklog) / QueueSize
+		}
+		if elapsed > 0 {
+			r.Achieved = float64(r.Stats.Generated) / elapsed.Seconds()
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// Shutdown shuts the exporters down when Run never did
+func (g *Generator) Shutdown(ctx context.Context) error {
+	if g.ran {
+		return nil
+	}
+	g.ran = true
+	var errs []error
+	if g.exp.Spans != nil {
+		errs = append(errs, g.exp.Spans.Shutdown(ctx))
+	}
+	if g.exp.Metrics != nil {
+		errs = append(errs, g.exp.Metrics.Shutdown(ctx))
+	}
+	if g.exp.Logs != nil {
+		errs = append(errs, g.exp.Logs.Shutdown(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// generate calls every generator at its rate until the duration is up or the context is done, and returns how long
+// it generated for
+func (g *Generator) generate(ctx context.Context, generators []func(int), rates []int) time.Duration {
+	ctx, cancel := context.WithTimeout(ctx, g.opts.Duration)
+	defer cancel()
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i, generate := range generators {
+		wg.Add(1)
+		go func(generate func(int), rate int) {
+			defer wg.Done()
+			ticker := time.NewTicker(tick)
+			defer ticker.Stop()
+			sent := 0
+			total := int(g.opts.Duration.Seconds() * float64(rate))
+			for {
+				// catch up with the rate, so a slow tick doesn't lower it
+				due := min(int(time.Since(start).Seconds()*float64(rate)), total)
+				for ; sent < due; sent++ {
+					generate(sent)
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}(generate, rates[i])
+	}
+	wg.Wait()
+	return time.Since(start)
+}
+
+func (g *Generator) spanAttributes(i int, payload string) trace.SpanStartOption {
+	attrs := []attribute.KeyValue{attribute.Int("series", i%g.opts.Cardinality)}
+	if payload != "" {
+		attrs = append(attrs, attribute.String("payload", payload))
+	}
+	return trace.WithAttributes(attrs...)
+}
@@ -0,0 +1,129 @@
+package load
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type fakeMetricExporter struct {
+	mu     sync.Mutex
+	points int
+}
+
+func (e *fakeMetricExporter) Temporality(k sdkmetric.InstrumentKind) metricdata.Temporality {
+	return sdkmetric.DefaultTemporalitySelector(k)
+}
+
+func (e *fakeMetricExporter) Aggregation(k sdkmetric.InstrumentKind) sdkmetric.Aggregation {
+	return sdkmetric.DefaultAggregationSelector(k)
+}
+
+func (e *fakeMetricExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.points += dataPoints(rm)
+	return nil
+}
+
+func (e *fakeMetricExporter) ForceFlush(ctx context.Context) error { return nil }
+
+func (e *fakeMetricExporter) Shutdown(ctx context.Context) error { return nil }
+
+type fakeLogExporter struct {
+	err error
+}
+
+func (e fakeLogExporter) Export(ctx context.Context, records []sdklog.Record) error { return e.err }
+
+func (e fakeLogExporter) ForceFlush(ctx context.Context) error { return nil }
+
+func (e fakeLogExporter) Shutdown(ctx context.Context) error { return nil }
+
+func TestGenerator_Run(t *testing.T) {
+	spans := tracetest.NewInMemoryExporter()
+	metrics := &fakeMetricExporter{}
+	g := NewGenerator(Options{
+		SpansPerSecond:        200,
+		MetricPointsPerSecond: 100,
+		LogRecordsPerSecond:   50,
+		Duration:              500 * time.Millisecond,
+		Cardinality:           4,
+		PayloadSize:           16,
+	}, resource.Empty(), Exporters{Spans: spans, Metrics: metrics, Logs: fakeLogExporter{err: fmt.Errorf("rejected")}})
+
+	results, err := g.Run(context.Background())
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	assert.Equal(t, SignalSpans, results[0].Signal)
+	assert.Equal(t, 100, results[0].Stats.Generated)
+	assert.Equal(t, 100, results[0].Stats.Exported)
+	assert.Zero(t, results[0].Stats.Dropped)
+	assert.Positive(t, results[0].Stats.Exports)
+	assert.Positive(t, results[0].Saturation)
+
+	// metrics are aggregated, so at most one point per series is exported every collection
+	assert.Equal(t, SignalMetricPoints, results[1].Signal)
+	assert.Equal(t, 50, results[1].Stats.Generated)
+	assert.Equal(t, metrics.points, results[1].Stats.Exported)
+	assert.Equal(t, 4, results[1].Stats.Exported)
+
+	assert.Equal(t, SignalLogRecords, results[2].Signal)
+	assert.Equal(t, 25, results[2].Stats.Generated)
+	assert.Equal(t, 25, results[2].Stats.Failed)
+	assert.Zero(t, results[2].Stats.Exported)
+	assert.EqualError(t, results[2].Stats.LastErr, "rejected")
+}
+
+func TestOptions_Validate(t *testing.T) {
+	valid := Options{SpansPerSecond: 1, Duration: time.Second, Cardinality: 1}
+	assert.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name    string
+		modify  func(o *Options)
+		wantErr string
+	}{
+		{"no rate", func(o *Options) { o.SpansPerSecond = 0 }, "at least one load rate must be set"},
+		{"negative rate", func(o *Options) { o.LogRecordsPerSecond = -1 }, "load rates can't be negative"},
+		{"no duration", func(o *Options) { o.Duration = 0 }, "load duration must be positive"},
+		{"no cardinality", func(o *Options) { o.Cardinality = 0 }, "load cardinality must be at least 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := valid
+			tt.modify(&o)
+			assert.EqualError(t, o.Validate(), tt.wantErr)
+		})
+	}
+}
+
+func TestRecorder_Stats(t *testing.T) {
+	r := &Recorder{}
+	r.Generated(10)
+	for i := 1; i <= 4; i++ {
+		r.Export(2, time.Duration(i)*time.Millisecond, nil)
+	}
+	r.Export(1, 10*time.Millisecond, fmt.Errorf("unavailable"))
+
+	s := r.Stats(2 * time.Second)
+	assert.Equal(t, 10, s.Generated)
+	assert.Equal(t, 8, s.Exported)
+	assert.Equal(t, 1, s.Failed)
+	assert.Equal(t, 1, s.Dropped)
+	assert.Equal(t, 5, s.Exports)
+	assert.Equal(t, 10, s.PeakBacklog)
+	assert.Equal(t, 4.0, s.Throughput)
+	assert.Equal(t, Percentiles{P50: 3 * time.Millisecond, P95: 10 * time.Millisecond, P99: 10 * time.Millisecond, Max: 10 * time.Millisecond}, s.Latency)
+}
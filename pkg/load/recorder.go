@@ -0,0 +1,175 @@
+package load
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Recorder counts the items a signal generated and what its exporter did with them
+type Recorder struct {
+	mu        sync.Mutex
+	generated int
+	exported  int
+	failed    int
+	latencies []time.Duration
+	lastErr   error
+	// peakBacklog is the most items that were generated but not exported yet at any one time
+	peakBacklog int
+}
+
+// Generated counts items handed to the SDK
+func (r *Recorder) Generated(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.generated += n
+	if backlog := r.generated - r.exported - r.failed; backlog > r.peakBacklog {
+		r.peakBacklog = backlog
+	}
+}
+
+// Export records an export call of n items that took d
+func (r *Recorder) Export(n int, d time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.latencies = append(r.latencies, d)
+	if err != nil {
+		r.failed += n
+		r.lastErr = err
+		return
+	}
+	r.exported += n
+}
+
+// Stats summarizes what was recorded over the given duration
+func (r *Recorder) Stats(duration time.Duration) Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := Stats{
+		Generated:   r.generated,
+		Exported:    r.exported,
+		Failed:      r.failed,
+		Exports:     len(r.latencies),
+		PeakBacklog: r.peakBacklog,
+		LastErr:     r.lastErr,
+		Latency:     newPercentiles(r.latencies),
+	}
+	// items still unaccounted for once the SDK was shut down were dropped, e.g. because the queue was full
+	if dropped := r.generated - r.exported - r.failed; dropped > 0 {
+		s.Dropped = dropped
+	}
+	if duration > 0 {
+		s.Throughput = float64(r.exported) / duration.Seconds()
+	}
+	return s
+}
+
+// Stats is the outcome of generating load for a signal
+type Stats struct {
+	Generated int
+	Exported  int
+	Failed    int
+	Dropped   int
+	// Exports is how many export calls were made
+	Exports int
+	// Throughput is how many items were exported per second
+	Throughput  float64
+	PeakBacklog int
+	Latency     Percentiles
+	// LastErr is the error of the last failed export
+	LastErr error
+}
+
+// Percentiles of export latencies, using the nearest rank
+type Percentiles struct {
+	P50 time.Duration
+	P95 time.Duration
+	P99 time.Duration
+	Max time.Duration
+}
+
+func newPercentiles(latencies []time.Duration) Percentiles {
+	if len(latencies) == 0 {
+		return Percentiles{}
+	}
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := func(p int) time.Duration {
+		i := (p*len(sorted)+99)/100 - 1
+		return sorted[max(i, 0)]
+	}
+	return Percentiles{P50: rank(50), P95: rank(95), P99: rank(99), Max: sorted[len(sorted)-1]}
+}
+
+// spanExporter records every export of the exporter it wraps
+type spanExporter struct {
+	sdktrace.SpanExporter
+	r *Recorder
+}
+
+func (e spanExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	start := time.Now()
+	err := e.SpanExporter.ExportSpans(ctx, spans)
+	e.r.Export(len(spans), time.Since(start), err)
+	return err
+}
+
+// logExporter records every export of the exporter it wraps
+type logExporter struct {
+	sdklog.Exporter
+	r *Recorder
+}
+
+func (e logExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	start := time.Now()
+	err := e.Exporter.Export(ctx, records)
+	e.r.Export(len(records), time.Since(start), err)
+	return err
+}
+
+// metricExporter records every export of the exporter it wraps, metrics are aggregated so it counts data points
+// rather than the measurements that were generated
+type metricExporter struct {
+	sdkmetric.Exporter
+	r *Recorder
+}
+
+func (e metricExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	start := time.Now()
+	err := e.Exporter.Export(ctx, rm)
+	e.r.Export(dataPoints(rm), time.Since(start), err)
+	return err
+}
+
+func dataPoints(rm *metricdata.ResourceMetrics) int {
+	n := 0
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch d := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				n += len(d.DataPoints)
+			case metricdata.Sum[float64]:
+				n += len(d.DataPoints)
+			case metricdata.Gauge[int64]:
+				n += len(d.DataPoints)
+			case metricdata.Gauge[float64]:
+				n += len(d.DataPoints)
+			case metricdata.Histogram[int64]:
+				n += len(d.DataPoints)
+			case metricdata.Histogram[float64]:
+				n += len(d.DataPoints)
+			case metricdata.ExponentialHistogram[int64]:
+				n += len(d.DataPoints)
+			case metricdata.ExponentialHistogram[float64]:
+				n += len(d.DataPoints)
+			}
+		}
+	}
+	return n
+}
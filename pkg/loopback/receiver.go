@@ -0,0 +1,129 @@
+// Package loopback receives the telemetry that went through the test collector, so that what came out can be compared
+// with what went in.
+package loopback
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// RunIDAttribute is the resource attribute that tells a run's telemetry apart from every other run's
+	RunIDAttribute = "collector.check.run_id"
+
+	TracesPath  = "/v1/traces"
+	MetricsPath = "/v1/metrics"
+	LogsPath    = "/v1/logs"
+)
+
+// Receiver is an OTLP receiver for gRPC and HTTP/protobuf that keeps everything it received
+type Receiver struct {
+	coltracepb.UnimplementedTraceServiceServer
+
+	mu      sync.Mutex
+	spans   []*tracepb.ResourceSpans
+	metrics []*metricspb.ResourceMetrics
+	logs    []*logspb.ResourceLogs
+}
+
+func NewReceiver() *Receiver {
+	return &Receiver{}
+}
+
+func (r *Receiver) Export(ctx context.Context, req *coltracepb.ExportTraceServiceRequest) (*coltracepb.ExportTraceServiceResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, req.GetResourceSpans()...)
+	return &coltracepb.ExportTraceServiceResponse{}, nil
+}
+
+// metricsService and logsService adapt the receiver to the services whose Export method has another signature
+type metricsService struct {
+	colmetricspb.UnimplementedMetricsServiceServer
+	r *Receiver
+}
+
+func (s metricsService) Export(ctx context.Context, req *colmetricspb.ExportMetricsServiceRequest) (*colmetricspb.ExportMetricsServiceResponse, error) {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+	s.r.metrics = append(s.r.metrics, req.GetResourceMetrics()...)
+	return &colmetricspb.ExportMetricsServiceResponse{}, nil
+}
+
+type logsService struct {
+	collogspb.UnimplementedLogsServiceServer
+	r *Receiver
+}
+
+func (s logsService) Export(ctx context.Context, req *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error) {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+	s.r.logs = append(s.r.logs, req.GetResourceLogs()...)
+	return &collogspb.ExportLogsServiceResponse{}, nil
+}
+
+// GRPCServer returns a server with the OTLP gRPC services registered
+func (r *Receiver) GRPCServer() *grpc.Server {
+	s := grpc.NewServer()
+	coltracepb.RegisterTraceServiceServer(s, r)
+	colmetricspb.RegisterMetricsServiceServer(s, metricsService{r: r})
+	collogspb.RegisterLogsServiceServer(s, logsService{r: r})
+	return s
+}
+
+// Handler serves OTLP/HTTP requests encoded as protobuf
+func (r *Receiver) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+TracesPath, handle(func(ctx context.Context, req *coltracepb.ExportTraceServiceRequest) (proto.Message, error) {
+		return r.Export(ctx, req)
+	}))
+	mux.HandleFunc("POST "+MetricsPath, handle(func(ctx context.Context, req *colmetricspb.ExportMetricsServiceRequest) (proto.Message, error) {
+		return metricsService{r: r}.Export(ctx, req)
+	}))
+	mux.HandleFunc("POST "+LogsPath, handle(func(ctx context.Context, req *collogspb.ExportLogsServiceRequest) (proto.Message, error) {
+		return logsService{r: r}.Export(ctx, req)
+	}))
+	return mux
+}
+
+// handle decodes a protobuf request, exports it and encodes the response
+func handle[T any, PT interface {
+	*T
+	proto.Message
+}](export func(context.Context, PT) (proto.Message, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		msg := PT(new(T))
+		if err := proto.Unmarshal(body, msg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resp, err := export(req.Context(), msg)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		out, err := proto.Marshal(resp)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		_, _ = w.Write(out)
+	}
+}
@@ -0,0 +1,186 @@
+package loopback
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/metric"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// exporters send telemetry to the receiver at addr the same way the providers of a run do
+type exporters struct {
+	spans   sdktrace.SpanExporter
+	metrics sdkmetric.Exporter
+	logs    sdklog.Exporter
+}
+
+func grpcExporters(t *testing.T, addr string) exporters {
+	ctx := context.Background()
+	spans, err := otlptracegrpc.New(ctx, otlptracegrpc.WithEndpoint(addr), otlptracegrpc.WithInsecure())
+	require.NoError(t, err)
+	metrics, err := otlpmetricgrpc.New(ctx, otlpmetricgrpc.WithEndpoint(addr), otlpmetricgrpc.WithInsecure())
+	require.NoError(t, err)
+	logs, err := otlploggrpc.New(ctx, otlploggrpc.WithEndpoint(addr), otlploggrpc.WithInsecure())
+	require.NoError(t, err)
+	return exporters{spans: spans, metrics: metrics, logs: logs}
+}
+
+func httpExporters(t *testing.T, addr string) exporters {
+	ctx := context.Background()
+	spans, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpoint(addr), otlptracehttp.WithInsecure())
+	require.NoError(t, err)
+	metrics, err := otlpmetrichttp.New(ctx, otlpmetrichttp.WithEndpoint(addr), otlpmetrichttp.WithInsecure())
+	require.NoError(t, err)
+	logs, err := otlploghttp.New(ctx, otlploghttp.WithEndpoint(addr), otlploghttp.WithInsecure())
+	require.NoError(t, err)
+	return exporters{spans: spans, metrics: metrics, logs: logs}
+}
+
+// send emits a span, a counter and a log record, records them like the steps of a run do and flushes them
+func send(t *testing.T, exp exporters, res *resource.Resource, sent *Sent) {
+	ctx := context.Background()
+	step := attribute.String(StepAttribute, "test")
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp.spans), sdktrace.WithResource(res))
+	_, span := tp.Tracer("test").Start(ctx, "traceChecker.Run", trace.WithAttributes(step))
+	span.End()
+	traceID, spanID := span.SpanContext().TraceID(), span.SpanContext().SpanID()
+	sent.RecordSpan(Span{Name: "traceChecker.Run", TraceID: traceID[:], SpanID: spanID[:], Attributes: map[string]string{StepAttribute: "test"}})
+	require.NoError(t, tp.Shutdown(ctx))
+
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exp.metrics)), sdkmetric.WithResource(res))
+	counter, err := mp.Meter("test").Int64Counter("collector.check.alive")
+	require.NoError(t, err)
+	counter.Add(ctx, 1, metric.WithAttributes(step))
+	sent.RecordMetric(Metric{Name: "collector.check.alive", Value: 1, Attributes: map[string]string{StepAttribute: "test"}})
+	require.NoError(t, mp.Shutdown(ctx))
+
+	lp := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exp.logs)), sdklog.WithResource(res))
+	record := log.Record{}
+	record.SetBody(log.StringValue("logChecker.Run"))
+	record.AddAttributes(log.String(StepAttribute, "test"))
+	lp.Logger("test").Emit(ctx, record)
+	sent.RecordLogRecord(LogRecord{Body: "logChecker.Run", Attributes: map[string]string{StepAttribute: "test"}})
+	require.NoError(t, lp.Shutdown(ctx))
+}
+
+func TestReceiver_Verify(t *testing.T) {
+	resourceAttrs := map[string]string{"service.name": "collector-cluster-check"}
+	attrs := []attribute.KeyValue{attribute.String("service.name", "collector-cluster-check"), attribute.String(RunIDAttribute, "a1b2")}
+	tests := []struct {
+		name  string
+		http  bool
+		attrs []attribute.KeyValue
+		// change makes what was sent differ from what the receiver got, like a collector rewriting it would
+		change  func(e *Expect)
+		wantErr []string
+	}{
+		{
+			name:  "grpc",
+			attrs: attrs,
+		},
+		{
+			name:  "http",
+			http:  true,
+			attrs: attrs,
+		},
+		{
+			name:  "another run",
+			attrs: []attribute.KeyValue{attribute.String("service.name", "collector-cluster-check"), attribute.String(RunIDAttribute, "c3d4")},
+			wantErr: []string{
+				"no spans of run a1b2 were received",
+				"no metrics of run a1b2 were received",
+				"no log_records of run a1b2 were received",
+			},
+		},
+		{
+			name:  "resource attribute changed on the way",
+			attrs: []attribute.KeyValue{attribute.String("service.name", "renamed"), attribute.String(RunIDAttribute, "a1b2")},
+			wantErr: []string{
+				`resource attribute service.name is "renamed", expected "collector-cluster-check"`,
+				`resource attribute service.name is "renamed", expected "collector-cluster-check"`,
+				`resource attribute service.name is "renamed", expected "collector-cluster-check"`,
+			},
+		},
+		{
+			name:  "IDs, value and attributes changed on the way",
+			attrs: attrs,
+			change: func(e *Expect) {
+				e.Span.TraceID = []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+				e.Metric.Value = 2
+				e.LogRecord.Attributes = map[string]string{StepAttribute: "other"}
+			},
+			wantErr: []string{
+				"expected 000102030405060708090a0b0c0d0e0f",
+				"metric collector.check.alive has value 1, expected 2",
+				`log record attribute collector.check.step is "test", expected "other"`,
+			},
+		},
+		{
+			name:  "span ID and attributes changed on the way",
+			attrs: attrs,
+			change: func(e *Expect) {
+				e.Span.SpanID = []byte{0, 1, 2, 3, 4, 5, 6, 7}
+				e.Metric.Attributes = map[string]string{"k8s.pod.name": "pod"}
+			},
+			wantErr: []string{
+				"expected 0001020304050607",
+				"metric attribute k8s.pod.name is missing",
+				"",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewReceiver()
+			lis, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			var exp exporters
+			if tt.http {
+				srv := &http.Server{Handler: r.Handler()}
+				go srv.Serve(lis)
+				defer srv.Close()
+				exp = httpExporters(t, lis.Addr().String())
+			} else {
+				srv := r.GRPCServer()
+				go srv.Serve(lis)
+				defer srv.Stop()
+				exp = grpcExporters(t, lis.Addr().String())
+			}
+			sent := NewSent()
+			send(t, exp, resource.NewSchemaless(tt.attrs...), sent)
+			expect := sent.Expect("a1b2", resourceAttrs)
+			if tt.change != nil {
+				tt.change(&expect)
+			}
+
+			verifications := r.Verify(expect)
+			require.Len(t, verifications, 3)
+			for i, v := range verifications {
+				assert.Equal(t, []string{SignalSpans, SignalMetrics, SignalLogRecords}[i], v.Signal)
+				if tt.wantErr == nil || tt.wantErr[i] == "" {
+					assert.NoError(t, v.Err, v.Signal)
+					assert.Equal(t, 1, v.Matched, v.Signal)
+					continue
+				}
+				assert.ErrorContains(t, v.Err, tt.wantErr[i])
+			}
+		})
+	}
+}
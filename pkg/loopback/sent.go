@@ -0,0 +1,67 @@
+package loopback
+
+import "sync"
+
+// StepAttribute is the attribute of every span, metric point and log record a run sends, it's the step that sent it
+const StepAttribute = "collector.check.step"
+
+// Span is the span a run sent
+type Span struct {
+	Name            string
+	TraceID, SpanID []byte
+	Attributes      map[string]string
+}
+
+// Metric is the counter a run incremented, Value is the sum of every increment
+type Metric struct {
+	Name       string
+	Value      int64
+	Attributes map[string]string
+}
+
+// LogRecord is the log record a run emitted
+type LogRecord struct {
+	Body       string
+	Attributes map[string]string
+}
+
+// Sent records the telemetry the steps of a run emitted, so what came back can be compared with it
+type Sent struct {
+	mu        sync.Mutex
+	span      Span
+	metric    Metric
+	logRecord LogRecord
+}
+
+func NewSent() *Sent {
+	return &Sent{}
+}
+
+func (s *Sent) RecordSpan(span Span) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.span = span
+}
+
+// RecordMetric adds the value to the counter's when it's the same counter
+func (s *Sent) RecordMetric(m Metric) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.metric.Name == m.Name {
+		m.Value += s.metric.Value
+	}
+	s.metric = m
+}
+
+func (s *Sent) RecordLogRecord(l LogRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logRecord = l
+}
+
+// Expect is what the receiver must get of the run, the resource of every signal must have the resource attributes
+func (s *Sent) Expect(runID string, resource map[string]string) Expect {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return Expect{RunID: runID, Resource: resource, Span: s.span, Metric: s.metric, LogRecord: s.logRecord}
+}
@@ -0,0 +1,92 @@
+package loopback
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// Tunnel lets the cluster reach a receiver on this machine. A relay in the cluster accepts a connection on its tunnel
+// port, forwarded to Relay, then waits for a collector to connect and pipes the two together. The tunnel keeps a
+// connection to the relay open at all times and, once a collector sends data on it, pipes it to Target.
+type Tunnel struct {
+	// Relay is the local address the relay's tunnel port is forwarded to
+	Relay string
+	// Target is the address of the receiver
+	Target string
+	// RetryInterval is how long to wait after the relay couldn't be reached or closed a connection that had no data
+	RetryInterval time.Duration
+}
+
+// Run keeps connecting to the relay until the context is done, every piped connection is closed when it returns
+func (t Tunnel) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	var d net.Dialer
+	for {
+		conn, err := d.DialContext(ctx, "tcp", t.Relay)
+		if err == nil {
+			var first []byte
+			if first, err = waitForData(ctx, conn); err == nil {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					t.pipe(ctx, conn, first)
+				}()
+				continue
+			}
+			conn.Close()
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(t.RetryInterval):
+		}
+	}
+}
+
+// waitForData blocks until the relay sends the first bytes of a collector connection
+func waitForData(ctx context.Context, conn net.Conn) ([]byte, error) {
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	if n > 0 {
+		return buf[:n], nil
+	}
+	if err == nil {
+		err = io.ErrUnexpectedEOF
+	}
+	return nil, err
+}
+
+// pipe copies the collector connection to the receiver and back until either side closes it
+func (t Tunnel) pipe(ctx context.Context, relay net.Conn, first []byte) {
+	defer relay.Close()
+	var d net.Dialer
+	target, err := d.DialContext(ctx, "tcp", t.Target)
+	if err != nil {
+		return
+	}
+	defer target.Close()
+	stop := context.AfterFunc(ctx, func() {
+		relay.Close()
+		target.Close()
+	})
+	defer stop()
+	if _, err := target.Write(first); err != nil {
+		return
+	}
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(target, relay)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(relay, target)
+		done <- struct{}{}
+	}()
+	<-done
+}
@@ -0,0 +1,65 @@
+package loopback
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTunnel_Run(t *testing.T) {
+	// the target echoes everything back, like a receiver answering a request
+	target, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer target.Close()
+	go func() {
+		for {
+			conn, err := target.Accept()
+			if err != nil {
+				return
+			}
+			go io.Copy(conn, conn)
+		}
+	}()
+
+	// the relay closes the first connection without data, as it does when it isn't ready, then sends a request
+	relay, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer relay.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tunnel := Tunnel{Relay: relay.Addr().String(), Target: target.Addr().String(), RetryInterval: 10 * time.Millisecond}
+	done := make(chan error)
+	go func() { done <- tunnel.Run(ctx) }()
+
+	conn, err := relay.Accept()
+	require.NoError(t, err)
+	conn.Close()
+
+	conn, err = relay.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("export request"))
+	require.NoError(t, err)
+	got := make([]byte, len("export request"))
+	_, err = io.ReadFull(conn, got)
+	require.NoError(t, err)
+	assert.Equal(t, "export request", string(got))
+
+	// the tunnel has another connection waiting for the next collector connection
+	next, err := relay.Accept()
+	require.NoError(t, err)
+	defer next.Close()
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("tunnel didn't stop")
+	}
+}
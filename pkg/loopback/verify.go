@@ -0,0 +1,205 @@
+package loopback
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+const (
+	SignalSpans      = "spans"
+	SignalMetrics    = "metrics"
+	SignalLogRecords = "log_records"
+)
+
+// Expect is the telemetry a run sent, every signal is looked for in the resources tagged with the run ID
+type Expect struct {
+	RunID string
+	// Resource are the attributes every resource of the run must have with the same values
+	Resource map[string]string
+	// Span, Metric and LogRecord are the span, metric and log record that were sent, they must come back with the same
+	// IDs, value and attributes
+	Span      Span
+	Metric    Metric
+	LogRecord LogRecord
+}
+
+// Verification is whether one signal arrived as it was sent
+type Verification struct {
+	Signal string
+	// Received is how many items of the run arrived, Matched how many of them are the ones that were sent
+	Received int
+	Matched  int
+	Err      error
+}
+
+func (v Verification) Ok() bool {
+	return v.Err == nil
+}
+
+// Verify compares what the receiver got with what the run sent, other runs' telemetry is ignored
+func (r *Receiver) Verify(e Expect) []Verification {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	spans := Verification{Signal: SignalSpans}
+	var spanMismatch error
+	for _, rs := range r.spans {
+		if !hasRunID(rs.GetResource(), e.RunID) {
+			continue
+		}
+		spans.Err = firstErr(spans.Err, checkAttributes("resource", rs.GetResource().GetAttributes(), e.Resource))
+		for _, ss := range rs.GetScopeSpans() {
+			for _, s := range ss.GetSpans() {
+				spans.Received++
+				if s.GetName() != e.Span.Name {
+					continue
+				}
+				if err := checkSpan(s, e.Span); err != nil {
+					spanMismatch = firstErr(spanMismatch, err)
+					continue
+				}
+				spans.Matched++
+			}
+		}
+	}
+
+	metrics := Verification{Signal: SignalMetrics}
+	var metricMismatch error
+	for _, rm := range r.metrics {
+		if !hasRunID(rm.GetResource(), e.RunID) {
+			continue
+		}
+		metrics.Err = firstErr(metrics.Err, checkAttributes("resource", rm.GetResource().GetAttributes(), e.Resource))
+		for _, sm := range rm.GetScopeMetrics() {
+			for _, m := range sm.GetMetrics() {
+				metrics.Received++
+				if m.GetName() != e.Metric.Name {
+					continue
+				}
+				if err := checkMetric(m, e.Metric); err != nil {
+					metricMismatch = firstErr(metricMismatch, err)
+					continue
+				}
+				metrics.Matched++
+			}
+		}
+	}
+
+	logs := Verification{Signal: SignalLogRecords}
+	var logMismatch error
+	for _, rl := range r.logs {
+		if !hasRunID(rl.GetResource(), e.RunID) {
+			continue
+		}
+		logs.Err = firstErr(logs.Err, checkAttributes("resource", rl.GetResource().GetAttributes(), e.Resource))
+		for _, sl := range rl.GetScopeLogs() {
+			for _, l := range sl.GetLogRecords() {
+				logs.Received++
+				if l.GetBody().GetStringValue() != e.LogRecord.Body {
+					continue
+				}
+				if err := checkAttributes("log record", l.GetAttributes(), e.LogRecord.Attributes); err != nil {
+					logMismatch = firstErr(logMismatch, err)
+					continue
+				}
+				logs.Matched++
+			}
+		}
+	}
+
+	verifications := []Verification{spans, metrics, logs}
+	mismatches := []error{spanMismatch, metricMismatch, logMismatch}
+	for i, v := range verifications {
+		if v.Err == nil && v.Matched == 0 {
+			switch {
+			case v.Received == 0:
+				verifications[i].Err = fmt.Errorf("no %s of run %s were received", v.Signal, e.RunID)
+			case mismatches[i] != nil:
+				verifications[i].Err = mismatches[i]
+			default:
+				verifications[i].Err = fmt.Errorf("%d %s of run %s were received, none of them is the one that was sent", v.Received, v.Signal, e.RunID)
+			}
+		}
+	}
+	return verifications
+}
+
+// checkSpan returns an error when the span's IDs or attributes aren't the ones that were sent
+func checkSpan(s *tracepb.Span, sent Span) error {
+	if !bytes.Equal(s.GetTraceId(), sent.TraceID) {
+		return fmt.Errorf("span %s has trace ID %x, expected %x", s.GetName(), s.GetTraceId(), sent.TraceID)
+	}
+	if !bytes.Equal(s.GetSpanId(), sent.SpanID) {
+		return fmt.Errorf("span %s has span ID %x, expected %x", s.GetName(), s.GetSpanId(), sent.SpanID)
+	}
+	return checkAttributes("span", s.GetAttributes(), sent.Attributes)
+}
+
+// checkMetric returns an error unless one of the counter's data points has the value and attributes that were sent
+func checkMetric(m *metricspb.Metric, sent Metric) error {
+	points := m.GetSum().GetDataPoints()
+	if len(points) == 0 {
+		return fmt.Errorf("metric %s has no sum data points", m.GetName())
+	}
+	var err error
+	for _, p := range points {
+		if p.GetAsInt() != sent.Value {
+			err = firstErr(err, fmt.Errorf("metric %s has value %d, expected %d", m.GetName(), p.GetAsInt(), sent.Value))
+			continue
+		}
+		if aErr := checkAttributes("metric", p.GetAttributes(), sent.Attributes); aErr != nil {
+			err = firstErr(err, aErr)
+			continue
+		}
+		return nil
+	}
+	return err
+}
+
+func hasRunID(res *resourcepb.Resource, runID string) bool {
+	v, ok := stringAttribute(res.GetAttributes(), RunIDAttribute)
+	return ok && v == runID
+}
+
+// checkAttributes returns an error for the first expected attribute, in key order, that's missing or has another value
+func checkAttributes(what string, attrs []*commonpb.KeyValue, expected map[string]string) error {
+	keys := make([]string, 0, len(expected))
+	for k := range expected {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v, ok := stringAttribute(attrs, k)
+		if !ok {
+			return fmt.Errorf("%s attribute %s is missing", what, k)
+		}
+		if v != expected[k] {
+			return fmt.Errorf("%s attribute %s is %q, expected %q", what, k, v, expected[k])
+		}
+	}
+	return nil
+}
+
+func stringAttribute(attrs []*commonpb.KeyValue, key string) (string, bool) {
+	for _, kv := range attrs {
+		if kv.GetKey() == key {
+			return kv.GetValue().GetStringValue(), true
+		}
+	}
+	return "", false
+}
+
+func firstErr(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+// Package otlp sends minimal OTLP export requests and explains why an endpoint rejected them.
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// TracesPath, MetricsPath and LogsPath are where OTLP/HTTP endpoints receive every signal
+const (
+	TracesPath  = "/v1/traces"
+	MetricsPath = "/v1/metrics"
+	LogsPath    = "/v1/logs"
+)
+
+// maxErrorBody bounds how much of an error response is kept, some proxies answer with whole HTML pages
+const maxErrorBody = 512
+
+// Transport is how an export request is sent
+type Transport string
+
+const (
+	TransportGRPC         Transport = "grpc"
+	TransportHTTPProtobuf Transport = "http/protobuf"
+	TransportHTTPJSON     Transport = "http/json"
+)
+
+// Transports are every supported transport, in the order they are documented
+var Transports = []Transport{TransportGRPC, TransportHTTPProtobuf, TransportHTTPJSON}
+
+// ParseTransport validates a transport given on the command line
+func ParseTransport(s string) (Transport, error) {
+	for _, t := range Transports {
+		if string(t) == s {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("unknown transport %q, must be one of grpc, http/protobuf, http/json", s)
+}
+
+// Client sends empty export requests, which exercise routing, TLS and authentication without sending telemetry
+type Client struct {
+	endpoint string
+	insecure bool
+	headers  map[string]string
+	proxy    func(*http.Request) (*url.URL, error)
+	dialer   func(context.Context, string) (net.Conn, error)
+	tls      *tls.Config
+	// resource describes the sender of the telemetry that Send exports
+	resource map[string]string
+}
+
+type Option func(c *Client)
+
+// WithProxy sets the proxy of HTTP requests
+func WithProxy(proxy func(*http.Request) (*url.URL, error)) Option {
+	return func(c *Client) {
+		c.proxy = proxy
+	}
+}
+
+// WithDialer sets how gRPC connections are dialed, e.g. through a proxy
+func WithDialer(dialer func(context.Context, string) (net.Conn, error)) Option {
+	return func(c *Client) {
+		c.dialer = dialer
+	}
+}
+
+// WithTLSConfig sets how secure connections are verified and authenticated, e.g. with a private CA
+func WithTLSConfig(conf *tls.Config) Option {
+	return func(c *Client) {
+		c.tls = conf
+	}
+}
+
+// WithResource sets the resource attributes of the telemetry that Send exports
+func WithResource(attrs map[string]string) Option {
+	return func(c *Client) {
+		c.resource = attrs
+	}
+}
+
+// NewClient creates a client for an endpoint given as host:port
+func NewClient(endpoint string, insecure bool, headers map[string]string, opts ...Option) *Client {
+	c := &Client{endpoint: endpoint, insecure: insecure, headers: headers, proxy: http.ProxyFromEnvironment, tls: &tls.Config{}}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *Client) Endpoint() string {
+	return c.endpoint
+}
+
+func (c *Client) Insecure() bool {
+	return c.insecure
+}
+
+// WithSecurity returns a copy of the client that connects with TLS or in plaintext. Headers are dropped when a secure
+// client is made insecure, so that credentials are only sent in the clear when the client was created to do so.
+func (c *Client) WithSecurity(insecure bool) *Client {
+	copied := *c
+	if insecure && !c.insecure {
+		copied.headers = nil
+	}
+	copied.insecure = insecure
+	return &copied
+}
+
+// Export sends an empty trace export request over the given transport
+func (c *Client) Export(ctx context.Context, transport Transport) error {
+	return c.export(ctx, transport, SignalTraces, &coltracepb.ExportTraceServiceRequest{})
+}
+
+// Send exports a single span, metric point or log record over the given transport
+func (c *Client) Send(ctx context.Context, transport Transport, signal Signal) error {
+	req, err := newRequest(signal, c.resource)
+	if err != nil {
+		return err
+	}
+	return c.export(ctx, transport, signal, req)
+}
+
+func (c *Client) export(ctx context.Context, transport Transport, signal Signal, req proto.Message) error {
+	switch transport {
+	case TransportGRPC:
+		return c.exportGRPC(ctx, signal, req)
+	case TransportHTTPProtobuf, TransportHTTPJSON:
+		return c.exportHTTP(ctx, transport, signal, req)
+	}
+	return fmt.Errorf("unknown transport %q", transport)
+}
+
+func (c *Client) exportGRPC(ctx context.Context, signal Signal, req proto.Message) error {
+	creds := credentials.NewTLS(c.tls)
+	if c.insecure {
+		creds = insecure.NewCredentials()
+	}
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if c.dialer != nil {
+		opts = append(opts, grpc.WithContextDialer(c.dialer))
+	}
+	conn, err := grpc.NewClient(c.endpoint, opts...)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	for k, v := range c.headers {
+		ctx = metadata.AppendToOutgoingContext(ctx, k, v)
+	}
+	return exportGRPC(ctx, conn, signal, req)
+}
+
+func (c *Client) exportHTTP(ctx context.Context, transport Transport, signal Signal, req proto.Message) error {
+	contentType := "application/x-protobuf"
+	marshal := proto.Marshal
+	if transport == TransportHTTPJSON {
+		contentType = "application/json"
+		marshal = marshalJSON
+	}
+	body, err := marshal(req)
+	if err != nil {
+		return err
+	}
+	u := url.URL{Scheme: "https", Host: c.endpoint, Path: signal.Path()}
+	if c.insecure {
+		u.Scheme = "http"
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", contentType)
+	for k, v := range c.headers {
+		httpReq.Header.Set(k, v)
+	}
+	client := &http.Client{Transport: &http.Transport{Proxy: c.proxy, TLSClientConfig: c.tls}}
+	defer client.CloseIdleConnections()
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	return &HTTPError{StatusCode: resp.StatusCode, Status: resp.Status, Body: strings.TrimSpace(string(respBody))}
+}
+
+// HTTPError is an export request that was answered with a status other than 2xx
+type HTTPError struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	if e.Body == "" {
+		return e.Status
+	}
+	return fmt.Sprintf("%s: %s", e.Status, e.Body)
+}
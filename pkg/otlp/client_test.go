@@ -0,0 +1,198 @@
+package otlp
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const token = "lightstep-access-token"
+
+// fakeTraceService answers every export with the configured status, unless the token header is missing
+type fakeTraceService struct {
+	coltracepb.UnimplementedTraceServiceServer
+	code codes.Code
+	msg  string
+}
+
+func (f *fakeTraceService) Export(ctx context.Context, req *coltracepb.ExportTraceServiceRequest) (*coltracepb.ExportTraceServiceResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if len(md.Get(token)) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "missing token")
+	}
+	if f.code != codes.OK {
+		return nil, status.Error(f.code, f.msg)
+	}
+	return &coltracepb.ExportTraceServiceResponse{}, nil
+}
+
+func newFakeGRPCServer(t *testing.T, service coltracepb.TraceServiceServer) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	if service != nil {
+		coltracepb.RegisterTraceServiceServer(server, service)
+	}
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+	return listener.Addr().String()
+}
+
+// newFakeHTTPServer answers exports to the traces path with the given status, unless the token header is missing
+func newFakeHTTPServer(t *testing.T, code int) string {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path != TracesPath:
+			http.NotFound(w, r)
+		case r.Header.Get("Content-Type") != "application/x-protobuf":
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+		case r.Header.Get(token) == "":
+			http.Error(w, "missing token", http.StatusUnauthorized)
+		default:
+			w.WriteHeader(code)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+func TestClient_ExportGRPC(t *testing.T) {
+	tests := []struct {
+		name        string
+		service     coltracepb.TraceServiceServer
+		headers     map[string]string
+		wantProblem Problem
+	}{
+		{
+			name:        "accepted",
+			service:     &fakeTraceService{},
+			headers:     map[string]string{token: "secret"},
+			wantProblem: ProblemNone,
+		},
+		{
+			name:        "missing token",
+			service:     &fakeTraceService{},
+			wantProblem: ProblemUnauthenticated,
+		},
+		{
+			name:        "permission denied",
+			service:     &fakeTraceService{code: codes.PermissionDenied},
+			headers:     map[string]string{token: "secret"},
+			wantProblem: ProblemPermissionDenied,
+		},
+		{
+			name:        "rate limited",
+			service:     &fakeTraceService{code: codes.ResourceExhausted, msg: "slow down"},
+			headers:     map[string]string{token: "secret"},
+			wantProblem: ProblemRateLimited,
+		},
+		{
+			name:        "too large",
+			service:     &fakeTraceService{code: codes.ResourceExhausted, msg: "grpc: received message larger than max (5 vs. 4)"},
+			headers:     map[string]string{token: "secret"},
+			wantProblem: ProblemTooLarge,
+		},
+		{
+			name:        "unavailable",
+			service:     &fakeTraceService{code: codes.Unavailable},
+			headers:     map[string]string{token: "secret"},
+			wantProblem: ProblemUnavailable,
+		},
+		{
+			name:        "not an OTLP server",
+			headers:     map[string]string{token: "secret"},
+			wantProblem: ProblemWrongPath,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := newFakeGRPCServer(t, tt.service)
+			err := NewClient(endpoint, true, tt.headers).Export(context.Background(), TransportGRPC)
+			assert.Equal(t, tt.wantProblem, Diagnose(err).Problem)
+		})
+	}
+}
+
+func TestClient_ExportHTTP(t *testing.T) {
+	tests := []struct {
+		name        string
+		code        int
+		headers     map[string]string
+		wantProblem Problem
+		wantErr     string
+	}{
+		{
+			name:        "accepted",
+			code:        http.StatusOK,
+			headers:     map[string]string{token: "secret"},
+			wantProblem: ProblemNone,
+		},
+		{
+			name:        "missing token",
+			code:        http.StatusOK,
+			wantProblem: ProblemUnauthenticated,
+			wantErr:     "401 Unauthorized: missing token",
+		},
+		{
+			name:        "forbidden",
+			code:        http.StatusForbidden,
+			headers:     map[string]string{token: "secret"},
+			wantProblem: ProblemPermissionDenied,
+			wantErr:     "403 Forbidden",
+		},
+		{
+			name:        "too large",
+			code:        http.StatusRequestEntityTooLarge,
+			headers:     map[string]string{token: "secret"},
+			wantProblem: ProblemTooLarge,
+			wantErr:     "413 Request Entity Too Large",
+		},
+		{
+			name:        "rate limited",
+			code:        http.StatusTooManyRequests,
+			headers:     map[string]string{token: "secret"},
+			wantProblem: ProblemRateLimited,
+			wantErr:     "429 Too Many Requests",
+		},
+		{
+			name:        "unavailable",
+			code:        http.StatusServiceUnavailable,
+			headers:     map[string]string{token: "secret"},
+			wantProblem: ProblemUnavailable,
+			wantErr:     "503 Service Unavailable",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := newFakeHTTPServer(t, tt.code)
+			err := NewClient(endpoint, true, tt.headers).Export(context.Background(), TransportHTTPProtobuf)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+			assert.Equal(t, tt.wantProblem, Diagnose(err).Problem)
+		})
+	}
+}
+
+func TestClient_ExportHTTPWrongPath(t *testing.T) {
+	// a gRPC only endpoint, or anything that isn't an OTLP/HTTP endpoint, doesn't serve the traces path
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	err := NewClient(strings.TrimPrefix(server.URL, "http://"), true, nil).Export(context.Background(), TransportHTTPProtobuf)
+	assert.Equal(t, ProblemWrongPath, Diagnose(err).Problem)
+}
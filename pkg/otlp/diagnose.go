@@ -0,0 +1,136 @@
+package otlp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Problem is what went wrong with an export request
+type Problem string
+
+const (
+	ProblemNone             Problem = "none"
+	ProblemUnauthenticated  Problem = "unauthenticated"
+	ProblemPermissionDenied Problem = "permission denied"
+	ProblemWrongPath        Problem = "wrong path"
+	ProblemTooLarge         Problem = "too large"
+	ProblemRateLimited      Problem = "rate limited"
+	ProblemUnavailable      Problem = "unavailable"
+	ProblemTimeout          Problem = "timeout"
+	ProblemUnsupported      Problem = "unsupported"
+	ProblemUnknown          Problem = "unknown"
+)
+
+// Diagnosis explains why an export request failed and what to do about it
+type Diagnosis struct {
+	Problem Problem
+	Help    string
+}
+
+// Transient is true for problems that go away by themselves, the endpoint is otherwise reachable and authenticated
+func (d Diagnosis) Transient() bool {
+	return d.Problem == ProblemRateLimited
+}
+
+var (
+	diagnoses = map[Problem]string{
+		ProblemNone:             "the endpoint accepted the export request",
+		ProblemUnauthenticated:  "the access token is missing or invalid, check --accessToken",
+		ProblemPermissionDenied: "the access token isn't allowed to send telemetry, check that it belongs to the right project and can ingest data",
+		ProblemWrongPath:        "the endpoint doesn't serve OTLP there, check the host and port for the transport, OTLP/HTTP is served at " + TracesPath,
+		ProblemTooLarge:         "the request was too large, lower the batch size or enable compression",
+		ProblemRateLimited:      "the endpoint is rate limiting requests, telemetry will be retried but may be dropped",
+		ProblemUnavailable:      "the endpoint is unreachable or unavailable, check DNS, firewall rules and proxies",
+		ProblemTimeout:          "the request timed out, check firewall rules and proxies",
+		ProblemUnsupported:      "the endpoint doesn't accept this transport or encoding, try the other transport",
+		ProblemUnknown:          "the endpoint rejected the export request",
+	}
+	// exporterStatus matches the HTTP status of an error returned by the OTLP/HTTP exporters
+	exporterStatus = regexp.MustCompile(`failed to send to \S+: (\d{3})`)
+)
+
+// Diagnose classifies an export error from the probe client, or from the OTLP exporters
+func Diagnose(err error) Diagnosis {
+	problem := ProblemUnknown
+	var httpErr *HTTPError
+	switch {
+	case err == nil:
+		problem = ProblemNone
+	case errors.As(err, &httpErr):
+		problem = httpProblem(httpErr.StatusCode)
+	case errors.Is(err, context.DeadlineExceeded):
+		problem = ProblemTimeout
+	default:
+		var netErr net.Error
+		var opErr *net.OpError
+		var dnsErr *net.DNSError
+		if s, ok := status.FromError(err); ok {
+			problem = grpcProblem(s)
+		} else if errors.As(err, &netErr) && netErr.Timeout() {
+			problem = ProblemTimeout
+		} else if errors.As(err, &opErr) || errors.As(err, &dnsErr) {
+			problem = ProblemUnavailable
+		} else if m := exporterStatus.FindStringSubmatch(err.Error()); m != nil {
+			code, _ := strconv.Atoi(m[1])
+			problem = httpProblem(code)
+		}
+	}
+	return Diagnosis{Problem: problem, Help: diagnoses[problem]}
+}
+
+func grpcProblem(s *status.Status) Problem {
+	switch s.Code() {
+	case codes.OK:
+		return ProblemNone
+	case codes.Unauthenticated:
+		return ProblemUnauthenticated
+	case codes.PermissionDenied:
+		return ProblemPermissionDenied
+	case codes.Unimplemented, codes.NotFound:
+		return ProblemWrongPath
+	case codes.ResourceExhausted:
+		// gRPC uses the same code for messages over the size limit
+		if strings.Contains(s.Message(), "larger than max") {
+			return ProblemTooLarge
+		}
+		return ProblemRateLimited
+	case codes.Unavailable:
+		return ProblemUnavailable
+	case codes.DeadlineExceeded:
+		return ProblemTimeout
+	}
+	return ProblemUnknown
+}
+
+func httpProblem(code int) Problem {
+	switch code {
+	case http.StatusUnauthorized:
+		return ProblemUnauthenticated
+	case http.StatusForbidden:
+		return ProblemPermissionDenied
+	case http.StatusNotFound, http.StatusMethodNotAllowed:
+		return ProblemWrongPath
+	case http.StatusRequestEntityTooLarge:
+		return ProblemTooLarge
+	case http.StatusTooManyRequests:
+		return ProblemRateLimited
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return ProblemUnavailable
+	case http.StatusGatewayTimeout, http.StatusRequestTimeout:
+		return ProblemTimeout
+	case http.StatusUnsupportedMediaType:
+		return ProblemUnsupported
+	}
+	if code >= 200 && code < 300 {
+		return ProblemNone
+	}
+	return ProblemUnknown
+}
@@ -0,0 +1,74 @@
+package otlp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDiagnose(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Problem
+	}{
+		{
+			name: "no error",
+			want: ProblemNone,
+		},
+		{
+			name: "wrapped grpc status from the exporters",
+			err:  fmt.Errorf("traces export: %w", status.Error(codes.Unauthenticated, "invalid token")),
+			want: ProblemUnauthenticated,
+		},
+		{
+			name: "http status from the exporters",
+			err:  fmt.Errorf("failed to send to https://ingest.lightstep.com:443/v1/metrics: 404 Not Found"),
+			want: ProblemWrongPath,
+		},
+		{
+			name: "unsupported media type",
+			err:  &HTTPError{StatusCode: 415, Status: "415 Unsupported Media Type"},
+			want: ProblemUnsupported,
+		},
+		{
+			name: "deadline",
+			err:  fmt.Errorf("traces export: %w", context.DeadlineExceeded),
+			want: ProblemTimeout,
+		},
+		{
+			name: "grpc deadline",
+			err:  status.Error(codes.DeadlineExceeded, "context deadline exceeded"),
+			want: ProblemTimeout,
+		},
+		{
+			name: "connection refused",
+			err:  &url.Error{Op: "Post", URL: "https://127.0.0.1:1/v1/traces", Err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}},
+			want: ProblemUnavailable,
+		},
+		{
+			name: "unknown host",
+			err:  &url.Error{Op: "Post", URL: "https://nope.invalid/v1/traces", Err: &net.DNSError{Err: "no such host", Name: "nope.invalid", IsNotFound: true}},
+			want: ProblemUnavailable,
+		},
+		{
+			name: "anything else",
+			err:  fmt.Errorf("boom"),
+			want: ProblemUnknown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Diagnose(tt.err)
+			assert.Equal(t, tt.want, got.Problem)
+			assert.NotEmpty(t, got.Help)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package otlp
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrorCollector keeps the errors of a provider's exports, including the ones from background exports that are only
+// reported to the OTel error handler and never returned to ForceFlush
+type ErrorCollector struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func NewErrorCollector() *ErrorCollector {
+	return &ErrorCollector{}
+}
+
+// Record keeps a failed export's error, nil errors are ignored
+func (c *ErrorCollector) Record(err error) {
+	if err == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.errs = append(c.errs, err)
+}
+
+// Owns is whether the error, or one it wraps, was recorded by this collector
+func (c *ErrorCollector) Owns(err error) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, recorded := range c.errs {
+		if errors.Is(err, recorded) {
+			return true
+		}
+	}
+	return false
+}
+
+// CollectedProblem is every failed export with the same problem
+type CollectedProblem struct {
+	Diagnosis
+	// Count is how many exports failed with the problem
+	Count int
+	// Err is the first error with the problem
+	Err error
+}
+
+// Problems groups the recorded errors by problem, in the order each problem first happened
+func (c *ErrorCollector) Problems() []CollectedProblem {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var problems []CollectedProblem
+	index := map[Problem]int{}
+	for _, err := range c.errs {
+		d := Diagnose(err)
+		if i, ok := index[d.Problem]; ok {
+			problems[i].Count++
+			continue
+		}
+		index[d.Problem] = len(problems)
+		problems = append(problems, CollectedProblem{Diagnosis: d, Count: 1, Err: err})
+	}
+	return problems
+}
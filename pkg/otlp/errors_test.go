@@ -0,0 +1,34 @@
+package otlp
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestErrorCollector(t *testing.T) {
+	unauthenticated := status.Error(codes.Unauthenticated, "invalid token")
+	limited := fmt.Errorf("failed to send to https://ingest.lightstep.com:443/v1/traces: 429 Too Many Requests")
+	c := NewErrorCollector()
+	c.Record(nil)
+	c.Record(limited)
+	c.Record(unauthenticated)
+	c.Record(status.Error(codes.Unauthenticated, "invalid token"))
+
+	assert.True(t, c.Owns(limited))
+	assert.True(t, c.Owns(fmt.Errorf("exporting: %w", unauthenticated)), "wrapped errors belong to the collector")
+	assert.False(t, c.Owns(fmt.Errorf("failed to send to https://ingest.lightstep.com:443/v1/traces: 429 Too Many Requests")))
+
+	problems := c.Problems()
+	require.Len(t, problems, 2)
+	assert.Equal(t, ProblemRateLimited, problems[0].Problem)
+	assert.Equal(t, 1, problems[0].Count)
+	assert.Equal(t, limited, problems[0].Err)
+	assert.Equal(t, ProblemUnauthenticated, problems[1].Problem)
+	assert.Equal(t, 2, problems[1].Count)
+	assert.Equal(t, unauthenticated, problems[1].Err)
+}
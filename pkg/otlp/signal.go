@@ -0,0 +1,166 @@
+package otlp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Signal is a kind of telemetry
+type Signal string
+
+const (
+	SignalTraces  Signal = "traces"
+	SignalMetrics Signal = "metrics"
+	SignalLogs    Signal = "logs"
+)
+
+// Signals are every signal, in the order they are reported
+var Signals = []Signal{SignalTraces, SignalMetrics, SignalLogs}
+
+// Path is where OTLP/HTTP endpoints receive the signal
+func (s Signal) Path() string {
+	switch s {
+	case SignalMetrics:
+		return MetricsPath
+	case SignalLogs:
+		return LogsPath
+	}
+	return TracesPath
+}
+
+const (
+	// SentName is the name of the span and the metric that Send exports
+	SentName = "collector.check.sent"
+	// SentBody is the body of the log record that Send exports
+	SentBody = "collector.check.sent"
+)
+
+// newRequest creates an export request with a single span, metric point or log record
+func newRequest(signal Signal, attrs map[string]string) (proto.Message, error) {
+	res := &resourcepb.Resource{}
+	for k, v := range attrs {
+		res.Attributes = append(res.Attributes, &commonpb.KeyValue{Key: k, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}}})
+	}
+	now := uint64(time.Now().UnixNano())
+	switch signal {
+	case SignalTraces:
+		traceID, spanID := make([]byte, 16), make([]byte, 8)
+		if _, err := rand.Read(traceID); err != nil {
+			return nil, err
+		}
+		if _, err := rand.Read(spanID); err != nil {
+			return nil, err
+		}
+		return &coltracepb.ExportTraceServiceRequest{ResourceSpans: []*tracepb.ResourceSpans{{
+			Resource: res,
+			ScopeSpans: []*tracepb.ScopeSpans{{Spans: []*tracepb.Span{{
+				TraceId:           traceID,
+				SpanId:            spanID,
+				Name:              SentName,
+				Kind:              tracepb.Span_SPAN_KIND_CLIENT,
+				StartTimeUnixNano: now,
+				EndTimeUnixNano:   now,
+			}}}},
+		}}}, nil
+	case SignalMetrics:
+		return &colmetricspb.ExportMetricsServiceRequest{ResourceMetrics: []*metricspb.ResourceMetrics{{
+			Resource: res,
+			ScopeMetrics: []*metricspb.ScopeMetrics{{Metrics: []*metricspb.Metric{{
+				Name: SentName,
+				Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: []*metricspb.NumberDataPoint{{
+					TimeUnixNano: now,
+					Value:        &metricspb.NumberDataPoint_AsInt{AsInt: 1},
+				}}}},
+			}}}},
+		}}}, nil
+	case SignalLogs:
+		return &collogspb.ExportLogsServiceRequest{ResourceLogs: []*logspb.ResourceLogs{{
+			Resource: res,
+			ScopeLogs: []*logspb.ScopeLogs{{LogRecords: []*logspb.LogRecord{{
+				TimeUnixNano:   now,
+				SeverityNumber: logspb.SeverityNumber_SEVERITY_NUMBER_INFO,
+				Body:           &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: SentBody}},
+			}}}},
+		}}}, nil
+	}
+	return nil, fmt.Errorf("unknown signal %q", signal)
+}
+
+// exportGRPC sends the request with the signal's service
+func exportGRPC(ctx context.Context, conn *grpc.ClientConn, signal Signal, req proto.Message) error {
+	var err error
+	switch r := req.(type) {
+	case *coltracepb.ExportTraceServiceRequest:
+		_, err = coltracepb.NewTraceServiceClient(conn).Export(ctx, r)
+	case *colmetricspb.ExportMetricsServiceRequest:
+		_, err = colmetricspb.NewMetricsServiceClient(conn).Export(ctx, r)
+	case *collogspb.ExportLogsServiceRequest:
+		_, err = collogspb.NewLogsServiceClient(conn).Export(ctx, r)
+	default:
+		err = fmt.Errorf("unknown signal %q", signal)
+	}
+	return err
+}
+
+// idFields are the fields OTLP/JSON encodes as hex, where protojson would encode them as base64
+var idFields = map[string]bool{"traceId": true, "spanId": true, "parentSpanId": true}
+
+// marshalJSON encodes a request as OTLP/JSON, which differs from the canonical protobuf JSON mapping in its enums and
+// IDs
+func marshalJSON(req proto.Message) ([]byte, error) {
+	b, err := protojson.MarshalOptions{UseEnumNumbers: true}.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	if err := hexIDs(v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+func hexIDs(v any) error {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, field := range v {
+			if s, ok := field.(string); ok && idFields[k] {
+				id, err := base64.StdEncoding.DecodeString(s)
+				if err != nil {
+					return err
+				}
+				v[k] = hex.EncodeToString(id)
+				continue
+			}
+			if err := hexIDs(field); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if err := hexIDs(item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
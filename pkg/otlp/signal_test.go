@@ -0,0 +1,171 @@
+package otlp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeSignalServices counts the items every signal's service received
+type fakeSignalServices struct {
+	coltracepb.UnimplementedTraceServiceServer
+	mu       sync.Mutex
+	received map[Signal]int
+}
+
+func (f *fakeSignalServices) add(signal Signal, n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.received[signal] += n
+}
+
+func (f *fakeSignalServices) Export(_ context.Context, req *coltracepb.ExportTraceServiceRequest) (*coltracepb.ExportTraceServiceResponse, error) {
+	f.add(SignalTraces, len(req.ResourceSpans[0].ScopeSpans[0].Spans))
+	return &coltracepb.ExportTraceServiceResponse{}, nil
+}
+
+type fakeMetricsService struct {
+	colmetricspb.UnimplementedMetricsServiceServer
+	*fakeSignalServices
+}
+
+func (f fakeMetricsService) Export(_ context.Context, req *colmetricspb.ExportMetricsServiceRequest) (*colmetricspb.ExportMetricsServiceResponse, error) {
+	f.add(SignalMetrics, len(req.ResourceMetrics[0].ScopeMetrics[0].Metrics))
+	return &colmetricspb.ExportMetricsServiceResponse{}, nil
+}
+
+type fakeLogsService struct {
+	collogspb.UnimplementedLogsServiceServer
+	*fakeSignalServices
+}
+
+func (f fakeLogsService) Export(_ context.Context, req *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error) {
+	f.add(SignalLogs, len(req.ResourceLogs[0].ScopeLogs[0].LogRecords))
+	return &collogspb.ExportLogsServiceResponse{}, nil
+}
+
+// newFakeSignalServers starts a gRPC and an HTTP server that count what they receive, the HTTP server decodes protobuf
+// and JSON bodies
+func newFakeSignalServers(t *testing.T) (grpcEndpoint, httpEndpoint string, services *fakeSignalServices) {
+	services = &fakeSignalServices{received: map[Signal]int{}}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	coltracepb.RegisterTraceServiceServer(server, services)
+	colmetricspb.RegisterMetricsServiceServer(server, fakeMetricsService{fakeSignalServices: services})
+	collogspb.RegisterLogsServiceServer(server, fakeLogsService{fakeSignalServices: services})
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var signal Signal
+		for _, s := range Signals {
+			if s.Path() == r.URL.Path {
+				signal = s
+			}
+		}
+		body, _ := io.ReadAll(r.Body)
+		switch r.Header.Get("Content-Type") {
+		case "application/x-protobuf":
+			var req proto.Message
+			switch signal {
+			case SignalTraces:
+				req = &coltracepb.ExportTraceServiceRequest{}
+			case SignalMetrics:
+				req = &colmetricspb.ExportMetricsServiceRequest{}
+			case SignalLogs:
+				req = &collogspb.ExportLogsServiceRequest{}
+			default:
+				http.NotFound(w, r)
+				return
+			}
+			if proto.Unmarshal(body, req) != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		case "application/json":
+			if !json.Valid(body) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		default:
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		services.add(signal, 1)
+	}))
+	t.Cleanup(httpServer.Close)
+	return listener.Addr().String(), strings.TrimPrefix(httpServer.URL, "http://"), services
+}
+
+func TestClient_Send(t *testing.T) {
+	grpcEndpoint, httpEndpoint, services := newFakeSignalServers(t)
+	for _, transport := range Transports {
+		for _, signal := range Signals {
+			t.Run(string(transport)+" "+string(signal), func(t *testing.T) {
+				endpoint := httpEndpoint
+				if transport == TransportGRPC {
+					endpoint = grpcEndpoint
+				}
+				before := services.received[signal]
+				err := NewClient(endpoint, true, nil, WithResource(map[string]string{"service.name": "collector-cluster-check"})).
+					Send(context.Background(), transport, signal)
+				require.NoError(t, err)
+				assert.Equal(t, before+1, services.received[signal])
+			})
+		}
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	req, err := newRequest(SignalTraces, nil)
+	require.NoError(t, err)
+	b, err := marshalJSON(req)
+	require.NoError(t, err)
+
+	var decoded struct {
+		ResourceSpans []struct {
+			ScopeSpans []struct {
+				Spans []struct {
+					TraceID string `json:"traceId"`
+					SpanID  string `json:"spanId"`
+					Kind    int    `json:"kind"`
+				} `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	span := decoded.ResourceSpans[0].ScopeSpans[0].Spans[0]
+	assert.Regexp(t, "^[0-9a-f]{32}$", span.TraceID, "OTLP/JSON encodes IDs as hex")
+	assert.Regexp(t, "^[0-9a-f]{16}$", span.SpanID)
+	assert.Equal(t, 3, span.Kind, "OTLP/JSON encodes enums as numbers")
+}
+
+func TestClient_WithSecurity(t *testing.T) {
+	headers := map[string]string{token: "secret"}
+	secure := NewClient("ingest.lightstep.com:443", false, headers)
+
+	insecure := secure.WithSecurity(true)
+	assert.True(t, insecure.Insecure())
+	assert.Empty(t, insecure.headers, "credentials are not sent in the clear")
+	assert.False(t, secure.Insecure(), "the original client is unchanged")
+
+	assert.Equal(t, headers, NewClient("localhost:4317", true, headers).WithSecurity(true).headers)
+	assert.Equal(t, headers, NewClient("localhost:4317", true, headers).WithSecurity(false).headers)
+}
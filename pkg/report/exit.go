@@ -0,0 +1,48 @@
+package report
+
+import (
+	"fmt"
+
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+// Exit codes of the check command, so it can be used as a gate in scripts and pipelines
+const (
+	// ExitPass means every step passed
+	ExitPass = 0
+	// ExitFail means at least one step failed, or warned when warnings fail the run
+	ExitFail = 1
+	// ExitWarning means no step failed but at least one warned
+	ExitWarning = 2
+	// ExitError means the tool itself failed, either a step errored or the command was misused
+	ExitError = 3
+)
+
+// ParseFailOn validates the lowest status that should fail the run
+func ParseFailOn(s string) (steps.Status, error) {
+	switch s {
+	case steps.StatusWarning.String():
+		return steps.StatusWarning, nil
+	case steps.StatusFail.String():
+		return steps.StatusFail, nil
+	}
+	return 0, fmt.Errorf("unknown fail-on status %q, must be one of %s, %s", s, steps.StatusWarning, steps.StatusFail)
+}
+
+// ExitCode is the exit code for the report, failOn is the lowest status that fails the run, so warnings exit with
+// ExitFail rather than ExitWarning when it's StatusWarning. Errors take precedence over failures, which take precedence
+// over warnings.
+func (r *Report) ExitCode(failOn steps.Status) int {
+	switch r.status {
+	case steps.StatusError:
+		return ExitError
+	case steps.StatusFail:
+		return ExitFail
+	case steps.StatusWarning:
+		if failOn == steps.StatusWarning {
+			return ExitFail
+		}
+		return ExitWarning
+	}
+	return ExitPass
+}
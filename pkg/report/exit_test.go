@@ -0,0 +1,80 @@
+package report
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+func TestReport_ExitCode(t *testing.T) {
+	pass := steps.NewResults(named("pass"), steps.NewSuccessfulResult("ok"))
+	warn := steps.NewResults(named("warn"), steps.NewAcceptableFailureResult(fmt.Errorf("exists")))
+	fail := steps.NewResults(named("fail"), steps.NewFailureResult(fmt.Errorf("boom")))
+	skip := steps.NewResults(named("skip"), steps.NewSkippedResult("skipped: fail failed"))
+	errored := steps.NewResults(named("error"), steps.NewErrorResult(fmt.Errorf("panic: oops")))
+	tests := []struct {
+		name   string
+		checks [][]steps.Results
+		failOn steps.Status
+		want   int
+	}{
+		{
+			name:   "nothing ran",
+			failOn: steps.StatusFail,
+			want:   ExitPass,
+		},
+		{
+			name:   "all passed",
+			checks: [][]steps.Results{{pass}, {pass}},
+			failOn: steps.StatusWarning,
+			want:   ExitPass,
+		},
+		{
+			name:   "warnings only",
+			checks: [][]steps.Results{{pass, warn}},
+			failOn: steps.StatusFail,
+			want:   ExitWarning,
+		},
+		{
+			name:   "warnings fail when asked to",
+			checks: [][]steps.Results{{pass, warn}},
+			failOn: steps.StatusWarning,
+			want:   ExitFail,
+		},
+		{
+			name:   "failures win over warnings across checks",
+			checks: [][]steps.Results{{warn}, {fail, skip}},
+			failOn: steps.StatusWarning,
+			want:   ExitFail,
+		},
+		{
+			name:   "errors win over failures",
+			checks: [][]steps.Results{{fail}, {errored}},
+			failOn: steps.StatusFail,
+			want:   ExitError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New()
+			for i, c := range tt.checks {
+				r.Add(fmt.Sprintf("check%d", i), nil, c)
+			}
+			assert.Equal(t, tt.want, r.ExitCode(tt.failOn))
+		})
+	}
+}
+
+func TestParseFailOn(t *testing.T) {
+	s, err := ParseFailOn("warn")
+	assert.NoError(t, err)
+	assert.Equal(t, steps.StatusWarning, s)
+	s, err = ParseFailOn("fail")
+	assert.NoError(t, err)
+	assert.Equal(t, steps.StatusFail, s)
+	_, err = ParseFailOn("skip")
+	assert.EqualError(t, err, `unknown fail-on status "skip", must be one of warn, fail`)
+}
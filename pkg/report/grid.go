@@ -0,0 +1,114 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// grid lays out the results of a step that sends every signal over every transport, e.g. the transport matrix, as
+// one row per signal and one column per transport and security setting
+type grid struct {
+	columns []string
+	rows    []string
+	cells   map[string]map[string]string
+}
+
+// newGrid only lays out steps whose every result has a signal and a transport, rows and columns keep the order of the
+// results
+func newGrid(s Step) (grid, bool) {
+	g := grid{cells: map[string]map[string]string{}}
+	if len(s.Results) == 0 {
+		return g, false
+	}
+	for _, result := range s.Results {
+		signal, ok := result.Attributes["signal"]
+		if !ok {
+			return g, false
+		}
+		transport, ok := result.Attributes["transport"]
+		if !ok {
+			return g, false
+		}
+		row, column := fmt.Sprint(signal), fmt.Sprint(transport)
+		if security, ok := result.Attributes["security"]; ok {
+			column = fmt.Sprintf("%s (%s)", column, security)
+		}
+		if _, ok := g.cells[row]; !ok {
+			g.rows = append(g.rows, row)
+			g.cells[row] = map[string]string{}
+		}
+		if !contains(g.columns, column) {
+			g.columns = append(g.columns, column)
+		}
+		cell := prettyStatus(result.status)
+		if latency, ok := result.Attributes["latency"]; ok {
+			cell = fmt.Sprintf("%s %v", cell, latency)
+		}
+		g.cells[row][column] = cell
+	}
+	return g, true
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// renderGridTables renders a grid for every step that can be laid out as one
+func renderGridTables(w io.Writer, stepReports []Step) {
+	for _, s := range stepReports {
+		g, ok := newGrid(s)
+		if !ok {
+			continue
+		}
+		t := table.NewWriter()
+		header := table.Row{s.Name}
+		for _, column := range g.columns {
+			header = append(header, column)
+		}
+		t.AppendHeader(header)
+		for _, row := range g.rows {
+			r := table.Row{row}
+			for _, column := range g.columns {
+				r = append(r, g.cells[row][column])
+			}
+			t.AppendRow(r)
+		}
+		t.SetOutputMirror(w)
+		t.SetStyle(table.StyleLight)
+		t.Render()
+	}
+}
+
+func writeMarkdownGrids(b *strings.Builder, stepReports []Step) {
+	for _, s := range stepReports {
+		g, ok := newGrid(s)
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(b, "\n### %s\n\n", markdownEscape(s.Name))
+		b.WriteString("| Signal |")
+		for _, column := range g.columns {
+			fmt.Fprintf(b, " %s |", markdownEscape(column))
+		}
+		b.WriteString("\n|--------|")
+		for range g.columns {
+			b.WriteString("---|")
+		}
+		b.WriteString("\n")
+		for _, row := range g.rows {
+			fmt.Fprintf(b, "| %s |", markdownEscape(row))
+			for _, column := range g.columns {
+				fmt.Fprintf(b, " %s |", g.cells[row][column])
+			}
+			b.WriteString("\n")
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// renderJUnit renders a test suite per check with a test case per dependency and step. Warnings pass,
+// their details are kept in the test case output.
+func (r *Report) renderJUnit(w io.Writer) error {
+	suites := junitTestSuites{Name: "collector-cluster-check"}
+	for _, c := range r.Checks {
+		suite := junitTestSuite{Name: c.Name}
+		var seconds float64
+		for _, group := range []struct {
+			className string
+			steps     []Step
+		}{
+			{className: c.Name + ".dependencies", steps: c.Dependencies},
+			{className: c.Name + ".steps", steps: c.Steps},
+		} {
+			for _, s := range group.steps {
+				tc := newJUnitTestCase(group.className, s)
+				seconds += s.duration.Seconds()
+				suite.Tests++
+				switch {
+				case tc.Failure != nil:
+					suite.Failures++
+				case tc.Error != nil:
+					suite.Errors++
+				case tc.Skipped != nil:
+					suite.Skipped++
+				}
+				suite.TestCases = append(suite.TestCases, tc)
+			}
+		}
+		suite.Time = fmt.Sprintf("%.3f", seconds)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		suites.Skipped += suite.Skipped
+		suites.Suites = append(suites.Suites, suite)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func newJUnitTestCase(className string, s Step) junitTestCase {
+	tc := junitTestCase{
+		Name:      s.Name,
+		ClassName: className,
+		Time:      fmt.Sprintf("%.3f", s.duration.Seconds()),
+	}
+	var lines []string
+	for _, result := range s.Results {
+		lines = append(lines, junitLine(result))
+	}
+	body := strings.Join(lines, "\n")
+	message := junitSummary(s)
+	switch s.status {
+	case steps.StatusFail:
+		tc.Failure = &junitMessage{Message: message, Body: body}
+	case steps.StatusError:
+		tc.Error = &junitMessage{Message: message, Body: body}
+	case steps.StatusSkipped:
+		tc.Skipped = &junitMessage{Message: message}
+	default:
+		tc.SystemOut = body
+	}
+	return tc
+}
+
+// junitSummary is the first result that explains the step's status
+func junitSummary(s Step) string {
+	for _, result := range s.Results {
+		if result.status != s.status {
+			continue
+		}
+		if result.Error != "" {
+			return result.Error
+		}
+		return result.Message
+	}
+	return s.Status
+}
+
+func junitLine(result Result) string {
+	line := fmt.Sprintf("[%s] %s", result.Status, result.Message)
+	if result.Error != "" {
+		line += fmt.Sprintf(" (error: %s)", result.Error)
+	}
+	if len(result.Attributes) > 0 {
+		line += " " + prettyAttributes(result.Attributes, " ")
+	}
+	return line
+}
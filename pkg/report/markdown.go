@@ -0,0 +1,49 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// renderMarkdown renders a section per check, suitable for a pull request comment or a CI job summary
+func (r *Report) renderMarkdown(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Collector cluster check %s\n", prettyStatus(r.status))
+	for _, c := range r.Checks {
+		fmt.Fprintf(&b, "\n## %s %s\n", c.Name, prettyStatus(c.status))
+		writeMarkdownTable(&b, "Dependencies", c.Dependencies)
+		writeMarkdownTable(&b, "Steps", c.Steps)
+		writeMarkdownGrids(&b, c.Steps)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeMarkdownTable(b *strings.Builder, title string, stepReports []Step) {
+	if len(stepReports) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n### %s\n\n", title)
+	b.WriteString("| Name | Result | Message | Error | Attempts | Duration | Attributes |\n")
+	b.WriteString("|------|--------|---------|-------|----------|----------|------------|\n")
+	for _, s := range stepReports {
+		for _, result := range s.Results {
+			fmt.Fprintf(b, "| %s | %s | %s | %s | %s | %s | %s |\n",
+				markdownEscape(s.Name),
+				prettyStatus(result.status),
+				markdownEscape(result.Message),
+				markdownEscape(result.Error),
+				markdownEscape(prettyAttempts(s)),
+				prettyDuration(s),
+				markdownEscape(prettyAttributes(result.Attributes, "<br>")),
+			)
+		}
+	}
+}
+
+// markdownEscape keeps a value from breaking out of its table cell
+func markdownEscape(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
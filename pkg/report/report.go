@@ -0,0 +1,171 @@
+// Package report turns the results of checks into a document that can be rendered in several formats.
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+// SchemaVersion is bumped whenever a field of the JSON/YAML report is removed or changes meaning
+const SchemaVersion = "v1"
+
+type Format string
+
+const (
+	FormatTable    Format = "table"
+	FormatJSON     Format = "json"
+	FormatYAML     Format = "yaml"
+	FormatJUnit    Format = "junit"
+	FormatMarkdown Format = "markdown"
+)
+
+// Formats are every supported format, in the order they are documented
+var Formats = []Format{FormatTable, FormatJSON, FormatYAML, FormatJUnit, FormatMarkdown}
+
+// ParseFormat validates a format given on the command line
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats {
+		if strings.EqualFold(s, string(f)) {
+			return f, nil
+		}
+	}
+	names := make([]string, len(Formats))
+	for i, f := range Formats {
+		names[i] = string(f)
+	}
+	return "", fmt.Errorf("unknown output format %q, must be one of %s", s, strings.Join(names, ", "))
+}
+
+// Report is the outcome of every check that was run
+type Report struct {
+	SchemaVersion string  `json:"schemaVersion" yaml:"schemaVersion"`
+	Status        string  `json:"status" yaml:"status"`
+	Checks        []Check `json:"checks" yaml:"checks"`
+
+	status steps.Status
+}
+
+// Check is the outcome of a single check, its dependencies are reported separately from its steps
+type Check struct {
+	Name         string `json:"name" yaml:"name"`
+	Status       string `json:"status" yaml:"status"`
+	Dependencies []Step `json:"dependencies" yaml:"dependencies"`
+	Steps        []Step `json:"steps" yaml:"steps"`
+
+	status steps.Status
+}
+
+// Step is the outcome of a single step or dependency
+type Step struct {
+	Name   string `json:"name" yaml:"name"`
+	Status string `json:"status" yaml:"status"`
+	// Start is omitted for steps that never ran
+	Start      *time.Time `json:"start,omitempty" yaml:"start,omitempty"`
+	DurationMs int64      `json:"durationMs" yaml:"durationMs"`
+	Attempts   int        `json:"attempts" yaml:"attempts"`
+	// LastError is the error of the last failed attempt, even when a retry succeeded
+	LastError string   `json:"lastError,omitempty" yaml:"lastError,omitempty"`
+	Results   []Result `json:"results" yaml:"results"`
+
+	status   steps.Status
+	duration time.Duration
+}
+
+// Result is a single result of a step, a step may produce several
+type Result struct {
+	Status     string         `json:"status" yaml:"status"`
+	Message    string         `json:"message,omitempty" yaml:"message,omitempty"`
+	Error      string         `json:"error,omitempty" yaml:"error,omitempty"`
+	Attributes map[string]any `json:"attributes,omitempty" yaml:"attributes,omitempty"`
+
+	status steps.Status
+}
+
+func New() *Report {
+	return &Report{SchemaVersion: SchemaVersion, status: steps.StatusPass, Status: steps.StatusPass.String()}
+}
+
+// Add records the results of a check
+func (r *Report) Add(name string, depResults []steps.Results, checkResults []steps.Results) {
+	c := Check{
+		Name:         name,
+		Dependencies: newSteps(depResults),
+		Steps:        newSteps(checkResults),
+		status:       steps.StatusPass,
+	}
+	for _, group := range [][]Step{c.Dependencies, c.Steps} {
+		for _, s := range group {
+			if s.status.Worse(c.status) {
+				c.status = s.status
+			}
+		}
+	}
+	c.Status = c.status.String()
+	r.Checks = append(r.Checks, c)
+	if c.status.Worse(r.status) {
+		r.status = c.status
+		r.Status = c.status.String()
+	}
+}
+
+// Worst is the worst status of every check
+func (r *Report) Worst() steps.Status {
+	return r.status
+}
+
+func newSteps(results []steps.Results) []Step {
+	toReturn := make([]Step, 0, len(results))
+	for _, rs := range results {
+		s := Step{
+			Name:       rs.StepName(),
+			Status:     rs.Status().String(),
+			DurationMs: rs.Duration().Milliseconds(),
+			Attempts:   rs.Attempts(),
+			Results:    make([]Result, 0, len(rs.Steps())),
+			status:     rs.Status(),
+			duration:   rs.Duration(),
+		}
+		if !rs.Start().IsZero() {
+			start := rs.Start()
+			s.Start = &start
+		}
+		if rs.LastErr() != nil {
+			s.LastError = rs.LastErr().Error()
+		}
+		for _, result := range rs.Steps() {
+			res := Result{
+				Status:     result.Status().String(),
+				Message:    result.Message(),
+				Attributes: result.Attributes(),
+				status:     result.Status(),
+			}
+			if result.Err() != nil {
+				res.Error = result.Err().Error()
+			}
+			s.Results = append(s.Results, res)
+		}
+		toReturn = append(toReturn, s)
+	}
+	return toReturn
+}
+
+// Render writes the report in the given format
+func (r *Report) Render(w io.Writer, format Format) error {
+	switch format {
+	case FormatTable:
+		return r.renderTable(w)
+	case FormatJSON:
+		return r.renderJSON(w)
+	case FormatYAML:
+		return r.renderYAML(w)
+	case FormatJUnit:
+		return r.renderJUnit(w)
+	case FormatMarkdown:
+		return r.renderMarkdown(w)
+	}
+	return fmt.Errorf("unknown output format %q", format)
+}
@@ -0,0 +1,141 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+type named string
+
+func (n named) Name() string {
+	return string(n)
+}
+
+func (n named) Description() string {
+	return ""
+}
+
+func testReport() *Report {
+	r := New()
+	r.Add("preflight",
+		[]steps.Results{
+			steps.NewResults(named("CreateKubeClient"), steps.NewSuccessfulResult("initialize dynamic client")),
+		},
+		[]steps.Results{
+			steps.NewResults(named("PodRunning"), steps.NewSuccessfulResult("test, ").WithAttribute("pods", []string{"default/test"})),
+			steps.NewResults(named("CRDExists"), steps.NewFailureResultWithHelp(fmt.Errorf("not found"), "install the operator")),
+			steps.NewResults(named("CreateCollector"), steps.NewAcceptableFailureResult(fmt.Errorf("already exists"))),
+			steps.NewResults(named("PodWatcher"), steps.NewSkippedResult("skipped: CRDExists failed")),
+		},
+	)
+	return r
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := ParseFormat("JSON")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatJSON, f)
+	_, err = ParseFormat("csv")
+	assert.EqualError(t, err, `unknown output format "csv", must be one of table, json, yaml, junit, markdown`)
+}
+
+func TestReport_RenderJSON(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, testReport().Render(&b, FormatJSON))
+	var got Report
+	require.NoError(t, json.Unmarshal(b.Bytes(), &got))
+	assert.Equal(t, SchemaVersion, got.SchemaVersion)
+	assert.Equal(t, "fail", got.Status)
+	require.Len(t, got.Checks, 1)
+	assert.Equal(t, "preflight", got.Checks[0].Name)
+	assert.Equal(t, "fail", got.Checks[0].Status)
+	assert.Equal(t, "CreateKubeClient", got.Checks[0].Dependencies[0].Name)
+	require.Len(t, got.Checks[0].Steps, 4)
+	assert.Equal(t, []any{"default/test"}, got.Checks[0].Steps[0].Results[0].Attributes["pods"])
+	assert.Equal(t, Result{Status: "fail", Message: "install the operator", Error: "not found"}, got.Checks[0].Steps[1].Results[0])
+	assert.Equal(t, "warn", got.Checks[0].Steps[2].Status)
+	assert.Equal(t, "skip", got.Checks[0].Steps[3].Status)
+	assert.Nil(t, got.Checks[0].Steps[3].Start)
+}
+
+func TestReport_RenderYAML(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, testReport().Render(&b, FormatYAML))
+	var got Report
+	require.NoError(t, yaml.Unmarshal(b.Bytes(), &got))
+	assert.Equal(t, "fail", got.Status)
+	assert.Equal(t, "CRDExists", got.Checks[0].Steps[1].Name)
+	assert.Equal(t, "not found", got.Checks[0].Steps[1].Results[0].Error)
+}
+
+func TestReport_RenderJUnit(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, testReport().Render(&b, FormatJUnit))
+	var got junitTestSuites
+	require.NoError(t, xml.Unmarshal(b.Bytes(), &got))
+	assert.Equal(t, 5, got.Tests)
+	assert.Equal(t, 1, got.Failures)
+	assert.Equal(t, 1, got.Skipped)
+	assert.Equal(t, 0, got.Errors)
+	require.Len(t, got.Suites, 1)
+	cases := got.Suites[0].TestCases
+	assert.Equal(t, "preflight.dependencies", cases[0].ClassName)
+	assert.Equal(t, "preflight.steps", cases[1].ClassName)
+	assert.Equal(t, "not found", cases[2].Failure.Message)
+	assert.Nil(t, cases[3].Failure)
+	assert.Contains(t, cases[3].SystemOut, "[warn]")
+	assert.Equal(t, "skipped: CRDExists failed", cases[4].Skipped.Message)
+}
+
+func TestReport_RenderMarkdown(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, testReport().Render(&b, FormatMarkdown))
+	assert.Contains(t, b.String(), "## preflight 🟥 fail")
+	assert.Contains(t, b.String(), "| CRDExists | 🟥 fail | install the operator | not found |  |  |  |")
+	assert.Contains(t, b.String(), "| PodRunning | 🟩 pass | test,  |  |  |  | pods=[default/test] |")
+}
+
+func TestReport_RenderTable(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, testReport().Render(&b, FormatTable))
+	assert.Contains(t, b.String(), "CreateKubeClient")
+	assert.Contains(t, b.String(), "⬜ skip")
+}
+
+func TestReport_RenderGrid(t *testing.T) {
+	cell := func(r steps.Result, signal, transport, latency string) steps.Result {
+		return r.WithAttribute("signal", signal).WithAttribute("transport", transport).WithAttribute("security", "tls").WithAttribute("latency", latency)
+	}
+	r := New()
+	r.Add("matrix", nil, []steps.Results{
+		steps.NewResults(named("TransportMatrix"),
+			cell(steps.NewSuccessfulResult("accepted"), "traces", "grpc", "12ms"),
+			cell(steps.NewAcceptableFailureResult(fmt.Errorf("404 Not Found")), "traces", "http/json", "3ms"),
+			cell(steps.NewSuccessfulResult("accepted"), "logs", "grpc", "8ms"),
+			cell(steps.NewSuccessfulResult("accepted"), "logs", "http/json", "4ms"),
+		),
+		// results without a signal aren't laid out as a grid
+		steps.NewResults(named("ExportProbe"), steps.NewSuccessfulResult("accepted").WithAttribute("transport", "grpc")),
+	})
+
+	var md bytes.Buffer
+	require.NoError(t, r.Render(&md, FormatMarkdown))
+	assert.Contains(t, md.String(), "| Signal | grpc (tls) | http/json (tls) |\n")
+	assert.Contains(t, md.String(), "| traces | 🟩 pass 12ms | 🟨 warn 3ms |\n")
+	assert.Contains(t, md.String(), "| logs | 🟩 pass 8ms | 🟩 pass 4ms |\n")
+	assert.NotContains(t, md.String(), "### ExportProbe")
+
+	var table bytes.Buffer
+	require.NoError(t, r.Render(&table, FormatTable))
+	assert.Contains(t, table.String(), "HTTP/JSON (TLS)")
+	assert.Contains(t, table.String(), "🟨 warn 3ms")
+}
@@ -0,0 +1,83 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+var statusIcons = map[steps.Status]string{
+	steps.StatusPass:    "🟩",
+	steps.StatusWarning: "🟨",
+	steps.StatusFail:    "🟥",
+	steps.StatusSkipped: "⬜",
+	steps.StatusError:   "🟧",
+}
+
+// renderTable renders a table of dependencies and a table of steps for every check, followed by a grid for every step
+// that sends each signal over several transports
+func (r *Report) renderTable(w io.Writer) error {
+	for _, c := range r.Checks {
+		renderStepTable(w, "dependency", c.Dependencies)
+		renderStepTable(w, "Checker", c.Steps)
+		renderGridTables(w, c.Steps)
+	}
+	return nil
+}
+
+func renderStepTable(w io.Writer, header string, stepReports []Step) {
+	t := table.NewWriter()
+	rowConfigAutoMerge := table.RowConfig{AutoMerge: true}
+	t.AppendHeader(table.Row{header, "Result", "Message", "Error", "Attempts", "Duration", "Attributes"})
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Number: 1, AutoMerge: true},
+	})
+	for _, s := range stepReports {
+		for _, result := range s.Results {
+			t.AppendRow(table.Row{s.Name, prettyStatus(result.status), result.Message, result.Error, prettyAttempts(s), prettyDuration(s), prettyAttributes(result.Attributes, "\n")}, rowConfigAutoMerge)
+		}
+	}
+	t.SetOutputMirror(w)
+	t.SetStyle(table.StyleLight)
+	t.Style().Options.SeparateRows = true
+	t.Render()
+}
+
+func prettyStatus(status steps.Status) string {
+	return fmt.Sprintf("%s %s", statusIcons[status], status)
+}
+
+// prettyAttempts only mentions attempts when a retry happened
+func prettyAttempts(s Step) string {
+	if s.Attempts <= 1 {
+		return ""
+	}
+	return fmt.Sprintf("%d (last error: %s)", s.Attempts, s.LastError)
+}
+
+func prettyDuration(s Step) string {
+	if s.Start == nil {
+		return ""
+	}
+	return s.duration.Round(time.Millisecond).String()
+}
+
+// prettyAttributes lists attributes sorted by key so the output is stable
+func prettyAttributes(attributes map[string]any, sep string) string {
+	keys := make([]string, 0, len(attributes))
+	for k := range attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	lines := make([]string, len(keys))
+	for i, k := range keys {
+		lines[i] = fmt.Sprintf("%s=%v", k, attributes[k])
+	}
+	return strings.Join(lines, sep)
+}
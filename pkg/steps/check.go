@@ -2,24 +2,73 @@ package steps
 
 import (
 	"context"
+	"time"
+)
+
+const (
+	// shutdownTimeout bounds how long dependencies have to tear down once a check is over
+	shutdownTimeout = 10 * time.Second
+	// finalizerTimeout bounds how long finalizers have to run once the check's context is cancelled
+	finalizerTimeout = 30 * time.Second
 )
 
 type Check struct {
 	name        string
 	description string
-	steps       []Step
-	depMap      map[string]Dependency
+	// stages run one after another, every lane of a stage may run concurrently with the others
+	stages []stage
+	// finalizers always run after steps, even when a step failed or the check was cancelled
+	finalizers []Step
+	depMap     map[string]Dependency
+	// initialized holds every dependency that was successfully run or timed out, in the order it was added to the
+	// check's graph
+	initialized []Dependency
 }
 
-func NewCheck(name string, description string, steps []Step) *Check {
+// NewCheck creates a check from lanes of steps. Steps within a lane run in the order they are listed and stop at
+// the first failure, while separate lanes only wait on the dependencies they share.
+func NewCheck(name string, description string, lanes ...[]Step) *Check {
 	return &Check{
 		name:        name,
 		description: description,
-		steps:       steps,
+		stages:      []stage{{lanes: lanes}},
 		depMap:      map[string]Dependency{},
 	}
 }
 
+type stage struct {
+	lanes [][]Step
+	// regardless stages start once the lanes before them finished, even when some of them failed
+	regardless bool
+}
+
+// Independent puts every step in its own lane, for steps that don't need to run in any particular order.
+func Independent(steps ...Step) [][]Step {
+	lanes := make([][]Step, len(steps))
+	for i, step := range steps {
+		lanes[i] = []Step{step}
+	}
+	return lanes
+}
+
+// Then adds a stage of lanes that only starts once every lane added before it succeeded.
+func (c *Check) Then(lanes ...[]Step) *Check {
+	c.stages = append(c.stages, stage{lanes: lanes})
+	return c
+}
+
+// After adds a stage of lanes that only starts once every lane added before it finished, however it went.
+func (c *Check) After(lanes ...[]Step) *Check {
+	c.stages = append(c.stages, stage{lanes: lanes, regardless: true})
+	return c
+}
+
+// WithFinalizers marks steps that must always run once the check's steps are over, like deferred calls.
+func (c *Check) WithFinalizers(finalizers ...Step) *Check {
+	c.finalizers = append(c.finalizers, finalizers...)
+	return c
+}
+
 func (c *Check) Name() string {
 	return c.name
 }
@@ -29,45 +78,61 @@ func (c *Check) Description() string {
 }
 
 func (c *Check) Run(ctx context.Context, deps *Deps, conf *Config) ([]Results, []Results) {
-	var acc []Results
-	var depAcc []Results
-	for _, step := range c.steps {
-		depResults, shouldContinue := c.initDeps(ctx, step.Dependencies(conf), deps, conf)
-		depAcc = append(depAcc, depResults...)
-		if !shouldContinue {
-			break
-		}
-		r := step.Run(ctx, deps)
-		acc = append(acc, r)
-		if r.ShouldStop() {
-			break
+	g := newGraph(c.depMap)
+	var barrier []*node
+	for _, stage := range c.stages {
+		var tails []*node
+		for _, lane := range stage.lanes {
+			predecessors := barrier
+			for i, step := range lane {
+				blocking := i > 0 || !stage.regardless
+				predecessors = []*node{g.addStep(step, conf, predecessors, blocking)}
+			}
+			tails = append(tails, predecessors...)
 		}
+		barrier = tails
 	}
+	depAcc, acc := c.execute(ctx, g, deps, conf, conf.Parallelism)
+	finalizerDeps, finalizerResults := c.runFinalizers(ctx, deps, conf)
+	depAcc = append(depAcc, finalizerDeps...)
+	acc = append(acc, finalizerResults...)
+	depAcc = append(depAcc, c.shutdownDeps(ctx)...)
 	return depAcc, acc
 }
 
+// runFinalizers runs every finalizer in order regardless of how the steps, or the finalizers before it, went.
+// Finalizers use their own context so that they still happen when the check's context was cancelled.
+func (c *Check) runFinalizers(ctx context.Context, deps *Deps, conf *Config) ([]Results, []Results) {
+	if len(c.finalizers) == 0 {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), finalizerTimeout)
+	defer cancel()
+	g := newGraph(c.depMap)
+	var predecessors []*node
+	for _, step := range c.finalizers {
+		predecessors = []*node{g.addStep(step, conf, predecessors, false)}
+	}
+	return c.execute(ctx, g, deps, conf, 1)
+}
+
 func (c *Check) Dependencies(conf *Config) []Dependency {
 	return nil
 }
 
-func (c *Check) initDeps(ctx context.Context, dependencies []Dependency, deps *Deps, conf *Config) ([]Results, bool) {
+// shutdownDeps tears down every initialized dependency in the reverse order it was added.
+// Teardown uses its own context so that it still happens when the check's context was cancelled.
+func (c *Check) shutdownDeps(ctx context.Context) []Results {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+	defer cancel()
 	var results []Results
-	for _, dep := range dependencies {
-		depResults, shouldContinue := c.initDeps(ctx, dep.Dependencies(conf), deps, conf)
-		if !shouldContinue {
-			return depResults, shouldContinue
-		}
-		results = append(results, depResults...)
-		if _, ok := c.depMap[dep.Name()]; ok {
-			continue
-		}
-		opt, r := dep.Run(ctx, deps)
-		results = append(results, NewResults(dep, r))
-		if !r.Successful() && !r.ShouldContinue() {
-			return results, false
+	for i := len(c.initialized) - 1; i >= 0; i-- {
+		dep := c.initialized[i]
+		if err := dep.Shutdown(ctx); err != nil {
+			results = append(results, NewResults(dep, NewFailureResultWithHelp(err, "failed to shut down")))
 		}
-		c.depMap[dep.Name()] = dep
-		opt(deps)
 	}
-	return results, true
+	c.initialized = nil
+	c.depMap = map[string]Dependency{}
+	return results
 }
@@ -0,0 +1,209 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeDependency struct {
+	name        string
+	deps        []Dependency
+	result      Result
+	shutdownErr error
+	// shutdowns records the order dependencies were shut down in
+	shutdowns *[]string
+}
+
+func (f fakeDependency) Name() string {
+	return f.name
+}
+
+func (f fakeDependency) Description() string {
+	return "fake dependency"
+}
+
+func (f fakeDependency) Run(ctx context.Context, deps *Deps) (Option, Result) {
+	return Empty, f.result
+}
+
+func (f fakeDependency) Dependencies(conf *Config) []Dependency {
+	return f.deps
+}
+
+func (f fakeDependency) Shutdown(ctx context.Context) error {
+	*f.shutdowns = append(*f.shutdowns, f.name)
+	return f.shutdownErr
+}
+
+type fakeStep struct {
+	name   string
+	deps   []Dependency
+	result Result
+}
+
+func (f fakeStep) Name() string {
+	return f.name
+}
+
+func (f fakeStep) Description() string {
+	return "fake step"
+}
+
+func (f fakeStep) Run(ctx context.Context, deps *Deps) Results {
+	return NewResults(f, f.result)
+}
+
+func (f fakeStep) Dependencies(conf *Config) []Dependency {
+	return f.deps
+}
+
+// summarize lists every results as name=status
+func summarize(results []Results) []string {
+	var summary []string
+	for _, r := range results {
+		summary = append(summary, fmt.Sprintf("%s=%s", r.StepName(), r.Status()))
+	}
+	return summary
+}
+
+func TestCheck_RunShutsDownDependencies(t *testing.T) {
+	tests := []struct {
+		name          string
+		steps         func(shutdowns *[]string) []Step
+		wantShutdowns []string
+		wantDeps      []string
+	}{
+		{
+			name: "reverse order after success",
+			steps: func(shutdowns *[]string) []Step {
+				config := fakeDependency{name: "config", result: NewSuccessfulResult("ok"), shutdowns: shutdowns}
+				client := fakeDependency{name: "client", deps: []Dependency{config}, result: NewSuccessfulResult("ok"), shutdowns: shutdowns}
+				provider := fakeDependency{name: "provider", result: NewSuccessfulResult("ok"), shutdowns: shutdowns}
+				return []Step{
+					fakeStep{name: "first", deps: []Dependency{client}, result: NewSuccessfulResult("ok")},
+					fakeStep{name: "second", deps: []Dependency{client, provider}, result: NewSuccessfulResult("ok")},
+				}
+			},
+			wantShutdowns: []string{"provider", "client", "config"},
+			wantDeps:      []string{"config=pass", "client=pass", "provider=pass"},
+		},
+		{
+			name: "after a failed step",
+			steps: func(shutdowns *[]string) []Step {
+				config := fakeDependency{name: "config", result: NewSuccessfulResult("ok"), shutdowns: shutdowns}
+				provider := fakeDependency{name: "provider", result: NewSuccessfulResult("ok"), shutdowns: shutdowns}
+				return []Step{
+					fakeStep{name: "first", deps: []Dependency{config}, result: NewFailureResult(fmt.Errorf("boom"))},
+					fakeStep{name: "second", deps: []Dependency{provider}, result: NewSuccessfulResult("ok")},
+				}
+			},
+			wantShutdowns: []string{"config"},
+			wantDeps:      []string{"config=pass", "provider=skip"},
+		},
+		{
+			name: "after a failed dependency",
+			steps: func(shutdowns *[]string) []Step {
+				config := fakeDependency{name: "config", result: NewSuccessfulResult("ok"), shutdowns: shutdowns}
+				client := fakeDependency{name: "client", deps: []Dependency{config}, result: NewFailureResult(fmt.Errorf("boom")), shutdowns: shutdowns}
+				return []Step{
+					fakeStep{name: "first", deps: []Dependency{client}, result: NewSuccessfulResult("ok")},
+				}
+			},
+			wantShutdowns: []string{"config"},
+			wantDeps:      []string{"config=pass", "client=fail"},
+		},
+		{
+			name: "teardown errors are reported",
+			steps: func(shutdowns *[]string) []Step {
+				config := fakeDependency{name: "config", result: NewSuccessfulResult("ok"), shutdownErr: fmt.Errorf("stuck"), shutdowns: shutdowns}
+				return []Step{
+					fakeStep{name: "first", deps: []Dependency{config}, result: NewSuccessfulResult("ok")},
+				}
+			},
+			wantShutdowns: []string{"config"},
+			wantDeps:      []string{"config=pass", "config=fail"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var shutdowns []string
+			c := NewCheck("test", "test", tt.steps(&shutdowns))
+			depResults, _ := c.Run(context.Background(), NewDependencies(), &Config{})
+			assert.Equal(t, tt.wantShutdowns, shutdowns)
+			assert.Equal(t, tt.wantDeps, summarize(depResults))
+		})
+	}
+}
+
+func TestCheck_RunShutsDownOnCancel(t *testing.T) {
+	var shutdowns []string
+	ctx, cancel := context.WithCancel(context.Background())
+	config := fakeDependency{name: "config", result: NewSuccessfulResult("ok"), shutdowns: &shutdowns}
+	c := NewCheck("test", "test", []Step{
+		cancelStep{fakeStep{name: "first", deps: []Dependency{config}, result: NewSuccessfulResult("ok")}, cancel},
+		fakeStep{name: "second", result: NewSuccessfulResult("ok")},
+	})
+	_, results := c.Run(ctx, NewDependencies(), &Config{})
+	assert.Equal(t, []string{"first=pass", "second=skip"}, summarize(results))
+	assert.Equal(t, "skipped: check was cancelled", results[1].Steps()[0].Message())
+	assert.Equal(t, []string{"config"}, shutdowns)
+}
+
+// cancelStep cancels the check's context once it has run
+type cancelStep struct {
+	fakeStep
+	cancel context.CancelFunc
+}
+
+func (c cancelStep) Run(ctx context.Context, deps *Deps) Results {
+	defer c.cancel()
+	return c.fakeStep.Run(ctx, deps)
+}
+
+func TestCheck_RunFinalizers(t *testing.T) {
+	tests := []struct {
+		name      string
+		steps     []Step
+		cancel    bool
+		wantSteps []string
+	}{
+		{
+			name: "after success",
+			steps: []Step{
+				fakeStep{name: "first", result: NewSuccessfulResult("ok")},
+			},
+			wantSteps: []string{"first=pass", "cleanup=pass"},
+		},
+		{
+			name: "after a failed step",
+			steps: []Step{
+				fakeStep{name: "first", result: NewFailureResult(fmt.Errorf("boom"))},
+				fakeStep{name: "second", result: NewSuccessfulResult("ok")},
+			},
+			wantSteps: []string{"first=fail", "second=skip", "cleanup=pass"},
+		},
+		{
+			name: "after cancellation",
+			steps: []Step{
+				fakeStep{name: "first", result: NewSuccessfulResult("ok")},
+			},
+			cancel:    true,
+			wantSteps: []string{"first=skip", "cleanup=pass"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				cancel()
+			}
+			c := NewCheck("test", "test", tt.steps).WithFinalizers(fakeStep{name: "cleanup", result: NewSuccessfulResult("ok")})
+			_, results := c.Run(ctx, NewDependencies(), &Config{})
+			assert.Equal(t, tt.wantSteps, summarize(results))
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package steps
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CollectorEnv is an environment variable of the test collector, it resolves a ${NAME} or ${env:NAME} placeholder of
+// the collector config either to a value or to a key of a Secret in the collector's namespace
+type CollectorEnv struct {
+	Name  string
+	Value string
+	// SecretName and SecretKey are where the value is read from when they're set, so tokens stay out of the CR
+	SecretName string
+	SecretKey  string
+}
+
+// FromSecret is whether the value is read from a Secret
+func (e CollectorEnv) FromSecret() bool {
+	return e.SecretName != ""
+}
+
+// ParseCollectorEnv parses values written as NAME=value and secrets written as NAME=secret/key, sorted by name. A name
+// given as both a value and a secret is read from the secret.
+func ParseCollectorEnv(values []string, secrets []string) ([]CollectorEnv, error) {
+	env := map[string]CollectorEnv{}
+	for _, v := range values {
+		name, value, ok := strings.Cut(v, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid collector env %q, must be NAME=value", v)
+		}
+		env[name] = CollectorEnv{Name: name, Value: value}
+	}
+	for _, s := range secrets {
+		name, ref, ok := strings.Cut(s, "=")
+		name = strings.TrimSpace(name)
+		secret, key, refOk := strings.Cut(ref, "/")
+		if !ok || !refOk || name == "" || secret == "" || key == "" {
+			return nil, fmt.Errorf("invalid collector env secret %q, must be NAME=secret/key", s)
+		}
+		env[name] = CollectorEnv{Name: name, SecretName: secret, SecretKey: key}
+	}
+	parsed := make([]CollectorEnv, 0, len(env))
+	for _, e := range env {
+		parsed = append(parsed, e)
+	}
+	sort.Slice(parsed, func(i, j int) bool {
+		return parsed[i].Name < parsed[j].Name
+	})
+	return parsed, nil
+}
@@ -0,0 +1,59 @@
+package steps
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCollectorEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		secrets []string
+		want    []CollectorEnv
+		wantErr string
+	}{
+		{
+			name: "nothing",
+			want: []CollectorEnv{},
+		},
+		{
+			name:    "values and secrets sorted by name",
+			values:  []string{"SAMPLING=10", "TENANT="},
+			secrets: []string{"LS_TOKEN=lightstep/access-token"},
+			want: []CollectorEnv{
+				{Name: "LS_TOKEN", SecretName: "lightstep", SecretKey: "access-token"},
+				{Name: "SAMPLING", Value: "10"},
+				{Name: "TENANT", Value: ""},
+			},
+		},
+		{
+			name:    "secrets win over values",
+			values:  []string{"LS_TOKEN=plain"},
+			secrets: []string{"LS_TOKEN=lightstep/access-token"},
+			want:    []CollectorEnv{{Name: "LS_TOKEN", SecretName: "lightstep", SecretKey: "access-token"}},
+		},
+		{
+			name:    "value without a name",
+			values:  []string{"=10"},
+			wantErr: `invalid collector env "=10", must be NAME=value`,
+		},
+		{
+			name:    "secret without a key",
+			secrets: []string{"LS_TOKEN=lightstep"},
+			wantErr: `invalid collector env secret "LS_TOKEN=lightstep", must be NAME=secret/key`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCollectorEnv(tt.values, tt.secrets)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package steps
+
+import "fmt"
+
+// CollectorMode is how the operator deploys the test collector
+type CollectorMode string
+
+const (
+	CollectorModeDeployment  CollectorMode = "deployment"
+	CollectorModeDaemonSet   CollectorMode = "daemonset"
+	CollectorModeStatefulSet CollectorMode = "statefulset"
+	// CollectorModeSidecar injects the collector into the pods annotated with sidecar.opentelemetry.io/inject, the
+	// operator doesn't create any pod of its own
+	CollectorModeSidecar CollectorMode = "sidecar"
+)
+
+func ParseCollectorMode(s string) (CollectorMode, error) {
+	switch m := CollectorMode(s); m {
+	case CollectorModeDeployment, CollectorModeDaemonSet, CollectorModeStatefulSet, CollectorModeSidecar:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown collector mode %q, must be one of deployment, daemonset, statefulset, sidecar", s)
+}
+
+// HasReplicas is whether the collector's spec sets a number of replicas, a daemonset runs one pod per node and a
+// sidecar one per annotated pod
+func (m CollectorMode) HasReplicas() bool {
+	return m == CollectorModeDeployment || m == CollectorModeStatefulSet
+}
@@ -0,0 +1,17 @@
+package steps
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCollectorMode(t *testing.T) {
+	for _, s := range []string{"deployment", "daemonset", "statefulset", "sidecar"} {
+		m, err := ParseCollectorMode(s)
+		assert.NoError(t, err)
+		assert.Equal(t, CollectorMode(s), m)
+	}
+	_, err := ParseCollectorMode("replicaset")
+	assert.EqualError(t, err, `unknown collector mode "replicaset", must be one of deployment, daemonset, statefulset, sidecar`)
+}
@@ -1,13 +1,21 @@
 package steps
 
 import (
+	"crypto/tls"
+
+	sdklog "go.opentelemetry.io/otel/sdk/log"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+
+	"github.com/lightstep/collector-cluster-check/pkg/load"
+	"github.com/lightstep/collector-cluster-check/pkg/loopback"
+	"github.com/lightstep/collector-cluster-check/pkg/otlp"
 )
 
 type Option func(c *Deps)
@@ -17,10 +25,46 @@ type Deps struct {
 	CustomResourceClient apiextensionsclientset.Interface
 	DynamicClient        dynamic.Interface
 	MeterProvider        *sdkmetric.MeterProvider
-	TracerProvider       *sdktrace.TracerProvider
-	OtelColConfig        *unstructured.Unstructured
-	KubeConf             *rest.Config
-	PortForward          *PortForwardedResource
+	// MeterExportErrors, TraceExportErrors and LogExportErrors are every failed export of the providers, including the
+	// background ones that ForceFlush doesn't return
+	MeterExportErrors *otlp.ErrorCollector
+	TraceExportErrors *otlp.ErrorCollector
+	LogExportErrors   *otlp.ErrorCollector
+	// MetricExporter is an exporter of its own, for steps that export metrics without a provider's reader, their
+	// providers describe themselves with MetricResource like the other providers do
+	MetricExporter sdkmetric.Exporter
+	MetricResource *resource.Resource
+	TracerProvider *sdktrace.TracerProvider
+	LoggerProvider *sdklog.LoggerProvider
+	OtelColConfig  *unstructured.Unstructured
+	// CollectorWorkload is the pod the sidecar collector is injected into, it's only set in sidecar mode
+	CollectorWorkload *unstructured.Unstructured
+	KubeConf          *rest.Config
+	PortForward       *PortForwardedResource
+	// Destinations are the hosts the network checks probe, the endpoint comes first
+	Destinations []Destination
+	// Proxies are how every destination is reached, in the same order as Destinations
+	Proxies []Proxy
+	// EndpointTLS is what the exporters verify the endpoint with, nil when they use the system roots and its host
+	EndpointTLS *tls.Config
+	// ClusterProbes are the results of probing every destination from inside the cluster
+	ClusterProbes []ProbeResult
+	// OTLPClient sends export requests to the endpoint without the SDK in the way
+	OTLPClient *otlp.Client
+	// OTLPTransport is the transport the exporters are configured to use
+	OTLPTransport otlp.Transport
+	// LoadGenerator sends telemetry at the configured rates through its own providers
+	LoadGenerator *load.Generator
+	// LoopbackReceiver keeps the telemetry the test collector exported back to this machine
+	LoopbackReceiver *loopback.Receiver
+	// LoopbackEndpoint is where the test collector reaches the loopback receiver from inside the cluster
+	LoopbackEndpoint string
+	// LoopbackRunID is the run ID of the telemetry the loopback receiver must get
+	LoopbackRunID string
+	// LoopbackSent is what the steps sent through the test collector, the loopback receiver must get the same
+	LoopbackSent *loopback.Sent
+	// TransportMatrix is every transport and security setting the matrix check sends with
+	TransportMatrix []MatrixCell
 }
 
 func NewDependencies() *Deps {
@@ -31,8 +75,47 @@ type Config struct {
 	Endpoint   string
 	Insecure   bool
 	Http       bool
-	Token      string
 	KubeConfig string
+	// RunID tags the resource of every signal this run sends, so it can be told apart from other runs' telemetry
+	RunID string
+	// Headers are sent with every export request, e.g. the access token in the header the backend expects
+	Headers map[string]string
+	// Destinations are probed by the network checks in addition to the endpoint
+	Destinations []string
+	// TLS secures the exporters' connections unless Insecure is set
+	TLS ExporterTLS
+	// Compression is how the exporters compress export requests
+	Compression Compression
+	// Temporality is how the metric exporters aggregate sums and histograms
+	Temporality Temporality
+	// Proxy replaces the proxy from the environment for the exporters and network checks
+	Proxy string
+	// ProbeImage runs the in-cluster network checks, it must have sh and curl
+	ProbeImage string
+	// ProbeNamespace is where the in-cluster network checks run
+	ProbeNamespace string
+	// CollectorConfigFile replaces the embedded collector config, the receiver and telemetry the checks rely on are
+	// added when it doesn't have them
+	CollectorConfigFile string
+	// CollectorMode is how the operator deploys the test collector
+	CollectorMode CollectorMode
+	// SidecarImage runs the pod the collector is injected into in sidecar mode
+	SidecarImage string
+	// CollectorEnv resolves the environment variable placeholders of the collector config
+	CollectorEnv []CollectorEnv
+	// LoopbackImage relays the test collector's exports to the loopback receiver, it must have socat as its entrypoint
+	LoopbackImage string
+	// MatrixTransports and MatrixSecurity are what the matrix check combines, every signal is sent with every pair
+	MatrixTransports []otlp.Transport
+	MatrixSecurity   []Security
+	// Load is the telemetry the load check generates
+	Load load.Options
+	// Parallelism is how many steps and dependencies may run at the same time
+	Parallelism int
+	// DefaultPolicy applies to every step and dependency that doesn't declare its own
+	DefaultPolicy Policy
+	// Policies override the policy of the step or dependency with the same name
+	Policies map[string]Policy
 }
 
 // Empty is for a step that doesn't change configuration
@@ -46,6 +129,44 @@ func WithPortForwardedResource(pfr *PortForwardedResource) Option {
 	}
 }
 
+func WithDestinations(destinations []Destination) Option {
+	return func(c *Deps) {
+		c.Destinations = destinations
+	}
+}
+
+func WithProxies(proxies []Proxy) Option {
+	return func(c *Deps) {
+		c.Proxies = proxies
+	}
+}
+
+func WithEndpointTLS(conf *tls.Config, transport otlp.Transport) Option {
+	return func(c *Deps) {
+		c.EndpointTLS = conf
+		c.OTLPTransport = transport
+	}
+}
+
+func WithClusterProbes(probes []ProbeResult) Option {
+	return func(c *Deps) {
+		c.ClusterProbes = probes
+	}
+}
+
+func WithOTLPClient(client *otlp.Client, transport otlp.Transport) Option {
+	return func(c *Deps) {
+		c.OTLPClient = client
+		c.OTLPTransport = transport
+	}
+}
+
+func WithTransportMatrix(cells []MatrixCell) Option {
+	return func(c *Deps) {
+		c.TransportMatrix = cells
+	}
+}
+
 func WithKubeConfig(conf *rest.Config) Option {
 	return func(c *Deps) {
 		c.KubeConf = conf
@@ -58,9 +179,10 @@ func WithDynamicClient(client dynamic.Interface) Option {
 	}
 }
 
-func WithOtelColConfig(conf *unstructured.Unstructured) Option {
+func WithOtelColConfig(conf *unstructured.Unstructured, workload *unstructured.Unstructured) Option {
 	return func(c *Deps) {
 		c.OtelColConfig = conf
+		c.CollectorWorkload = workload
 	}
 }
 
@@ -76,14 +198,45 @@ func WithCustomResourceClient(client apiextensionsclientset.Interface) Option {
 	}
 }
 
-func WithMeterProvider(mp *sdkmetric.MeterProvider) Option {
+func WithMeterProvider(mp *sdkmetric.MeterProvider, errs *otlp.ErrorCollector) Option {
 	return func(c *Deps) {
 		c.MeterProvider = mp
+		c.MeterExportErrors = errs
 	}
 }
 
-func WithTracerProvider(tp *sdktrace.TracerProvider) Option {
+func WithTracerProvider(tp *sdktrace.TracerProvider, errs *otlp.ErrorCollector) Option {
 	return func(c *Deps) {
 		c.TracerProvider = tp
+		c.TraceExportErrors = errs
+	}
+}
+
+func WithLoggerProvider(lp *sdklog.LoggerProvider, errs *otlp.ErrorCollector) Option {
+	return func(c *Deps) {
+		c.LoggerProvider = lp
+		c.LogExportErrors = errs
+	}
+}
+
+func WithLoadGenerator(g *load.Generator) Option {
+	return func(c *Deps) {
+		c.LoadGenerator = g
+	}
+}
+
+func WithMetricExporter(exp sdkmetric.Exporter, res *resource.Resource) Option {
+	return func(c *Deps) {
+		c.MetricExporter = exp
+		c.MetricResource = res
+	}
+}
+
+func WithLoopback(r *loopback.Receiver, sent *loopback.Sent, endpoint string, runID string) Option {
+	return func(c *Deps) {
+		c.LoopbackReceiver = r
+		c.LoopbackSent = sent
+		c.LoopbackEndpoint = endpoint
+		c.LoopbackRunID = runID
 	}
 }
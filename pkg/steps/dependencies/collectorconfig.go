@@ -3,6 +3,10 @@ package dependencies
 import (
 	"context"
 	_ "embed"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -11,19 +15,47 @@ import (
 )
 
 type CollectorConfig struct {
-	token    string
-	endpoint string
+	headers     map[string]string
+	endpoint    string
+	http        bool
+	tls         steps.ExporterTLS
+	compression steps.Compression
+	// file replaces the embedded config, its exporters are used as they are
+	file         string
+	env          []steps.CollectorEnv
+	mode         steps.CollectorMode
+	sidecarImage string
 }
 
 func NewCollectorConfigFromConfig(config *steps.Config) CollectorConfig {
-	return CollectorConfig{endpoint: config.Endpoint, token: config.Token}
+	return CollectorConfig{
+		endpoint:     config.Endpoint,
+		http:         config.Http,
+		headers:      config.Headers,
+		tls:          config.TLS,
+		compression:  config.Compression,
+		file:         config.CollectorConfigFile,
+		env:          config.CollectorEnv,
+		mode:         config.CollectorMode,
+		sidecarImage: config.SidecarImage,
+	}
 }
 
-func NewCollectorConfig(token string, endpoint string) *CollectorConfig {
-	return &CollectorConfig{token: token, endpoint: endpoint}
+func NewCollectorConfig(headers map[string]string, endpoint string) *CollectorConfig {
+	return &CollectorConfig{headers: headers, endpoint: endpoint}
 }
 
 var _ steps.Dependency = CollectorConfig{}
+
+const (
+	collectorName = "test-col"
+	// sidecarWorkloadName is the pod the collector is injected into in sidecar mode
+	sidecarWorkloadName     = "collector-cluster-check-sidecar"
+	sidecarInjectAnnotation = "sidecar.opentelemetry.io/inject"
+	// DefaultSidecarImage runs the pod the collector is injected into, it only has to keep running
+	DefaultSidecarImage = "registry.k8s.io/pause:3.9"
+)
+
 var (
 	//go:embed config.yaml
 	collectorConfig string
@@ -41,10 +73,76 @@ func (c CollectorConfig) Description() string {
 }
 
 func (c CollectorConfig) Run(ctx context.Context, deps *steps.Deps) (steps.Option, steps.Result) {
+	raw := collectorConfig
+	if c.file != "" {
+		b, err := os.ReadFile(c.file)
+		if err != nil {
+			return steps.Empty, steps.NewFailureResultWithHelp(err, "check --collector-config")
+		}
+		raw = string(b)
+	}
 	config := map[string]interface{}{}
-	err := yaml.Unmarshal([]byte(collectorConfig), config)
+	err := yaml.Unmarshal([]byte(raw), config)
 	if err != nil {
-		return nil, steps.Result{}
+		return steps.Empty, steps.NewFailureResultWithHelp(err, "the collector config isn't valid YAML")
+	}
+	if len(pipelines(config)) == 0 {
+		return steps.Empty, steps.NewFailureResultWithHelp(fmt.Errorf("collector config has no pipelines"), "add service.pipelines to the collector config")
+	}
+	env := []map[string]interface{}{
+		{
+			"name":  "DESTINATION",
+			"value": c.endpoint,
+		},
+	}
+	// a user supplied config is tested with the exporters it runs with in production
+	if c.file == "" {
+		headerEnv, err := c.configureExporter(config)
+		if err != nil {
+			return steps.Empty, steps.NewErrorResult(err)
+		}
+		env = append(env, headerEnv...)
+	}
+	env = append(env, c.collectorEnv()...)
+	var injected []string
+	if injectOTLPReceiver(config) {
+		injected = append(injected, "otlp receiver")
+	}
+	if injectTelemetry(config) {
+		injected = append(injected, "telemetry metrics")
+	}
+	if deps.LoopbackEndpoint != "" {
+		if err := c.addLoopbackExporter(config, deps.LoopbackEndpoint); err != nil {
+			return steps.Empty, steps.NewErrorResult(err)
+		}
+	}
+	mode := c.mode
+	if mode == "" {
+		mode = steps.CollectorModeDeployment
+	}
+	spec := map[string]interface{}{
+		"mode":   string(mode),
+		"config": config,
+		"env":    env,
+	}
+	if mode.HasReplicas() {
+		spec["replicas"] = 1
+	}
+	// the CA and client certificate are read from the secret created by CreateTLSSecret
+	if len(tlsFiles(c.tls)) > 0 {
+		spec["volumes"] = []map[string]interface{}{
+			{
+				"name":   tlsVolumeName,
+				"secret": map[string]interface{}{"secretName": tlsSecretName},
+			},
+		}
+		spec["volumeMounts"] = []map[string]interface{}{
+			{
+				"name":      tlsVolumeName,
+				"mountPath": tlsMountPath,
+				"readOnly":  true,
+			},
+		}
 	}
 
 	col := &unstructured.Unstructured{
@@ -52,27 +150,161 @@ func (c CollectorConfig) Run(ctx context.Context, deps *steps.Deps) (steps.Optio
 			"apiVersion": "opentelemetry.io/v1beta1",
 			"kind":       "OpenTelemetryCollector",
 			"metadata": map[string]interface{}{
-				"name":   "test-col",
+				"name":   collectorName,
 				"labels": podLabels,
 			},
+			"spec": spec,
+		},
+	}
+	var workload *unstructured.Unstructured
+	if mode == steps.CollectorModeSidecar {
+		workload = c.sidecarWorkload()
+	}
+	msg := "retrieved CRD config"
+	if c.file != "" {
+		msg = fmt.Sprintf("retrieved CRD config from %s", c.file)
+	}
+	if len(injected) > 0 {
+		msg = fmt.Sprintf("%s, added %s", msg, strings.Join(injected, " and "))
+	}
+	return steps.WithOtelColConfig(col, workload), steps.NewSuccessfulResult(msg).WithAttribute("mode", string(mode))
+}
+
+// sidecarWorkload is a pod that does nothing but have the collector injected, it has the collector's labels so the
+// pod watcher and the port forwards find it like any collector pod
+func (c CollectorConfig) sidecarWorkload() *unstructured.Unstructured {
+	image := c.sidecarImage
+	if image == "" {
+		image = DefaultSidecarImage
+	}
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata": map[string]interface{}{
+				"name":        sidecarWorkloadName,
+				"labels":      podLabels,
+				"annotations": map[string]interface{}{sidecarInjectAnnotation: collectorName},
+			},
 			"spec": map[string]interface{}{
-				"replicas": 1,
-				"mode":     "deployment",
-				"config":   config,
-				"env": []map[string]interface{}{
-					{
-						"name":  "LS_TOKEN",
-						"value": c.token,
-					},
-					{
-						"name":  "DESTINATION",
-						"value": c.endpoint,
-					},
+				"containers": []interface{}{
+					map[string]interface{}{"name": "workload", "image": image},
 				},
 			},
 		},
 	}
-	return steps.WithOtelColConfig(col), steps.NewSuccessfulResult("retrieved CRD config")
+}
+
+// configureExporter points the embedded config's otlp exporter at the endpoint with the configured headers, TLS and
+// compression, it returns the env vars the headers are read from
+func (c CollectorConfig) configureExporter(config map[string]interface{}) ([]map[string]interface{}, error) {
+	exporters, _ := config["exporters"].(map[string]interface{})
+	otlp, ok := exporters["otlp"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("collector config has no otlp exporter")
+	}
+	headers, headerEnv := c.exporterHeaders()
+	if len(headers) > 0 {
+		otlp["headers"] = headers
+	}
+	if tls := c.exporterTLS(); len(tls) > 0 {
+		otlp["tls"] = tls
+	}
+	if c.compression != steps.CompressionDefault {
+		otlp["compression"] = string(c.compression)
+	}
+	return headerEnv, nil
+}
+
+// collectorEnv returns the env vars given with --collector-env and --collector-env-secret, secrets are referenced
+// rather than copied into the CR
+func (c CollectorConfig) collectorEnv() []map[string]interface{} {
+	env := make([]map[string]interface{}, 0, len(c.env))
+	for _, e := range c.env {
+		if !e.FromSecret() {
+			env = append(env, map[string]interface{}{"name": e.Name, "value": e.Value})
+			continue
+		}
+		env = append(env, map[string]interface{}{
+			"name": e.Name,
+			"valueFrom": map[string]interface{}{
+				"secretKeyRef": map[string]interface{}{"name": e.SecretName, "key": e.SecretKey},
+			},
+		})
+	}
+	return env
+}
+
+// exporterHeaders returns the otlp exporter's headers and the env vars they're read from, so that values such as tokens
+// aren't written in the collector config itself
+func (c CollectorConfig) exporterHeaders() (map[string]interface{}, []map[string]interface{}) {
+	keys := make([]string, 0, len(c.headers))
+	for k := range c.headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	headers := map[string]interface{}{}
+	var env []map[string]interface{}
+	for i, k := range keys {
+		name := fmt.Sprintf("OTLP_HEADER_%d", i)
+		headers[k] = fmt.Sprintf("${%s}", name)
+		env = append(env, map[string]interface{}{
+			"name":  name,
+			"value": c.headers[k],
+		})
+	}
+	return headers, env
+}
+
+// exporterTLS returns the otlp exporter's tls settings, pointing at the files mounted from the TLS secret
+func (c CollectorConfig) exporterTLS() map[string]interface{} {
+	settings := map[string]interface{}{}
+	files := tlsFiles(c.tls)
+	if _, ok := files[tlsCAKey]; ok {
+		settings["ca_file"] = tlsMountedFile(tlsCAKey)
+	}
+	if _, ok := files[tlsCertKey]; ok {
+		settings["cert_file"] = tlsMountedFile(tlsCertKey)
+		settings["key_file"] = tlsMountedFile(tlsKeyKey)
+	}
+	if c.tls.ServerName != "" {
+		settings["server_name_override"] = c.tls.ServerName
+	}
+	return settings
+}
+
+// addLoopbackExporter sends a copy of every pipeline's telemetry to the loopback receiver, over the same protocol as
+// the exporters
+func (c CollectorConfig) addLoopbackExporter(config map[string]interface{}, endpoint string) error {
+	exporters, ok := config["exporters"].(map[string]interface{})
+	if !ok {
+		exporters = map[string]interface{}{}
+		config["exporters"] = exporters
+	}
+	name := "otlp/loopback"
+	exporter := map[string]interface{}{
+		"endpoint": endpoint,
+		"tls":      map[string]interface{}{"insecure": true},
+	}
+	if c.http {
+		name = "otlphttp/loopback"
+		exporter = map[string]interface{}{"endpoint": "http://" + endpoint}
+	}
+	exporters[name] = exporter
+	service, _ := config["service"].(map[string]interface{})
+	pipelines, ok := service["pipelines"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("collector config has no pipelines")
+	}
+	for _, p := range pipelines {
+		pipeline, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		names, _ := pipeline["exporters"].([]interface{})
+		pipeline["exporters"] = append(names, name)
+	}
+	return nil
 }
 
 func (c CollectorConfig) Dependencies(config *steps.Config) []steps.Dependency {
@@ -0,0 +1,335 @@
+package dependencies
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/lightstep/collector-cluster-check/pkg/colconfig"
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+func TestCollectorConfig_Run(t *testing.T) {
+	tests := []struct {
+		name        string
+		headers     map[string]string
+		wantHeaders map[string]interface{}
+		wantEnv     []map[string]interface{}
+	}{
+		{
+			name:    "no headers",
+			wantEnv: []map[string]interface{}{{"name": "DESTINATION", "value": "api.honeycomb.io:443"}},
+		},
+		{
+			name:    "headers are read from env vars",
+			headers: map[string]string{"x-honeycomb-team": "secret", "x-honeycomb-dataset": "checks"},
+			wantHeaders: map[string]interface{}{
+				"x-honeycomb-dataset": "${OTLP_HEADER_0}",
+				"x-honeycomb-team":    "${OTLP_HEADER_1}",
+			},
+			wantEnv: []map[string]interface{}{
+				{"name": "DESTINATION", "value": "api.honeycomb.io:443"},
+				{"name": "OTLP_HEADER_0", "value": "checks"},
+				{"name": "OTLP_HEADER_1", "value": "secret"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := steps.NewDependencies()
+			option, result := NewCollectorConfig(tt.headers, "api.honeycomb.io:443").Run(context.Background(), deps)
+			require.Equal(t, steps.StatusPass, result.Status())
+			option(deps)
+
+			col := deps.OtelColConfig.Object
+			headers, found, err := unstructured.NestedFieldNoCopy(col, "spec", "config", "exporters", "otlp", "headers")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantHeaders != nil, found)
+			if tt.wantHeaders != nil {
+				assert.Equal(t, tt.wantHeaders, headers)
+			}
+			env, _, err := unstructured.NestedFieldNoCopy(col, "spec", "env")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantEnv, env)
+		})
+	}
+}
+
+func TestCollectorConfig_RunTLS(t *testing.T) {
+	tests := []struct {
+		name        string
+		tls         steps.ExporterTLS
+		compression steps.Compression
+		wantTLS     map[string]interface{}
+		wantVolumes bool
+	}{
+		{
+			name: "defaults",
+		},
+		{
+			name:        "server name and compression",
+			tls:         steps.ExporterTLS{ServerName: "gateway.internal"},
+			compression: steps.CompressionNone,
+			wantTLS:     map[string]interface{}{"server_name_override": "gateway.internal"},
+		},
+		{
+			name: "mounted certificates",
+			tls:  steps.ExporterTLS{CAFile: "ca.pem", CertFile: "client.pem", KeyFile: "client-key.pem"},
+			wantTLS: map[string]interface{}{
+				"ca_file":   "/etc/collector-cluster-check/tls/ca.crt",
+				"cert_file": "/etc/collector-cluster-check/tls/tls.crt",
+				"key_file":  "/etc/collector-cluster-check/tls/tls.key",
+			},
+			wantVolumes: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := steps.NewDependencies()
+			c := CollectorConfig{endpoint: "gateway.internal:4317", tls: tt.tls, compression: tt.compression}
+			option, result := c.Run(context.Background(), deps)
+			require.Equal(t, steps.StatusPass, result.Status())
+			option(deps)
+
+			col := deps.OtelColConfig.Object
+			tls, found, err := unstructured.NestedFieldNoCopy(col, "spec", "config", "exporters", "otlp", "tls")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTLS != nil, found)
+			if tt.wantTLS != nil {
+				assert.Equal(t, tt.wantTLS, tls)
+			}
+			compression, found, err := unstructured.NestedFieldNoCopy(col, "spec", "config", "exporters", "otlp", "compression")
+			require.NoError(t, err)
+			assert.Equal(t, tt.compression != steps.CompressionDefault, found)
+			if found {
+				assert.Equal(t, string(tt.compression), compression)
+			}
+			_, found, err = unstructured.NestedFieldNoCopy(col, "spec", "volumeMounts")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantVolumes, found)
+		})
+	}
+}
+
+func TestCollectorConfig_RunLoopback(t *testing.T) {
+	tests := []struct {
+		name         string
+		http         bool
+		wantName     string
+		wantExporter map[string]interface{}
+	}{
+		{
+			name:     "grpc",
+			wantName: "otlp/loopback",
+			wantExporter: map[string]interface{}{
+				"endpoint": "collector-cluster-check-loopback.default.svc:4317",
+				"tls":      map[string]interface{}{"insecure": true},
+			},
+		},
+		{
+			name:         "http",
+			http:         true,
+			wantName:     "otlphttp/loopback",
+			wantExporter: map[string]interface{}{"endpoint": "http://collector-cluster-check-loopback.default.svc:4317"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := steps.NewDependencies()
+			deps.LoopbackEndpoint = "collector-cluster-check-loopback.default.svc:4317"
+			c := NewCollectorConfigFromConfig(&steps.Config{Endpoint: "api.honeycomb.io:443", Http: tt.http})
+			option, result := c.Run(context.Background(), deps)
+			require.Equal(t, steps.StatusPass, result.Status())
+			option(deps)
+
+			col := deps.OtelColConfig.Object
+			exporter, _, err := unstructured.NestedFieldNoCopy(col, "spec", "config", "exporters", tt.wantName)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantExporter, exporter)
+			for _, pipeline := range []string{"traces", "metrics", "logs"} {
+				exporters, _, err := unstructured.NestedFieldNoCopy(col, "spec", "config", "service", "pipelines", pipeline, "exporters")
+				require.NoError(t, err)
+				assert.Contains(t, exporters, tt.wantName, pipeline)
+				assert.Contains(t, exporters, "otlp", pipeline)
+			}
+		})
+	}
+}
+
+func TestCollectorConfig_RunCustom(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        string
+		wantStatus    steps.Status
+		wantMessage   string
+		wantReceivers map[string][]interface{}
+		wantLevel     interface{}
+	}{
+		{
+			name: "own receiver",
+			config: `
+receivers:
+  otlp:
+    protocols:
+      grpc:
+        endpoint: 0.0.0.0:4317
+  prometheus: {}
+exporters:
+  otlphttp:
+    endpoint: https://otlp.example.com
+    headers:
+      authorization: ${env:LS_TOKEN}
+service:
+  telemetry:
+    metrics:
+      level: none
+  pipelines:
+    traces:
+      receivers: [otlp]
+      exporters: [otlphttp]
+    metrics/scraped:
+      receivers: [prometheus]
+      exporters: [otlphttp]
+`,
+			wantStatus:  steps.StatusPass,
+			wantMessage: "added otlp receiver and telemetry metrics",
+			wantReceivers: map[string][]interface{}{
+				"traces":          {"otlp"},
+				"metrics/scraped": {"prometheus", "otlp"},
+			},
+			wantLevel: "normal",
+		},
+		{
+			name: "no otlp receiver",
+			config: `
+receivers:
+  otlp:
+    protocols:
+      http: {}
+exporters:
+  debug: {}
+service:
+  pipelines:
+    logs:
+      receivers: [otlp]
+      exporters: [debug]
+`,
+			wantStatus:  steps.StatusPass,
+			wantMessage: "added otlp receiver",
+			wantReceivers: map[string][]interface{}{
+				"logs": {"otlp", injectedReceiver},
+			},
+		},
+		{
+			name:        "no pipelines",
+			config:      "receivers: {}\n",
+			wantStatus:  steps.StatusFail,
+			wantMessage: "add service.pipelines to the collector config",
+		},
+		{
+			name:        "not yaml",
+			config:      "receivers: [",
+			wantStatus:  steps.StatusFail,
+			wantMessage: "the collector config isn't valid YAML",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "collector.yaml")
+			require.NoError(t, os.WriteFile(file, []byte(tt.config), 0o600))
+			env := []steps.CollectorEnv{{Name: "LS_TOKEN", SecretName: "lightstep", SecretKey: "token"}}
+			c := NewCollectorConfigFromConfig(&steps.Config{
+				Endpoint:            "ingest.lightstep.com:443",
+				Headers:             map[string]string{"lightstep-access-token": "secret"},
+				CollectorConfigFile: file,
+				CollectorEnv:        env,
+			})
+			deps := steps.NewDependencies()
+			option, result := c.Run(context.Background(), deps)
+			require.Equal(t, tt.wantStatus, result.Status())
+			assert.Contains(t, result.Message(), tt.wantMessage)
+			if tt.wantStatus != steps.StatusPass {
+				return
+			}
+			option(deps)
+
+			col := deps.OtelColConfig.Object
+			config, _, err := unstructured.NestedFieldNoCopy(col, "spec", "config")
+			require.NoError(t, err)
+			for _, f := range colconfig.Lint(config.(map[string]interface{}), nil) {
+				assert.NotEqual(t, colconfig.RulePortCollision, f.Rule, f.Message)
+			}
+			for pipeline, want := range tt.wantReceivers {
+				receivers, _, err := unstructured.NestedFieldNoCopy(col, "spec", "config", "service", "pipelines", pipeline, "receivers")
+				require.NoError(t, err)
+				assert.Equal(t, want, receivers, pipeline)
+			}
+			level, _, err := unstructured.NestedFieldNoCopy(col, "spec", "config", "service", "telemetry", "metrics", "level")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantLevel, level)
+			_, found, err := unstructured.NestedFieldNoCopy(col, "spec", "config", "exporters", "otlphttp", "headers", "lightstep-access-token")
+			require.NoError(t, err)
+			assert.False(t, found, "the config's exporters are used as they are")
+			gotEnv, _, err := unstructured.NestedFieldNoCopy(col, "spec", "env")
+			require.NoError(t, err)
+			assert.Equal(t, []map[string]interface{}{
+				{"name": "DESTINATION", "value": "ingest.lightstep.com:443"},
+				{"name": "LS_TOKEN", "valueFrom": map[string]interface{}{"secretKeyRef": map[string]interface{}{"name": "lightstep", "key": "token"}}},
+			}, gotEnv)
+		})
+	}
+}
+
+func TestCollectorConfig_RunMissingFile(t *testing.T) {
+	c := NewCollectorConfigFromConfig(&steps.Config{CollectorConfigFile: filepath.Join(t.TempDir(), "missing.yaml")})
+	_, result := c.Run(context.Background(), steps.NewDependencies())
+	assert.Equal(t, steps.StatusFail, result.Status())
+	assert.Equal(t, "check --collector-config", result.Message())
+}
+
+func TestCollectorConfig_RunModes(t *testing.T) {
+	tests := []struct {
+		mode         steps.CollectorMode
+		wantMode     string
+		wantReplicas bool
+		wantWorkload bool
+	}{
+		{mode: "", wantMode: "deployment", wantReplicas: true},
+		{mode: steps.CollectorModeDeployment, wantMode: "deployment", wantReplicas: true},
+		{mode: steps.CollectorModeStatefulSet, wantMode: "statefulset", wantReplicas: true},
+		{mode: steps.CollectorModeDaemonSet, wantMode: "daemonset"},
+		{mode: steps.CollectorModeSidecar, wantMode: "sidecar", wantWorkload: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.wantMode, func(t *testing.T) {
+			deps := steps.NewDependencies()
+			c := NewCollectorConfigFromConfig(&steps.Config{Endpoint: "ingest.lightstep.com:443", CollectorMode: tt.mode, SidecarImage: "mirror.internal/pause:3.9"})
+			option, result := c.Run(context.Background(), deps)
+			require.Equal(t, steps.StatusPass, result.Status())
+			assert.Equal(t, tt.wantMode, result.Attributes()["mode"])
+			option(deps)
+
+			mode, _, err := unstructured.NestedString(deps.OtelColConfig.Object, "spec", "mode")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantMode, mode)
+			_, found, err := unstructured.NestedFieldNoCopy(deps.OtelColConfig.Object, "spec", "replicas")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantReplicas, found)
+			if !tt.wantWorkload {
+				assert.Nil(t, deps.CollectorWorkload)
+				return
+			}
+			require.NotNil(t, deps.CollectorWorkload)
+			assert.Equal(t, "test-col", deps.CollectorWorkload.GetAnnotations()["sidecar.opentelemetry.io/inject"])
+			assert.Equal(t, "collector-cluster-checker", deps.CollectorWorkload.GetLabels()["app.kubernetes.io/created-by"])
+			containers, _, err := unstructured.NestedSlice(deps.CollectorWorkload.Object, "spec", "containers")
+			require.NoError(t, err)
+			assert.Equal(t, "mirror.internal/pause:3.9", containers[0].(map[string]interface{})["image"])
+		})
+	}
+}
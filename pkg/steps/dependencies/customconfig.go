@@ -0,0 +1,162 @@
+package dependencies
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+const (
+	// injectedReceiver receives the checks' telemetry when a user supplied config has no OTLP gRPC receiver on 4317
+	injectedReceiver = "otlp/collector-cluster-check"
+	// telemetryAddress is where QueryCollector reads the collector's own metrics
+	telemetryAddress = "0.0.0.0:8888"
+	telemetryPort    = 8888
+)
+
+// signalPipelines are the pipeline types the checks send telemetry to
+var signalPipelines = map[string]bool{"traces": true, "metrics": true, "logs": true}
+
+// injectOTLPReceiver makes sure every traces, metrics and logs pipeline receives from an OTLP receiver that serves
+// gRPC on 4317, where the checks send their telemetry through the port forward. It returns whether the config changed.
+func injectOTLPReceiver(config map[string]interface{}) bool {
+	receivers, ok := config["receivers"].(map[string]interface{})
+	if !ok {
+		receivers = map[string]interface{}{}
+		config["receivers"] = receivers
+	}
+	name := otlpGRPCReceiver(receivers)
+	changed := false
+	if name == "" {
+		name = injectedReceiver
+		receivers[name] = map[string]interface{}{
+			// only gRPC, the checks don't send over HTTP and the config's own receivers may already serve it on 4318
+			"protocols": map[string]interface{}{
+				"grpc": map[string]interface{}{"endpoint": "0.0.0.0:4317"},
+			},
+		}
+		changed = true
+	}
+	for id, p := range pipelines(config) {
+		pipelineType, _, _ := strings.Cut(id, "/")
+		if !signalPipelines[pipelineType] {
+			continue
+		}
+		names, _ := p["receivers"].([]interface{})
+		if containsName(names, name) {
+			continue
+		}
+		p["receivers"] = append(names, name)
+		changed = true
+	}
+	return changed
+}
+
+// otlpGRPCReceiver returns the first OTLP receiver, in name order, that serves gRPC on the default port
+func otlpGRPCReceiver(receivers map[string]interface{}) string {
+	names := make([]string, 0, len(receivers))
+	for name := range receivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if name != "otlp" && !strings.HasPrefix(name, "otlp/") {
+			continue
+		}
+		receiver, _ := receivers[name].(map[string]interface{})
+		protocols, _ := receiver["protocols"].(map[string]interface{})
+		grpc, ok := protocols["grpc"]
+		if !ok {
+			continue
+		}
+		settings, _ := grpc.(map[string]interface{})
+		endpoint, _ := settings["endpoint"].(string)
+		if endpoint == "" || port(endpoint) == "4317" {
+			return name
+		}
+	}
+	return ""
+}
+
+// injectTelemetry makes sure the collector serves its own metrics, which QueryCollector reads, on 8888. It returns
+// whether the config changed.
+func injectTelemetry(config map[string]interface{}) bool {
+	service, ok := config["service"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	telemetry, ok := service["telemetry"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	metrics, ok := telemetry["metrics"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	changed := false
+	if metrics["level"] == "none" {
+		metrics["level"] = "normal"
+		changed = true
+	}
+	if address, ok := metrics["address"].(string); ok && port(address) != fmt.Sprint(telemetryPort) {
+		metrics["address"] = telemetryAddress
+		changed = true
+	}
+	if readers, ok := metrics["readers"].([]interface{}); ok && !servesTelemetry(readers) {
+		metrics["readers"] = append(readers, map[string]interface{}{
+			"pull": map[string]interface{}{
+				"exporter": map[string]interface{}{
+					"prometheus": map[string]interface{}{"host": "0.0.0.0", "port": telemetryPort},
+				},
+			},
+		})
+		changed = true
+	}
+	return changed
+}
+
+// servesTelemetry is whether a pull reader exposes the metrics in the prometheus format on 8888
+func servesTelemetry(readers []interface{}) bool {
+	for _, r := range readers {
+		reader, _ := r.(map[string]interface{})
+		pull, _ := reader["pull"].(map[string]interface{})
+		exporter, _ := pull["exporter"].(map[string]interface{})
+		prometheus, _ := exporter["prometheus"].(map[string]interface{})
+		if fmt.Sprint(prometheus["port"]) == fmt.Sprint(telemetryPort) {
+			return true
+		}
+	}
+	return false
+}
+
+// pipelines returns the config's pipelines by ID
+func pipelines(config map[string]interface{}) map[string]map[string]interface{} {
+	service, _ := config["service"].(map[string]interface{})
+	all, _ := service["pipelines"].(map[string]interface{})
+	found := map[string]map[string]interface{}{}
+	for id, p := range all {
+		if pipeline, ok := p.(map[string]interface{}); ok {
+			found[id] = pipeline
+		}
+	}
+	return found
+}
+
+func containsName(names []interface{}, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// port returns the port of an endpoint written as host:port, or an empty string
+func port(endpoint string) string {
+	_, p, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return ""
+	}
+	return p
+}
@@ -0,0 +1,62 @@
+package dependencies
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+type ParseDestinations struct {
+	endpoint string
+	extra    []string
+}
+
+func NewParseDestinationsFromConfig(config *steps.Config) ParseDestinations {
+	return ParseDestinations{endpoint: config.Endpoint, extra: config.Destinations}
+}
+
+func NewParseDestinations(endpoint string, extra ...string) *ParseDestinations {
+	return &ParseDestinations{endpoint: endpoint, extra: extra}
+}
+
+var _ steps.Dependency = ParseDestinations{}
+
+func (c ParseDestinations) Name() string {
+	return "ParseDestinations"
+}
+
+func (c ParseDestinations) Description() string {
+	return "Parses the endpoint and extra destinations into the hosts and ports to probe"
+}
+
+func (c ParseDestinations) Run(ctx context.Context, deps *steps.Deps) (steps.Option, steps.Result) {
+	var destinations []steps.Destination
+	seen := map[steps.Destination]bool{}
+	for _, endpoint := range append([]string{c.endpoint}, c.extra...) {
+		d, err := steps.ParseDestination(endpoint)
+		if err != nil {
+			return steps.Empty, steps.NewFailureResultWithHelp(err, "check --endpoint and --destination")
+		}
+		if seen[d] {
+			continue
+		}
+		seen[d] = true
+		destinations = append(destinations, d)
+	}
+	addresses := make([]string, len(destinations))
+	for i, d := range destinations {
+		addresses[i] = d.Address()
+	}
+	return steps.WithDestinations(destinations), steps.NewSuccessfulResult(fmt.Sprintf("probing %s", strings.Join(addresses, ", "))).
+		WithAttribute("destinations", addresses)
+}
+
+func (c ParseDestinations) Dependencies(config *steps.Config) []steps.Dependency {
+	return nil
+}
+
+func (c ParseDestinations) Shutdown(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,56 @@
+package dependencies
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+func TestParseDestinations_Run(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		extra    []string
+		want     []steps.Destination
+		wantErr  error
+	}{
+		{
+			name:     "endpoint only",
+			endpoint: "ingest.lightstep.com:443",
+			want:     []steps.Destination{{Host: "ingest.lightstep.com", Port: "443"}},
+		},
+		{
+			name:     "extra destinations are deduplicated",
+			endpoint: "ingest.lightstep.com:443",
+			extra:    []string{"https://ingest.lightstep.com", "[::1]:4317"},
+			want: []steps.Destination{
+				{Host: "ingest.lightstep.com", Port: "443"},
+				{Host: "::1", Port: "4317"},
+			},
+		},
+		{
+			name:     "invalid destination",
+			endpoint: "ingest.lightstep.com:443",
+			extra:    []string{"example.com:otlp"},
+			wantErr:  fmt.Errorf(`invalid destination "example.com:otlp": invalid port "otlp"`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := steps.NewDependencies()
+			option, result := NewParseDestinations(tt.endpoint, tt.extra...).Run(context.Background(), deps)
+			option(deps)
+			if tt.wantErr != nil {
+				assert.Equal(t, steps.StatusFail, result.Status())
+				assert.EqualError(t, result.Err(), tt.wantErr.Error())
+				return
+			}
+			assert.Equal(t, steps.StatusPass, result.Status())
+			assert.Equal(t, tt.want, deps.Destinations)
+		})
+	}
+}
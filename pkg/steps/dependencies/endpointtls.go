@@ -0,0 +1,50 @@
+package dependencies
+
+import (
+	"context"
+
+	"github.com/lightstep/collector-cluster-check/pkg/otlp"
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+type LoadEndpointTLS struct {
+	export steps.ExportOptions
+	http   bool
+}
+
+func NewLoadEndpointTLSFromConfig(config *steps.Config) LoadEndpointTLS {
+	return LoadEndpointTLS{export: steps.ExportOptionsFromConfig(config), http: config.Http}
+}
+
+var _ steps.Dependency = LoadEndpointTLS{}
+
+func (c LoadEndpointTLS) Name() string {
+	return "LoadEndpointTLS"
+}
+
+func (c LoadEndpointTLS) Description() string {
+	return "Loads the CA, client certificate and server name the exporters verify the endpoint with, and their transport"
+}
+
+func (c LoadEndpointTLS) Run(ctx context.Context, deps *steps.Deps) (steps.Option, steps.Result) {
+	conf, err := c.export.TLSConfig()
+	if err != nil {
+		return steps.Empty, steps.NewFailureResultWithHelp(err, "check --ca-file, --cert-file and --key-file")
+	}
+	transport := otlp.TransportGRPC
+	if c.http {
+		transport = otlp.TransportHTTPProtobuf
+	}
+	if conf == nil {
+		return steps.WithEndpointTLS(nil, transport), steps.NewSuccessfulResult("the endpoint is verified with the system roots")
+	}
+	return steps.WithEndpointTLS(conf, transport), steps.NewSuccessfulResult("loaded the endpoint's TLS settings")
+}
+
+func (c LoadEndpointTLS) Dependencies(config *steps.Config) []steps.Dependency {
+	return nil
+}
+
+func (c LoadEndpointTLS) Shutdown(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,87 @@
+package dependencies
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/lightstep/collector-cluster-check/pkg/otlp"
+)
+
+// exportErrorHandler receives the errors the SDK reports in the background, e.g. when the batch span processor or the
+// periodic reader fails to export. Errors that a provider's exporter returned are already in its collector, anything
+// else is logged like the default handler does.
+type exportErrorHandler struct {
+	mu         sync.Mutex
+	collectors map[*otlp.ErrorCollector]struct{}
+}
+
+var (
+	errorHandler     = &exportErrorHandler{collectors: map[*otlp.ErrorCollector]struct{}{}}
+	errorHandlerOnce sync.Once
+)
+
+// registerExportErrors installs the error handler and keeps the collector's errors from being logged until unregister
+// is called
+func registerExportErrors(c *otlp.ErrorCollector) (unregister func()) {
+	errorHandlerOnce.Do(func() { otel.SetErrorHandler(errorHandler) })
+	errorHandler.mu.Lock()
+	defer errorHandler.mu.Unlock()
+	errorHandler.collectors[c] = struct{}{}
+	return func() {
+		errorHandler.mu.Lock()
+		defer errorHandler.mu.Unlock()
+		delete(errorHandler.collectors, c)
+	}
+}
+
+func (h *exportErrorHandler) Handle(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.collectors {
+		if c.Owns(err) {
+			return
+		}
+	}
+	log.Print(err)
+}
+
+// errorRecordingSpanExporter records every failed export in the provider's collector
+type errorRecordingSpanExporter struct {
+	sdktrace.SpanExporter
+	errs *otlp.ErrorCollector
+}
+
+func (e errorRecordingSpanExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	err := e.SpanExporter.ExportSpans(ctx, spans)
+	e.errs.Record(err)
+	return err
+}
+
+type errorRecordingMetricExporter struct {
+	sdkmetric.Exporter
+	errs *otlp.ErrorCollector
+}
+
+func (e errorRecordingMetricExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	err := e.Exporter.Export(ctx, rm)
+	e.errs.Record(err)
+	return err
+}
+
+type errorRecordingLogExporter struct {
+	sdklog.Exporter
+	errs *otlp.ErrorCollector
+}
+
+func (e errorRecordingLogExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	err := e.Exporter.Export(ctx, records)
+	e.errs.Record(err)
+	return err
+}
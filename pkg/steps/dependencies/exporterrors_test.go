@@ -0,0 +1,55 @@
+package dependencies
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/lightstep/collector-cluster-check/pkg/otlp"
+)
+
+// failingSpanExporter rejects every export
+type failingSpanExporter struct {
+	tracetest.InMemoryExporter
+	err error
+}
+
+func (e *failingSpanExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	return e.err
+}
+
+func TestExportErrorHandler(t *testing.T) {
+	var logged bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&logged)
+
+	errs := otlp.NewErrorCollector()
+	unregister := registerExportErrors(errs)
+	rejected := errors.New("traces export: rejected")
+	exp := errorRecordingSpanExporter{SpanExporter: &failingSpanExporter{err: rejected}, errs: errs}
+
+	// the batch processor reports the failed background export to the error handler, not to the caller
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exp))
+	_, span := tp.Tracer("test").Start(context.Background(), "span")
+	span.End()
+	require.ErrorIs(t, tp.ForceFlush(context.Background()), rejected)
+	require.NoError(t, tp.Shutdown(context.Background()))
+
+	problems := errs.Problems()
+	require.Len(t, problems, 1)
+	assert.Equal(t, rejected, problems[0].Err)
+	otel.Handle(rejected)
+	assert.Empty(t, logged.String(), "errors of a registered collector aren't logged")
+
+	unregister()
+	otel.Handle(rejected)
+	assert.Contains(t, logged.String(), "traces export: rejected")
+}
@@ -0,0 +1,81 @@
+package dependencies
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lightstep/collector-cluster-check/pkg/load"
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+type CreateLoadGenerator struct {
+	export      steps.ExportOptions
+	http        bool
+	temporality steps.Temporality
+	opts        load.Options
+	runID       string
+
+	g *load.Generator
+}
+
+func CreateLoadGeneratorFromConfig(config *steps.Config) *CreateLoadGenerator {
+	return &CreateLoadGenerator{export: steps.ExportOptionsFromConfig(config), http: config.Http, temporality: config.Temporality, opts: config.Load, runID: config.RunID}
+}
+
+func NewCreateLoadGenerator(endpoint string, insecure bool, http bool, headers map[string]string, temporality steps.Temporality, opts load.Options, runID string) *CreateLoadGenerator {
+	return &CreateLoadGenerator{export: steps.ExportOptions{Endpoint: endpoint, Insecure: insecure, Headers: headers}, http: http, temporality: temporality, opts: opts, runID: runID}
+}
+
+var _ steps.Dependency = &CreateLoadGenerator{}
+
+func (c *CreateLoadGenerator) Name() string {
+	return fmt.Sprintf("Create Load Generator @ %s", c.export.Endpoint)
+}
+
+func (c *CreateLoadGenerator) Description() string {
+	return "Creates exporters for every signal that the load is generated through"
+}
+
+func (c *CreateLoadGenerator) Run(ctx context.Context, deps *steps.Deps) (steps.Option, steps.Result) {
+	if err := c.opts.Validate(); err != nil {
+		return steps.Empty, steps.NewFailureResultWithHelp(err, "check the --load flags")
+	}
+	// the exporters are the same as the ones the providers use, so the load goes through the same settings
+	var exp load.Exporters
+	var err error
+	if c.opts.SpansPerSecond > 0 {
+		tp := &CreateTraceProvider{export: c.export, http: c.http}
+		if exp.Spans, err = tp.newTraceExporter(ctx); err != nil {
+			return steps.Empty, steps.NewFailureResult(err)
+		}
+	}
+	if c.opts.MetricPointsPerSecond > 0 {
+		mp := &CreateMeterProvider{export: c.export, http: c.http, temporality: c.temporality}
+		if exp.Metrics, err = mp.newMetricExporter(ctx); err != nil {
+			return steps.Empty, steps.NewFailureResult(err)
+		}
+	}
+	if c.opts.LogRecordsPerSecond > 0 {
+		lp := &CreateLoggerProvider{export: c.export, http: c.http}
+		if exp.Logs, err = lp.newLogExporter(ctx); err != nil {
+			return steps.Empty, steps.NewFailureResult(err)
+		}
+	}
+	res, err := newResource(c.runID)
+	if err != nil {
+		return steps.Empty, steps.NewFailureResult(err)
+	}
+	c.g = load.NewGenerator(c.opts, res, exp)
+	return steps.WithLoadGenerator(c.g), steps.NewSuccessfulResult("initialized load generator")
+}
+
+func (c *CreateLoadGenerator) Dependencies(config *steps.Config) []steps.Dependency {
+	return nil
+}
+
+func (c *CreateLoadGenerator) Shutdown(ctx context.Context) error {
+	if c.g == nil {
+		return nil
+	}
+	return c.g.Shutdown(ctx)
+}
@@ -0,0 +1,134 @@
+package dependencies
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/lightstep/collector-cluster-check/pkg/otlp"
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+type CreateLoggerProvider struct {
+	export steps.ExportOptions
+	http   bool
+	runID  string
+
+	lp *sdklog.LoggerProvider
+	// errs are the provider's failed exports, unregister stops keeping them from the error handler once it's shut down
+	errs       *otlp.ErrorCollector
+	unregister func()
+}
+
+func CreateLoggerProviderFromConfig(config *steps.Config) *CreateLoggerProvider {
+	return &CreateLoggerProvider{export: steps.ExportOptionsFromConfig(config), http: config.Http, runID: config.RunID}
+}
+
+func NewCreateLoggerProvider(endpoint string, insecure bool, http bool, headers map[string]string, runID string) *CreateLoggerProvider {
+	return &CreateLoggerProvider{export: steps.ExportOptions{Endpoint: endpoint, Insecure: insecure, Headers: headers}, http: http, runID: runID}
+}
+
+var _ steps.Dependency = &CreateLoggerProvider{}
+
+func (c *CreateLoggerProvider) Name() string {
+	return fmt.Sprintf("Create Logger Provider @ %s", c.export.Endpoint)
+}
+
+func (c *CreateLoggerProvider) Description() string {
+	return "Creates a logger provider"
+}
+
+func (c *CreateLoggerProvider) Run(ctx context.Context, deps *steps.Deps) (steps.Option, steps.Result) {
+	exp, err := c.newLogExporter(ctx)
+	if err != nil {
+		return steps.Empty, steps.NewFailureResult(err)
+	}
+	c.errs = otlp.NewErrorCollector()
+	c.unregister = registerExportErrors(c.errs)
+	lp, err := c.newLoggerProvider(errorRecordingLogExporter{Exporter: exp, errs: c.errs})
+	if err != nil {
+		return steps.Empty, steps.NewFailureResult(err)
+	}
+	c.lp = lp
+	return steps.WithLoggerProvider(lp, c.errs), steps.NewSuccessfulResult("initialized logger provider")
+}
+
+func (c *CreateLoggerProvider) Dependencies(config *steps.Config) []steps.Dependency {
+	return nil
+}
+
+func (c *CreateLoggerProvider) Shutdown(ctx context.Context) error {
+	if c.unregister != nil {
+		defer c.unregister()
+	}
+	if c.lp == nil {
+		return nil
+	}
+	return c.lp.Shutdown(ctx)
+}
+
+var (
+	logHTTPOptions = steps.ExporterOptionFuncs[otlploghttp.Option]{
+		Headers:  otlploghttp.WithHeaders,
+		Endpoint: otlploghttp.WithEndpoint,
+		Insecure: otlploghttp.WithInsecure,
+		TLS:      otlploghttp.WithTLSClientConfig,
+		Gzip: func() otlploghttp.Option {
+			return otlploghttp.WithCompression(otlploghttp.GzipCompression)
+		},
+		Proxy: func(proxy func(*http.Request) (*url.URL, error)) otlploghttp.Option {
+			return otlploghttp.WithProxy(proxy)
+		},
+	}
+	logGRPCOptions = steps.ExporterOptionFuncs[otlploggrpc.Option]{
+		Headers:  otlploggrpc.WithHeaders,
+		Endpoint: otlploggrpc.WithEndpoint,
+		Insecure: otlploggrpc.WithInsecure,
+		TLS: func(conf *tls.Config) otlploggrpc.Option {
+			return otlploggrpc.WithTLSCredentials(credentials.NewTLS(conf))
+		},
+		Gzip: func() otlploggrpc.Option {
+			return otlploggrpc.WithCompressor("gzip")
+		},
+		Dialer: func(dialer func(context.Context, string) (net.Conn, error)) otlploggrpc.Option {
+			return otlploggrpc.WithDialOption(grpc.WithContextDialer(dialer))
+		},
+	}
+)
+
+func (c *CreateLoggerProvider) newLogExporter(ctx context.Context) (sdklog.Exporter, error) {
+	if c.http {
+		opts, err := steps.ExporterOptions(c.export, logHTTPOptions)
+		if err != nil {
+			return nil, err
+		}
+		return otlploghttp.New(ctx, opts...)
+	}
+	opts, err := steps.ExporterOptions(c.export, logGRPCOptions)
+	if err != nil {
+		return nil, err
+	}
+	return otlploggrpc.New(ctx, opts...)
+}
+
+func (c *CreateLoggerProvider) newLoggerProvider(exp sdklog.Exporter) (*sdklog.LoggerProvider, error) {
+	res, rErr := newResource(c.runID)
+
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	return sdklog.NewLoggerProvider(
+		sdklog.WithProcessor(sdklog.NewBatchProcessor(exp)),
+		sdklog.WithResource(res),
+	), nil
+}
@@ -0,0 +1,248 @@
+package dependencies
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/lightstep/collector-cluster-check/pkg/loopback"
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+const (
+	DefaultLoopbackImage = "alpine/socat:1.8.0.0"
+	// loopbackName is the relay's service, the test collector exports to it
+	loopbackName = "collector-cluster-check-loopback"
+	// loopbackPort is where the relay accepts the test collector's exports, loopbackTunnelPort where the tunnel
+	// connects to pick them up through a port forward from a free local port
+	loopbackPort       = 4317
+	loopbackTunnelPort = 9000
+	loopbackRunLabel   = "collector-cluster-check/run-id"
+	// loopbackRetry is how often the tunnel reconnects while the relay isn't ready
+	loopbackRetry          = 200 * time.Millisecond
+	loopbackPollInterval   = time.Second
+	loopbackDeleteTimeout  = 10 * time.Second
+	loopbackServerShutdown = 5 * time.Second
+)
+
+// CreateLoopback starts a receiver on this machine and a relay in the cluster that the test collector exports to, the
+// relay's exports come back to the receiver through a tunnel over a port forward
+type CreateLoopback struct {
+	http      bool
+	image     string
+	runID     string
+	namespace string
+
+	client     kubernetes.Interface
+	pod        string
+	grpcServer *grpc.Server
+	httpServer *http.Server
+	forwarded  *steps.PortForwardedResource
+	stopTunnel context.CancelFunc
+	tunnelDone chan struct{}
+}
+
+func NewCreateLoopbackFromConfig(config *steps.Config) *CreateLoopback {
+	return NewCreateLoopback(config.Http, config.LoopbackImage, config.RunID, config.ProbeNamespace)
+}
+
+func NewCreateLoopback(http bool, image string, runID string, namespace string) *CreateLoopback {
+	if namespace == "" {
+		namespace = apiv1.NamespaceDefault
+	}
+	return &CreateLoopback{http: http, image: image, runID: runID, namespace: namespace}
+}
+
+var _ steps.Dependency = &CreateLoopback{}
+var _ steps.PolicyProvider = &CreateLoopback{}
+
+func (c *CreateLoopback) Name() string {
+	return "CreateLoopback"
+}
+
+func (c *CreateLoopback) Description() string {
+	return "Starts a local OTLP receiver and a relay in the cluster that the test collector exports to"
+}
+
+func (c *CreateLoopback) Policy() steps.Policy {
+	return steps.Policy{Timeout: 2 * time.Minute}
+}
+
+func (c *CreateLoopback) Run(ctx context.Context, deps *steps.Deps) (steps.Option, steps.Result) {
+	if deps.KubeClient == nil {
+		return steps.Empty, steps.NewFailureResultWithHelp(nil, "kube client not set")
+	}
+	receiver := loopback.NewReceiver()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return steps.Empty, steps.NewFailureResultWithHelp(err, "failed to start the loopback receiver")
+	}
+	if c.http {
+		c.httpServer = &http.Server{Handler: receiver.Handler()}
+		go c.httpServer.Serve(lis)
+	} else {
+		c.grpcServer = receiver.GRPCServer()
+		go c.grpcServer.Serve(lis)
+	}
+
+	c.client = deps.KubeClient
+	pods := deps.KubeClient.CoreV1().Pods(c.namespace)
+	pod, err := pods.Create(ctx, c.relayPod(), metav1.CreateOptions{})
+	if err != nil {
+		return steps.Empty, steps.NewFailureResultWithHelp(err, fmt.Sprintf("failed to create the loopback relay in %s", c.namespace))
+	}
+	c.pod = pod.Name
+	if err := c.applyService(ctx); err != nil {
+		return steps.Empty, steps.NewFailureResult(err)
+	}
+	for pod.Status.Phase != apiv1.PodRunning {
+		if pod.Status.Phase == apiv1.PodSucceeded || pod.Status.Phase == apiv1.PodFailed {
+			return steps.Empty, steps.NewFailureResultWithHelp(nil, fmt.Sprintf("loopback relay %s/%s exited, check that %s runs socat", c.namespace, pod.Name, c.image))
+		}
+		select {
+		case <-ctx.Done():
+			return steps.Empty, steps.NewFailureResultWithHelp(ctx.Err(), fmt.Sprintf("loopback relay %s/%s isn't running", c.namespace, pod.Name))
+		case <-time.After(loopbackPollInterval):
+		}
+		pod, err = pods.Get(ctx, pod.Name, metav1.GetOptions{})
+		if err != nil {
+			return steps.Empty, steps.NewFailureResult(err)
+		}
+	}
+
+	c.forwarded, err = portForwardedResource(deps, c.namespace, pod.Name, 0, loopbackTunnelPort)
+	if err != nil {
+		return steps.Empty, steps.NewFailureResultWithHelp(err, "failed to forward the loopback relay's tunnel port")
+	}
+	// the tunnel outlives this dependency's run, it's stopped on shutdown
+	tunnelCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	c.stopTunnel = cancel
+	c.tunnelDone = make(chan struct{})
+	tunnel := loopback.Tunnel{
+		Relay:         fmt.Sprintf("localhost:%d", c.forwarded.LocalPort),
+		Target:        lis.Addr().String(),
+		RetryInterval: loopbackRetry,
+	}
+	go func() {
+		defer close(c.tunnelDone)
+		_ = tunnel.Run(tunnelCtx)
+	}()
+
+	endpoint := fmt.Sprintf("%s.%s.svc:%d", loopbackName, c.namespace, loopbackPort)
+	return steps.WithLoopback(receiver, loopback.NewSent(), endpoint, c.runID), steps.NewSuccessfulResult(fmt.Sprintf("receiving at %s through %s/%s", lis.Addr(), c.namespace, pod.Name))
+}
+
+// relayPod accepts a connection from the tunnel, then waits for the collector to connect and pipes the two together,
+// every tunnel connection gets its own process
+func (c *CreateLoopback) relayPod() *apiv1.Pod {
+	return &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: loopbackName + "-",
+			Namespace:    c.namespace,
+			Labels:       c.labels(),
+		},
+		Spec: apiv1.PodSpec{
+			RestartPolicy: apiv1.RestartPolicyNever,
+			Containers: []apiv1.Container{
+				{
+					Name:  "relay",
+					Image: c.image,
+					Args: []string{
+						fmt.Sprintf("TCP-LISTEN:%d,reuseaddr,fork", loopbackTunnelPort),
+						fmt.Sprintf("TCP-LISTEN:%d,reuseaddr", loopbackPort),
+					},
+					Ports: []apiv1.ContainerPort{
+						{Name: "otlp", ContainerPort: loopbackPort},
+						{Name: "tunnel", ContainerPort: loopbackTunnelPort},
+					},
+				},
+			},
+		},
+	}
+}
+
+// labels select this run's relay, so a relay left over from a killed run never gets the collector's exports
+func (c *CreateLoopback) labels() map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":    loopbackName,
+		"app.kubernetes.io/part-of": "collector-cluster-checker",
+		loopbackRunLabel:            c.runID,
+	}
+}
+
+// applyService creates the relay's service, or points the one left over from a killed run at this run's relay
+func (c *CreateLoopback) applyService(ctx context.Context) error {
+	services := c.client.CoreV1().Services(c.namespace)
+	svc := &apiv1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      loopbackName,
+			Namespace: c.namespace,
+			Labels:    map[string]string{"app.kubernetes.io/created-by": "collector-cluster-checker"},
+		},
+		Spec: apiv1.ServiceSpec{
+			Selector: c.labels(),
+			Ports: []apiv1.ServicePort{
+				{Name: "otlp", Port: loopbackPort, TargetPort: intstr.FromInt32(loopbackPort)},
+			},
+		},
+	}
+	_, err := services.Create(ctx, svc, metav1.CreateOptions{})
+	if !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	existing, err := services.Get(ctx, loopbackName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	existing.Spec.Selector = svc.Spec.Selector
+	existing.Spec.Ports = svc.Spec.Ports
+	_, err = services.Update(ctx, existing, metav1.UpdateOptions{})
+	return err
+}
+
+func (c *CreateLoopback) Dependencies(config *steps.Config) []steps.Dependency {
+	return []steps.Dependency{NewCreateKubeClientFromConfig(config)}
+}
+
+func (c *CreateLoopback) Shutdown(ctx context.Context) error {
+	if c.stopTunnel != nil {
+		c.stopTunnel()
+		<-c.tunnelDone
+	}
+	if c.forwarded != nil {
+		c.forwarded.Close()
+	}
+	if c.grpcServer != nil {
+		c.grpcServer.Stop()
+	}
+	if c.httpServer != nil {
+		serverCtx, cancel := context.WithTimeout(ctx, loopbackServerShutdown)
+		_ = c.httpServer.Shutdown(serverCtx)
+		cancel()
+	}
+	if c.client == nil {
+		return nil
+	}
+	deleteCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), loopbackDeleteTimeout)
+	defer cancel()
+	var errs []error
+	if err := c.client.CoreV1().Services(c.namespace).Delete(deleteCtx, loopbackName, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		errs = append(errs, err)
+	}
+	if c.pod != "" {
+		if err := c.client.CoreV1().Pods(c.namespace).Delete(deleteCtx, c.pod, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
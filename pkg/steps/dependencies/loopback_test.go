@@ -0,0 +1,73 @@
+package dependencies
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+func TestCreateLoopback_Run(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []runtime.Object
+	}{
+		{
+			name: "new service",
+		},
+		{
+			name: "service left over from another run",
+			existing: []runtime.Object{&apiv1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: loopbackName, Namespace: "monitoring"},
+				Spec:       apiv1.ServiceSpec{Selector: map[string]string{loopbackRunLabel: "old"}},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(tt.existing...)
+			client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				pod := action.(k8stesting.CreateAction).GetObject().(*apiv1.Pod)
+				pod.Name = pod.GenerateName + "test"
+				return false, nil, nil
+			})
+			deps := &steps.Deps{KubeClient: client}
+			c := NewCreateLoopback(false, DefaultLoopbackImage, "a1b2", "monitoring")
+
+			// the fake relay never runs, so the dependency gives up when the context ends
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			_, result := c.Run(ctx, deps)
+			assert.Equal(t, steps.StatusFail, result.Status())
+
+			pods, err := client.CoreV1().Pods("monitoring").List(context.Background(), metav1.ListOptions{})
+			require.NoError(t, err)
+			require.Len(t, pods.Items, 1)
+			relay := pods.Items[0].Spec.Containers[0]
+			assert.Equal(t, DefaultLoopbackImage, relay.Image)
+			assert.Equal(t, []string{"TCP-LISTEN:9000,reuseaddr,fork", "TCP-LISTEN:4317,reuseaddr"}, relay.Args)
+
+			svc, err := client.CoreV1().Services("monitoring").Get(context.Background(), loopbackName, metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, "a1b2", svc.Spec.Selector[loopbackRunLabel])
+			assert.Equal(t, pods.Items[0].Labels, svc.Spec.Selector)
+
+			require.NoError(t, c.Shutdown(context.Background()))
+			pods, err = client.CoreV1().Pods("monitoring").List(context.Background(), metav1.ListOptions{})
+			require.NoError(t, err)
+			assert.Empty(t, pods.Items)
+			services, err := client.CoreV1().Services("monitoring").List(context.Background(), metav1.ListOptions{})
+			require.NoError(t, err)
+			assert.Empty(t, services.Items)
+		})
+	}
+}
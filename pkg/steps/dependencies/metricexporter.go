@@ -0,0 +1,60 @@
+package dependencies
+
+import (
+	"context"
+	"fmt"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+// CreateMetricExporter creates an exporter with the same settings as the meter provider's, for steps that collect
+// and export metrics themselves
+type CreateMetricExporter struct {
+	provider *CreateMeterProvider
+
+	exp sdkmetric.Exporter
+}
+
+func CreateMetricExporterFromConfig(config *steps.Config) *CreateMetricExporter {
+	return &CreateMetricExporter{provider: CreateMeterProviderFromConfig(config)}
+}
+
+func NewCreateMetricExporter(endpoint string, insecure bool, http bool, headers map[string]string, runID string) *CreateMetricExporter {
+	return &CreateMetricExporter{provider: NewCreateMeterProvider(endpoint, insecure, http, headers, runID)}
+}
+
+var _ steps.Dependency = &CreateMetricExporter{}
+
+func (c *CreateMetricExporter) Name() string {
+	return fmt.Sprintf("Create Metric Exporter @ %s", c.provider.export.Endpoint)
+}
+
+func (c *CreateMetricExporter) Description() string {
+	return "Creates a metric exporter"
+}
+
+func (c *CreateMetricExporter) Run(ctx context.Context, deps *steps.Deps) (steps.Option, steps.Result) {
+	exp, err := c.provider.newMetricExporter(ctx)
+	if err != nil {
+		return steps.Empty, steps.NewFailureResult(err)
+	}
+	res, err := newResource(c.provider.runID)
+	if err != nil {
+		return steps.Empty, steps.NewFailureResult(err)
+	}
+	c.exp = exp
+	return steps.WithMetricExporter(exp, res), steps.NewSuccessfulResult("initialized metric exporter")
+}
+
+func (c *CreateMetricExporter) Dependencies(config *steps.Config) []steps.Dependency {
+	return nil
+}
+
+func (c *CreateMetricExporter) Shutdown(ctx context.Context) error {
+	if c.exp == nil {
+		return nil
+	}
+	return c.exp.Shutdown(ctx)
+}
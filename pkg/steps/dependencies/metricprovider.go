@@ -2,104 +2,133 @@ package dependencies
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
 	"time"
 
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
-	"go.opentelemetry.io/otel/sdk/resource"
-	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 
+	"github.com/lightstep/collector-cluster-check/pkg/otlp"
 	"github.com/lightstep/collector-cluster-check/pkg/steps"
 )
 
 type CreateMeterProvider struct {
-	endpoint string
-	insecure bool
-	http     bool
-	token    string
+	export      steps.ExportOptions
+	http        bool
+	temporality steps.Temporality
+	runID       string
+
+	mp *sdkmetric.MeterProvider
+	// errs are the provider's failed exports, unregister stops keeping them from the error handler once it's shut down
+	errs       *otlp.ErrorCollector
+	unregister func()
 }
 
-func CreateMeterProviderFromConfig(config *steps.Config) CreateMeterProvider {
-	return CreateMeterProvider{endpoint: config.Endpoint, insecure: config.Insecure, http: config.Http, token: config.Token}
+func CreateMeterProviderFromConfig(config *steps.Config) *CreateMeterProvider {
+	return &CreateMeterProvider{export: steps.ExportOptionsFromConfig(config), http: config.Http, temporality: config.Temporality, runID: config.RunID}
 }
 
-func NewCreateMeterProvider(endpoint string, insecure bool, http bool, token string) CreateMeterProvider {
-	return CreateMeterProvider{endpoint: endpoint, insecure: insecure, http: http, token: token}
+func NewCreateMeterProvider(endpoint string, insecure bool, http bool, headers map[string]string, runID string) *CreateMeterProvider {
+	return &CreateMeterProvider{export: steps.ExportOptions{Endpoint: endpoint, Insecure: insecure, Headers: headers}, http: http, runID: runID}
 }
 
-var _ steps.Dependency = CreateMeterProvider{}
+var _ steps.Dependency = &CreateMeterProvider{}
 
-func (c CreateMeterProvider) Name() string {
-	return fmt.Sprintf("Create Meter Provider @ %s", c.endpoint)
+func (c *CreateMeterProvider) Name() string {
+	return fmt.Sprintf("Create Meter Provider @ %s", c.export.Endpoint)
 }
 
-func (c CreateMeterProvider) Description() string {
+func (c *CreateMeterProvider) Description() string {
 	return "Creates a meter provider"
 }
 
-func (c CreateMeterProvider) Run(ctx context.Context, deps *steps.Deps) (steps.Option, steps.Result) {
+func (c *CreateMeterProvider) Run(ctx context.Context, deps *steps.Deps) (steps.Option, steps.Result) {
 	exp, err := c.newMetricExporter(ctx)
 	if err != nil {
 		return steps.Empty, steps.NewFailureResult(err)
 	}
-	mp, err := c.newMetricProvider(exp)
+	c.errs = otlp.NewErrorCollector()
+	c.unregister = registerExportErrors(c.errs)
+	mp, err := c.newMetricProvider(errorRecordingMetricExporter{Exporter: exp, errs: c.errs})
 	if err != nil {
 		return steps.Empty, steps.NewFailureResult(err)
 	}
-	return steps.WithMeterProvider(mp), steps.NewSuccessfulResult("initialized meter provider")
+	c.mp = mp
+	return steps.WithMeterProvider(mp, c.errs), steps.NewSuccessfulResult("initialized meter provider")
 }
 
-func (c CreateMeterProvider) Dependencies(config *steps.Config) []steps.Dependency {
+func (c *CreateMeterProvider) Dependencies(config *steps.Config) []steps.Dependency {
 	return nil
 }
 
-func (c CreateMeterProvider) Shutdown(ctx context.Context) error {
+func (c *CreateMeterProvider) Shutdown(ctx context.Context) error {
+	if c.unregister != nil {
+		defer c.unregister()
+	}
+	if c.mp == nil {
+		return nil
+	}
+	// The provider is usually already shut down by the ShutdownMeter step
+	if err := c.mp.Shutdown(ctx); err != nil && !errors.Is(err, sdkmetric.ErrReaderShutdown) {
+		return err
+	}
 	return nil
 }
 
-func (c CreateMeterProvider) newMetricExporter(ctx context.Context) (sdkmetric.Exporter, error) {
-	var headers = map[string]string{
-		"lightstep-access-token": c.token,
+var (
+	metricHTTPOptions = steps.ExporterOptionFuncs[otlpmetrichttp.Option]{
+		Headers:  otlpmetrichttp.WithHeaders,
+		Endpoint: otlpmetrichttp.WithEndpoint,
+		Insecure: otlpmetrichttp.WithInsecure,
+		TLS:      otlpmetrichttp.WithTLSClientConfig,
+		Gzip: func() otlpmetrichttp.Option {
+			return otlpmetrichttp.WithCompression(otlpmetrichttp.GzipCompression)
+		},
+		Proxy: func(proxy func(*http.Request) (*url.URL, error)) otlpmetrichttp.Option {
+			return otlpmetrichttp.WithProxy(proxy)
+		},
 	}
+	metricGRPCOptions = steps.ExporterOptionFuncs[otlpmetricgrpc.Option]{
+		Headers:  otlpmetricgrpc.WithHeaders,
+		Endpoint: otlpmetricgrpc.WithEndpoint,
+		Insecure: otlpmetricgrpc.WithInsecure,
+		TLS: func(conf *tls.Config) otlpmetricgrpc.Option {
+			return otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(conf))
+		},
+		Gzip: func() otlpmetricgrpc.Option {
+			return otlpmetricgrpc.WithCompressor("gzip")
+		},
+		Dialer: func(dialer func(context.Context, string) (net.Conn, error)) otlpmetricgrpc.Option {
+			return otlpmetricgrpc.WithDialOption(grpc.WithContextDialer(dialer))
+		},
+	}
+)
+
+func (c *CreateMeterProvider) newMetricExporter(ctx context.Context) (sdkmetric.Exporter, error) {
 	if c.http {
-		opts := []otlpmetrichttp.Option{
-			otlpmetrichttp.WithHeaders(headers),
-			otlpmetrichttp.WithEndpoint(c.endpoint),
+		opts, err := steps.ExporterOptions(c.export, metricHTTPOptions)
+		if err != nil {
+			return nil, err
 		}
-		if c.insecure {
-			opts = append(opts, otlpmetrichttp.WithInsecure())
-		}
-		return otlpmetrichttp.New(
-			ctx,
-			opts...,
-		)
-	} else {
-		opts := []otlpmetricgrpc.Option{
-			otlpmetricgrpc.WithHeaders(headers),
-			otlpmetricgrpc.WithEndpoint(c.endpoint),
-		}
-		if c.insecure {
-			opts = append(opts, otlpmetricgrpc.WithInsecure())
-		}
-		return otlpmetricgrpc.New(
-			ctx,
-			opts...,
-		)
+		return otlpmetrichttp.New(ctx, append(opts, otlpmetrichttp.WithTemporalitySelector(c.temporality.Selector()))...)
 	}
+	opts, err := steps.ExporterOptions(c.export, metricGRPCOptions)
+	if err != nil {
+		return nil, err
+	}
+	return otlpmetricgrpc.New(ctx, append(opts, otlpmetricgrpc.WithTemporalitySelector(c.temporality.Selector()))...)
 }
 
-func (c CreateMeterProvider) newMetricProvider(exp sdkmetric.Exporter) (*sdkmetric.MeterProvider, error) {
-	res, rErr :=
-		resource.Merge(
-			resource.Default(),
-			resource.NewWithAttributes(
-				semconv.SchemaURL,
-				semconv.ServiceNameKey.String(steps.ServiceName),
-				semconv.ServiceVersionKey.String(steps.ServiceVersion),
-			),
-		)
+func (c *CreateMeterProvider) newMetricProvider(exp sdkmetric.Exporter) (*sdkmetric.MeterProvider, error) {
+	res, rErr := newResource(c.runID)
 
 	if rErr != nil {
 		return nil, rErr
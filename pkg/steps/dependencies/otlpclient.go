@@ -0,0 +1,75 @@
+package dependencies
+
+import (
+	"context"
+	"fmt"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+
+	"github.com/lightstep/collector-cluster-check/pkg/loopback"
+	"github.com/lightstep/collector-cluster-check/pkg/otlp"
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+type CreateOTLPClient struct {
+	export steps.ExportOptions
+	http   bool
+	runID  string
+}
+
+func NewCreateOTLPClientFromConfig(config *steps.Config) CreateOTLPClient {
+	return CreateOTLPClient{export: steps.ExportOptionsFromConfig(config), http: config.Http, runID: config.RunID}
+}
+
+func NewCreateOTLPClient(endpoint string, insecure bool, http bool, headers map[string]string, proxy string) *CreateOTLPClient {
+	return &CreateOTLPClient{export: steps.ExportOptions{Endpoint: endpoint, Insecure: insecure, Headers: headers, Proxy: proxy}, http: http}
+}
+
+var _ steps.Dependency = CreateOTLPClient{}
+
+func (c CreateOTLPClient) Name() string {
+	return fmt.Sprintf("Create OTLP Client @ %s", c.export.Endpoint)
+}
+
+func (c CreateOTLPClient) Description() string {
+	return "Creates a client that sends export requests without the SDK"
+}
+
+func (c CreateOTLPClient) Run(ctx context.Context, deps *steps.Deps) (steps.Option, steps.Result) {
+	d, err := steps.ParseDestination(c.export.Endpoint)
+	if err != nil {
+		return steps.Empty, steps.NewFailureResultWithHelp(err, "check --endpoint")
+	}
+	resource := map[string]string{
+		string(semconv.ServiceNameKey):    steps.ServiceName,
+		string(semconv.ServiceVersionKey): steps.ServiceVersion,
+	}
+	if c.runID != "" {
+		resource[loopback.RunIDAttribute] = c.runID
+	}
+	opts := []otlp.Option{otlp.WithProxy(steps.ProxyFromEnvironment(c.export.Proxy)), otlp.WithResource(resource)}
+	if c.export.Proxy != "" {
+		opts = append(opts, otlp.WithDialer(steps.ProxyDialer(c.export.Proxy, !c.export.Insecure)))
+	}
+	conf, err := c.export.TLSConfig()
+	if err != nil {
+		return steps.Empty, steps.NewFailureResultWithHelp(err, "check --ca-file, --cert-file and --key-file")
+	}
+	if conf != nil {
+		opts = append(opts, otlp.WithTLSConfig(conf))
+	}
+	transport := otlp.TransportGRPC
+	if c.http {
+		transport = otlp.TransportHTTPProtobuf
+	}
+	client := otlp.NewClient(d.Address(), c.export.Insecure, c.export.Headers, opts...)
+	return steps.WithOTLPClient(client, transport), steps.NewSuccessfulResult("initialized OTLP client")
+}
+
+func (c CreateOTLPClient) Dependencies(config *steps.Config) []steps.Dependency {
+	return nil
+}
+
+func (c CreateOTLPClient) Shutdown(ctx context.Context) error {
+	return nil
+}
@@ -6,9 +6,11 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"sync"
 
 	apiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/tools/portforward"
 	"k8s.io/client-go/transport/spdy"
 
@@ -18,6 +20,8 @@ import (
 type PortForward struct {
 	Port          int
 	LabelSelector string
+
+	forwarded *steps.PortForwardedResource
 }
 
 var _ steps.Dependency = &PortForward{}
@@ -34,7 +38,8 @@ func (p *PortForward) Description() string {
 	return "Initiates a port forward"
 }
 
-func (p *PortForward) portForwardedResource(conf *steps.Deps, resourceName string) (*steps.PortForwardedResource, error) {
+// portForwardedResource forwards the port of a pod to localPort, or to a free local port when it is 0
+func portForwardedResource(conf *steps.Deps, namespace string, resourceName string, localPort int, port int) (*steps.PortForwardedResource, error) {
 	transport, upgrader, err := spdy.RoundTripperFor(conf.KubeConf)
 	if err != nil {
 		return nil, err
@@ -42,7 +47,7 @@ func (p *PortForward) portForwardedResource(conf *steps.Deps, resourceName strin
 	url := conf.KubeClient.CoreV1().RESTClient().
 		Post().
 		Resource("pods").
-		Namespace(apiv1.NamespaceDefault).
+		Namespace(namespace).
 		Name(resourceName).
 		SubResource("portforward").
 		URL()
@@ -55,7 +60,7 @@ func (p *PortForward) portForwardedResource(conf *steps.Deps, resourceName strin
 
 	portForwarder, err := portforward.New(
 		dialer,
-		[]string{fmt.Sprintf("%d:%d", p.Port, p.Port)},
+		[]string{fmt.Sprintf("%d:%d", localPort, port)},
 		stopChan,
 		readyChan,
 		io.Discard, // Info messages are a little spammy and we don't care.
@@ -87,10 +92,12 @@ func (p *PortForward) portForwardedResource(conf *steps.Deps, resourceName strin
 		return nil, err
 	}
 
+	// Close may be called by both the FinishPortForward step and Shutdown
+	var once sync.Once
 	return &steps.PortForwardedResource{
 		Name:      resourceName,
 		LocalPort: int(ports[0].Local),
-		Close:     func() { close(stopChan) },
+		Close:     func() { once.Do(func() { close(stopChan) }) },
 	}, nil
 }
 
@@ -107,14 +114,31 @@ func (p *PortForward) Run(ctx context.Context, deps *steps.Deps) (steps.Option,
 	} else if len(podList.Items) == 0 {
 		return steps.Empty, steps.NewFailureResultWithHelp(nil, "no pods found")
 	}
-	pfp, err := p.portForwardedResource(deps, podList.Items[0].GetName())
+	name := runningPod(podList.Items)
+	pfp, err := portForwardedResource(deps, apiv1.NamespaceDefault, name, p.Port, p.Port)
 	if err != nil {
 		return steps.Empty, steps.NewFailureResult(err)
 	}
-	return steps.WithPortForwardedResource(pfp), steps.NewSuccessfulResult("started port forward")
+	p.forwarded = pfp
+	return steps.WithPortForwardedResource(pfp), steps.NewSuccessfulResult(fmt.Sprintf("started port forward to %s", name))
+}
+
+// runningPod picks the first running pod that isn't being deleted, e.g. one of a daemonset's pods on a node that's
+// ready, and falls back to the first pod
+func runningPod(pods []unstructured.Unstructured) string {
+	for _, pod := range pods {
+		phase, _, _ := unstructured.NestedString(pod.Object, "status", "phase")
+		if phase == string(apiv1.PodRunning) && pod.GetDeletionTimestamp() == nil {
+			return pod.GetName()
+		}
+	}
+	return pods[0].GetName()
 }
 
 func (p *PortForward) Shutdown(ctx context.Context) error {
+	if p.forwarded != nil {
+		p.forwarded.Close()
+	}
 	return nil
 }
 
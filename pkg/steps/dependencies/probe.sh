@@ -0,0 +1,6 @@
+#!/bin/sh
+# Probes every destination given as an argument and prints one JSON object per destination. curl reports how long the
+# DNS lookup, TCP connect and TLS handshake took, and how the first one to fail failed.
+for destination in "$@"; do
+  curl --silent --output /dev/null --connect-timeout 5 --max-time 10 --write-out '%{json}\n' "https://${destination}/" || true
+done
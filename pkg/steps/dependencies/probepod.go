@@ -0,0 +1,205 @@
+package dependencies
+
+import (
+	"bufio"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+const (
+	DefaultProbeImage = "curlimages/curl:8.8.0"
+	probePollInterval = time.Second
+	// probeDeleteTimeout bounds deleting the probe pod once it is done, even when the check was cancelled
+	probeDeleteTimeout = 10 * time.Second
+	// probeUser runs the probe, images whose user is a name rather than a UID can't be verified as non-root otherwise
+	probeUser = 65534
+)
+
+var (
+	//go:embed probe.sh
+	probeScript string
+	// probeLabels are distinct from the collector's labels so that the probe is never mistaken for the collector
+	probeLabels = map[string]string{
+		"app.kubernetes.io/name":    "collector-cluster-check-probe",
+		"app.kubernetes.io/part-of": "collector-cluster-checker",
+	}
+)
+
+type RunProbePod struct {
+	image     string
+	namespace string
+}
+
+func NewRunProbePodFromConfig(config *steps.Config) RunProbePod {
+	return RunProbePod{image: config.ProbeImage, namespace: config.ProbeNamespace}
+}
+
+func NewRunProbePod(image string, namespace string) *RunProbePod {
+	return &RunProbePod{image: image, namespace: namespace}
+}
+
+var _ steps.Dependency = RunProbePod{}
+var _ steps.PolicyProvider = RunProbePod{}
+
+func (c RunProbePod) Name() string {
+	return "RunProbePod"
+}
+
+func (c RunProbePod) Description() string {
+	return "Probes every destination from a short-lived pod inside the cluster"
+}
+
+func (c RunProbePod) Policy() steps.Policy {
+	return steps.Policy{Timeout: 2 * time.Minute}
+}
+
+func (c RunProbePod) Run(ctx context.Context, deps *steps.Deps) (steps.Option, steps.Result) {
+	if deps.KubeClient == nil {
+		return steps.Empty, steps.NewFailureResultWithHelp(nil, "kube client not set")
+	}
+	if len(deps.Destinations) == 0 {
+		return steps.Empty, steps.NewFailureResultWithHelp(nil, "destinations not set")
+	}
+	pods := deps.KubeClient.CoreV1().Pods(c.namespace)
+	pod, err := pods.Create(ctx, c.pod(deps.Destinations), metav1.CreateOptions{})
+	if err != nil {
+		return steps.Empty, steps.NewFailureResultWithHelp(err, fmt.Sprintf("failed to create probe pod in %s", c.namespace))
+	}
+	// the pod is only needed until its logs are read, it is deleted even when the check was cancelled
+	defer func() {
+		deleteCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), probeDeleteTimeout)
+		defer cancel()
+		_ = pods.Delete(deleteCtx, pod.Name, metav1.DeleteOptions{})
+	}()
+
+	for pod.Status.Phase == apiv1.PodPending || pod.Status.Phase == "" {
+		select {
+		case <-ctx.Done():
+			return steps.Empty, steps.NewFailureResultWithHelp(ctx.Err(), fmt.Sprintf("probe pod %s/%s didn't start", c.namespace, pod.Name))
+		case <-time.After(probePollInterval):
+		}
+		pod, err = pods.Get(ctx, pod.Name, metav1.GetOptions{})
+		if err != nil {
+			return steps.Empty, steps.NewFailureResult(err)
+		}
+	}
+	// following the logs reports every destination as soon as it's probed, so a probe that hangs still reports the
+	// destinations before it
+	logs, err := pods.GetLogs(pod.Name, &apiv1.PodLogOptions{Follow: true}).Stream(ctx)
+	if err != nil {
+		return steps.Empty, steps.NewFailureResultWithHelp(err, "failed to read the probe pod's logs")
+	}
+	defer logs.Close()
+	probes, err := parseProbeOutput(logs, deps.Destinations)
+	if err != nil && ctx.Err() != nil && len(probes) > 0 {
+		return steps.WithClusterProbes(probes), steps.NewAcceptableFailureResultWithHelp(err, fmt.Sprintf("probe pod %s/%s didn't finish, only %d of %d destinations were probed", c.namespace, pod.Name, len(probes), len(deps.Destinations)))
+	}
+	if err != nil {
+		return steps.Empty, steps.NewFailureResultWithHelp(err, fmt.Sprintf("probe pod %s/%s didn't report every destination", c.namespace, pod.Name))
+	}
+	return steps.WithClusterProbes(probes), steps.NewSuccessfulResult(fmt.Sprintf("probed %d destinations from %s/%s", len(probes), c.namespace, pod.Name))
+}
+
+func (c RunProbePod) pod(destinations []steps.Destination) *apiv1.Pod {
+	args := []string{"-c", probeScript, "probe"}
+	for _, d := range destinations {
+		args = append(args, d.Address())
+	}
+	return &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "collector-cluster-check-probe-",
+			Namespace:    c.namespace,
+			Labels:       probeLabels,
+		},
+		Spec: apiv1.PodSpec{
+			RestartPolicy: apiv1.RestartPolicyNever,
+			// the restricted Pod Security Standard, so the probe can run in any namespace
+			SecurityContext: &apiv1.PodSecurityContext{
+				RunAsNonRoot:   ptr.To(true),
+				RunAsUser:      ptr.To[int64](probeUser),
+				SeccompProfile: &apiv1.SeccompProfile{Type: apiv1.SeccompProfileTypeRuntimeDefault},
+			},
+			Containers: []apiv1.Container{
+				{
+					Name:    "probe",
+					Image:   c.image,
+					Command: []string{"sh"},
+					Args:    args,
+					SecurityContext: &apiv1.SecurityContext{
+						AllowPrivilegeEscalation: ptr.To(false),
+						Capabilities:             &apiv1.Capabilities{Drop: []apiv1.Capability{"ALL"}},
+					},
+				},
+			},
+		},
+	}
+}
+
+// curlOutput is the subset of curl's --write-out '%{json}' that the probe needs, times are in seconds
+type curlOutput struct {
+	ExitCode       int     `json:"exitcode"`
+	ErrorMessage   string  `json:"errormsg"`
+	RemoteIP       string  `json:"remote_ip"`
+	TimeLookup     float64 `json:"time_namelookup"`
+	TimeConnect    float64 `json:"time_connect"`
+	TimeAppConnect float64 `json:"time_appconnect"`
+}
+
+// parseProbeOutput reads one JSON object per destination, in the order the destinations were given to the probe.
+// Lines that aren't JSON objects are ignored. The destinations read before an error are returned with it.
+func parseProbeOutput(r io.Reader, destinations []steps.Destination) ([]steps.ProbeResult, error) {
+	var probes []steps.ProbeResult
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+		if len(probes) == len(destinations) {
+			return probes, fmt.Errorf("probe reported more than %d destinations", len(destinations))
+		}
+		var out curlOutput
+		if err := json.Unmarshal([]byte(line), &out); err != nil {
+			return probes, fmt.Errorf("invalid probe output %q: %w", line, err)
+		}
+		probes = append(probes, steps.ProbeResult{
+			Destination: destinations[len(probes)],
+			RemoteIP:    out.RemoteIP,
+			Lookup:      seconds(out.TimeLookup),
+			Connect:     seconds(out.TimeConnect),
+			Handshake:   seconds(out.TimeAppConnect),
+			ExitCode:    out.ExitCode,
+			Error:       out.ErrorMessage,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return probes, err
+	}
+	if len(probes) != len(destinations) {
+		return probes, fmt.Errorf("probe reported %d of %d destinations", len(probes), len(destinations))
+	}
+	return probes, nil
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+func (c RunProbePod) Dependencies(config *steps.Config) []steps.Dependency {
+	return []steps.Dependency{NewParseDestinationsFromConfig(config), NewCreateKubeClientFromConfig(config)}
+}
+
+func (c RunProbePod) Shutdown(ctx context.Context) error {
+	return nil
+}
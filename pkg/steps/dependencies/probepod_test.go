@@ -0,0 +1,129 @@
+package dependencies
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+func TestParseProbeOutput(t *testing.T) {
+	destinations := []steps.Destination{
+		{Host: "ingest.lightstep.com", Port: "443"},
+		{Host: "gateway.internal", Port: "4317"},
+	}
+	tests := []struct {
+		name    string
+		output  string
+		want    []steps.ProbeResult
+		wantErr string
+	}{
+		{
+			name: "every destination",
+			output: `{"exitcode":0,"errormsg":null,"remote_ip":"10.0.0.1","time_namelookup":0.002,"time_connect":0.012,"time_appconnect":0.05}
+{"exitcode":6,"errormsg":"Could not resolve host: gateway.internal","remote_ip":"","time_namelookup":0,"time_connect":0,"time_appconnect":0}
+`,
+			want: []steps.ProbeResult{
+				{
+					Destination: destinations[0],
+					RemoteIP:    "10.0.0.1",
+					Lookup:      2 * time.Millisecond,
+					Connect:     12 * time.Millisecond,
+					Handshake:   50 * time.Millisecond,
+				},
+				{
+					Destination: destinations[1],
+					ExitCode:    6,
+					Error:       "Could not resolve host: gateway.internal",
+				},
+			},
+		},
+		{
+			name:    "missing destination",
+			output:  "sh: curl: not found\n",
+			wantErr: "probe reported 0 of 2 destinations",
+		},
+		{
+			name: "partial",
+			output: `{"exitcode":0,"errormsg":null,"remote_ip":"10.0.0.1","time_namelookup":0.002,"time_connect":0.012,"time_appconnect":0.05}
+`,
+			want: []steps.ProbeResult{
+				{
+					Destination: destinations[0],
+					RemoteIP:    "10.0.0.1",
+					Lookup:      2 * time.Millisecond,
+					Connect:     12 * time.Millisecond,
+					Handshake:   50 * time.Millisecond,
+				},
+			},
+			wantErr: "probe reported 1 of 2 destinations",
+		},
+		{
+			name:    "invalid json",
+			output:  "{nope\n",
+			wantErr: `invalid probe output "{nope": invalid character 'n' looking for beginning of object key string`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseProbeOutput(strings.NewReader(tt.output), destinations)
+			assert.Equal(t, tt.want, got)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRunProbePod_Run(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	// the fake client neither generates names nor runs pods, so the pod is named and finished as it is created
+	client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		pod := action.(k8stesting.CreateAction).GetObject().(*apiv1.Pod)
+		pod.Name = pod.GenerateName + "test"
+		pod.Status.Phase = apiv1.PodSucceeded
+		return false, nil, nil
+	})
+	deps := &steps.Deps{
+		KubeClient:   client,
+		Destinations: []steps.Destination{{Host: "ingest.lightstep.com", Port: "443"}},
+	}
+	_, result := NewRunProbePod(DefaultProbeImage, "monitoring").Run(context.Background(), deps)
+	// the fake client's logs aren't curl's output
+	assert.Equal(t, steps.StatusFail, result.Status())
+	assert.EqualError(t, result.Err(), "probe reported 0 of 1 destinations")
+
+	var created *apiv1.Pod
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "create" {
+			created = action.(k8stesting.CreateAction).GetObject().(*apiv1.Pod)
+		}
+	}
+	require.NotNil(t, created)
+	assert.Equal(t, "monitoring", created.Namespace)
+	assert.Equal(t, probeLabels, created.Labels)
+	assert.Equal(t, apiv1.RestartPolicyNever, created.Spec.RestartPolicy)
+	assert.Equal(t, DefaultProbeImage, created.Spec.Containers[0].Image)
+	assert.Equal(t, []string{"-c", probeScript, "probe", "ingest.lightstep.com:443"}, created.Spec.Containers[0].Args)
+	// the restricted Pod Security Standard
+	assert.True(t, *created.Spec.SecurityContext.RunAsNonRoot)
+	assert.Equal(t, apiv1.SeccompProfileTypeRuntimeDefault, created.Spec.SecurityContext.SeccompProfile.Type)
+	assert.False(t, *created.Spec.Containers[0].SecurityContext.AllowPrivilegeEscalation)
+	assert.Equal(t, []apiv1.Capability{"ALL"}, created.Spec.Containers[0].SecurityContext.Capabilities.Drop)
+
+	pods, err := client.CoreV1().Pods("monitoring").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, pods.Items, "the probe pod should be deleted")
+}
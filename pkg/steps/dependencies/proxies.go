@@ -0,0 +1,51 @@
+package dependencies
+
+import (
+	"context"
+
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+type ResolveProxies struct {
+	proxy  string
+	secure bool
+}
+
+func NewResolveProxiesFromConfig(config *steps.Config) ResolveProxies {
+	return ResolveProxies{proxy: config.Proxy, secure: !config.Insecure}
+}
+
+func NewResolveProxies(proxy string, secure bool) *ResolveProxies {
+	return &ResolveProxies{proxy: proxy, secure: secure}
+}
+
+var _ steps.Dependency = ResolveProxies{}
+
+func (c ResolveProxies) Name() string {
+	return "ResolveProxies"
+}
+
+func (c ResolveProxies) Description() string {
+	return "Finds the proxy for every destination from --proxy, HTTPS_PROXY, HTTP_PROXY and NO_PROXY"
+}
+
+func (c ResolveProxies) Run(ctx context.Context, deps *steps.Deps) (steps.Option, steps.Result) {
+	conf := steps.ProxyConfig(c.proxy)
+	proxies := make([]steps.Proxy, len(deps.Destinations))
+	for i, d := range deps.Destinations {
+		p, err := steps.ResolveProxy(conf, c.proxy, d, c.secure)
+		if err != nil {
+			return steps.Empty, steps.NewFailureResultWithHelp(err, "check --proxy and the proxy environment variables")
+		}
+		proxies[i] = p
+	}
+	return steps.WithProxies(proxies), steps.NewSuccessfulResult("resolved proxies")
+}
+
+func (c ResolveProxies) Dependencies(config *steps.Config) []steps.Dependency {
+	return []steps.Dependency{NewParseDestinationsFromConfig(config)}
+}
+
+func (c ResolveProxies) Shutdown(ctx context.Context) error {
+	return nil
+}
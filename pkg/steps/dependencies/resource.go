@@ -0,0 +1,26 @@
+package dependencies
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+
+	"github.com/lightstep/collector-cluster-check/pkg/loopback"
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+// newResource describes this tool in every signal it sends, the run ID tells this run's telemetry apart from earlier
+// runs' when it's set
+func newResource(runID string) (*resource.Resource, error) {
+	attrs := []attribute.KeyValue{
+		semconv.ServiceNameKey.String(steps.ServiceName),
+		semconv.ServiceVersionKey.String(steps.ServiceVersion),
+	}
+	if runID != "" {
+		attrs = append(attrs, attribute.String(loopback.RunIDAttribute, runID))
+	}
+	return resource.Merge(
+		resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL, attrs...),
+	)
+}
@@ -0,0 +1,119 @@
+package dependencies
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+
+	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+const (
+	// tlsSecretName holds the exporter's CA and client certificate, it's mounted into the test collector
+	tlsSecretName = "collector-cluster-check-tls"
+	tlsVolumeName = "exporter-tls"
+	tlsMountPath  = "/etc/collector-cluster-check/tls"
+	tlsCAKey      = "ca.crt"
+	tlsCertKey    = "tls.crt"
+	tlsKeyKey     = "tls.key"
+)
+
+// tlsFiles maps the keys of the TLS secret to the local files they're read from
+func tlsFiles(t steps.ExporterTLS) map[string]string {
+	files := map[string]string{}
+	if t.CAFile != "" {
+		files[tlsCAKey] = t.CAFile
+	}
+	if t.CertFile != "" {
+		files[tlsCertKey] = t.CertFile
+	}
+	if t.KeyFile != "" {
+		files[tlsKeyKey] = t.KeyFile
+	}
+	return files
+}
+
+// tlsMountedFile is where the collector finds a key of the TLS secret
+func tlsMountedFile(key string) string {
+	return path.Join(tlsMountPath, key)
+}
+
+type CreateTLSSecret struct {
+	tls       steps.ExporterTLS
+	namespace string
+
+	// client is kept from Run so that Shutdown can delete the secret it created
+	client kubernetes.Interface
+}
+
+func NewCreateTLSSecretFromConfig(config *steps.Config) *CreateTLSSecret {
+	return &CreateTLSSecret{tls: config.TLS, namespace: apiv1.NamespaceDefault}
+}
+
+func NewCreateTLSSecret(tls steps.ExporterTLS, namespace string) *CreateTLSSecret {
+	return &CreateTLSSecret{tls: tls, namespace: namespace}
+}
+
+var _ steps.Dependency = &CreateTLSSecret{}
+
+func (c *CreateTLSSecret) Name() string {
+	return "CreateTLSSecret"
+}
+
+func (c *CreateTLSSecret) Description() string {
+	return "Creates a secret with the exporter's CA and client certificate for the test collector"
+}
+
+func (c *CreateTLSSecret) Run(ctx context.Context, deps *steps.Deps) (steps.Option, steps.Result) {
+	if deps.KubeClient == nil {
+		return steps.Empty, steps.NewFailureResultWithHelp(nil, "kube client not set")
+	}
+	data := map[string][]byte{}
+	for key, file := range tlsFiles(c.tls) {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return steps.Empty, steps.NewFailureResultWithHelp(err, "check --ca-file, --cert-file and --key-file")
+		}
+		data[key] = b
+	}
+	secret := &apiv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      tlsSecretName,
+			Namespace: c.namespace,
+			Labels:    map[string]string{"app.kubernetes.io/created-by": "collector-cluster-checker"},
+		},
+		Data: data,
+	}
+	secrets := deps.KubeClient.CoreV1().Secrets(c.namespace)
+	_, err := secrets.Create(ctx, secret, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		// left over from a run that was killed before it could clean up
+		_, err = secrets.Update(ctx, secret, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return steps.Empty, steps.NewFailureResult(err)
+	}
+	c.client = deps.KubeClient
+	return steps.Empty, steps.NewSuccessfulResult(fmt.Sprintf("%s has been created", tlsSecretName))
+}
+
+func (c *CreateTLSSecret) Dependencies(config *steps.Config) []steps.Dependency {
+	return []steps.Dependency{NewCreateKubeClientFromConfig(config)}
+}
+
+func (c *CreateTLSSecret) Shutdown(ctx context.Context) error {
+	if c.client == nil {
+		return nil
+	}
+	err := c.client.CoreV1().Secrets(c.namespace).Delete(ctx, tlsSecretName, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,75 @@
+package dependencies
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+func TestCreateTLSSecret(t *testing.T) {
+	dir := t.TempDir()
+	ca := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(ca, []byte("ca"), 0o600))
+
+	tests := []struct {
+		name     string
+		tls      steps.ExporterTLS
+		existing bool
+		want     map[string][]byte
+		wantErr  bool
+	}{
+		{
+			name: "creates the secret",
+			tls:  steps.ExporterTLS{CAFile: ca},
+			want: map[string][]byte{"ca.crt": []byte("ca")},
+		},
+		{
+			name:     "replaces a leftover secret",
+			tls:      steps.ExporterTLS{CAFile: ca},
+			existing: true,
+			want:     map[string][]byte{"ca.crt": []byte("ca")},
+		},
+		{
+			name:    "missing file",
+			tls:     steps.ExporterTLS{CAFile: filepath.Join(dir, "missing.pem")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			if tt.existing {
+				_, err := client.CoreV1().Secrets(apiv1.NamespaceDefault).Create(context.Background(), &apiv1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: tlsSecretName, Namespace: apiv1.NamespaceDefault},
+					Data:       map[string][]byte{"ca.crt": []byte("stale")},
+				}, metav1.CreateOptions{})
+				require.NoError(t, err)
+			}
+			c := NewCreateTLSSecret(tt.tls, apiv1.NamespaceDefault)
+			_, result := c.Run(context.Background(), &steps.Deps{KubeClient: client})
+			if tt.wantErr {
+				assert.Equal(t, steps.StatusFail, result.Status())
+				require.NoError(t, c.Shutdown(context.Background()))
+				return
+			}
+			require.Equal(t, steps.StatusPass, result.Status())
+			secret, err := client.CoreV1().Secrets(apiv1.NamespaceDefault).Get(context.Background(), tlsSecretName, metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, secret.Data)
+
+			require.NoError(t, c.Shutdown(context.Background()))
+			_, err = client.CoreV1().Secrets(apiv1.NamespaceDefault).Get(context.Background(), tlsSecretName, metav1.GetOptions{})
+			assert.True(t, apierrors.IsNotFound(err))
+		})
+	}
+}
@@ -2,104 +2,127 @@ package dependencies
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
 
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
-	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 
+	"github.com/lightstep/collector-cluster-check/pkg/otlp"
 	"github.com/lightstep/collector-cluster-check/pkg/steps"
 )
 
 type CreateTraceProvider struct {
-	endpoint string
-	insecure bool
-	http     bool
-	token    string
+	export steps.ExportOptions
+	http   bool
+	runID  string
+
+	tp *sdktrace.TracerProvider
+	// errs are the provider's failed exports, unregister stops keeping them from the error handler once it's shut down
+	errs       *otlp.ErrorCollector
+	unregister func()
 }
 
-func CreateTracerProviderFromConfig(config *steps.Config) CreateTraceProvider {
-	return CreateTraceProvider{endpoint: config.Endpoint, insecure: config.Insecure, http: config.Http, token: config.Token}
+func CreateTracerProviderFromConfig(config *steps.Config) *CreateTraceProvider {
+	return &CreateTraceProvider{export: steps.ExportOptionsFromConfig(config), http: config.Http, runID: config.RunID}
 }
 
-func NewCreateTraceProvider(endpoint string, insecure bool, http bool, token string) CreateTraceProvider {
-	return CreateTraceProvider{endpoint: endpoint, insecure: insecure, http: http, token: token}
+func NewCreateTraceProvider(endpoint string, insecure bool, http bool, headers map[string]string, runID string) *CreateTraceProvider {
+	return &CreateTraceProvider{export: steps.ExportOptions{Endpoint: endpoint, Insecure: insecure, Headers: headers}, http: http, runID: runID}
 }
 
-var _ steps.Dependency = CreateTraceProvider{}
+var _ steps.Dependency = &CreateTraceProvider{}
 
-func (c CreateTraceProvider) Name() string {
-	return fmt.Sprintf("Create Trace Provider @ %s", c.endpoint)
+func (c *CreateTraceProvider) Name() string {
+	return fmt.Sprintf("Create Trace Provider @ %s", c.export.Endpoint)
 }
 
-func (c CreateTraceProvider) Description() string {
+func (c *CreateTraceProvider) Description() string {
 	return "Creates a Trace provider"
 }
 
-func (c CreateTraceProvider) Run(ctx context.Context, deps *steps.Deps) (steps.Option, steps.Result) {
+func (c *CreateTraceProvider) Run(ctx context.Context, deps *steps.Deps) (steps.Option, steps.Result) {
 	exp, err := c.newTraceExporter(ctx)
 	if err != nil {
 		return steps.Empty, steps.NewFailureResult(err)
 	}
-	tp, err := c.newTraceProvider(exp)
+	c.errs = otlp.NewErrorCollector()
+	c.unregister = registerExportErrors(c.errs)
+	tp, err := c.newTraceProvider(errorRecordingSpanExporter{SpanExporter: exp, errs: c.errs})
 	if err != nil {
 		return steps.Empty, steps.NewFailureResult(err)
 	}
-	return steps.WithTracerProvider(tp), steps.NewSuccessfulResult("initialized trace provider")
+	c.tp = tp
+	return steps.WithTracerProvider(tp, c.errs), steps.NewSuccessfulResult("initialized trace provider")
 }
 
-func (c CreateTraceProvider) Dependencies(config *steps.Config) []steps.Dependency {
+func (c *CreateTraceProvider) Dependencies(config *steps.Config) []steps.Dependency {
 	return nil
 }
 
-func (c CreateTraceProvider) Shutdown(ctx context.Context) error {
-	return nil
+func (c *CreateTraceProvider) Shutdown(ctx context.Context) error {
+	if c.unregister != nil {
+		defer c.unregister()
+	}
+	if c.tp == nil {
+		return nil
+	}
+	return c.tp.Shutdown(ctx)
 }
 
-func (c CreateTraceProvider) newTraceExporter(ctx context.Context) (*otlptrace.Exporter, error) {
-	var headers = map[string]string{
-		"lightstep-access-token": c.token,
+var (
+	traceHTTPOptions = steps.ExporterOptionFuncs[otlptracehttp.Option]{
+		Headers:  otlptracehttp.WithHeaders,
+		Endpoint: otlptracehttp.WithEndpoint,
+		Insecure: otlptracehttp.WithInsecure,
+		TLS:      otlptracehttp.WithTLSClientConfig,
+		Gzip: func() otlptracehttp.Option {
+			return otlptracehttp.WithCompression(otlptracehttp.GzipCompression)
+		},
+		Proxy: func(proxy func(*http.Request) (*url.URL, error)) otlptracehttp.Option {
+			return otlptracehttp.WithProxy(proxy)
+		},
 	}
+	traceGRPCOptions = steps.ExporterOptionFuncs[otlptracegrpc.Option]{
+		Headers:  otlptracegrpc.WithHeaders,
+		Endpoint: otlptracegrpc.WithEndpoint,
+		Insecure: otlptracegrpc.WithInsecure,
+		TLS: func(conf *tls.Config) otlptracegrpc.Option {
+			return otlptracegrpc.WithTLSCredentials(credentials.NewTLS(conf))
+		},
+		Gzip: func() otlptracegrpc.Option {
+			return otlptracegrpc.WithCompressor("gzip")
+		},
+		Dialer: func(dialer func(context.Context, string) (net.Conn, error)) otlptracegrpc.Option {
+			return otlptracegrpc.WithDialOption(grpc.WithContextDialer(dialer))
+		},
+	}
+)
+
+func (c *CreateTraceProvider) newTraceExporter(ctx context.Context) (*otlptrace.Exporter, error) {
 	if c.http {
-		opts := []otlptracehttp.Option{
-			otlptracehttp.WithHeaders(headers),
-			otlptracehttp.WithEndpoint(c.endpoint),
-		}
-		if c.insecure {
-			opts = append(opts, otlptracehttp.WithInsecure())
+		opts, err := steps.ExporterOptions(c.export, traceHTTPOptions)
+		if err != nil {
+			return nil, err
 		}
-		return otlptracehttp.New(
-			ctx,
-			opts...,
-		)
-	}
-	opts := []otlptracegrpc.Option{
-		otlptracegrpc.WithHeaders(headers),
-		otlptracegrpc.WithEndpoint(c.endpoint),
+		return otlptracehttp.New(ctx, opts...)
 	}
-	if c.insecure {
-		opts = append(opts, otlptracegrpc.WithInsecure())
+	opts, err := steps.ExporterOptions(c.export, traceGRPCOptions)
+	if err != nil {
+		return nil, err
 	}
-	return otlptracegrpc.New(
-		ctx,
-		opts...,
-	)
-
+	return otlptracegrpc.New(ctx, opts...)
 }
 
-func (c CreateTraceProvider) newTraceProvider(exp *otlptrace.Exporter) (*sdktrace.TracerProvider, error) {
-	res, rErr :=
-		resource.Merge(
-			resource.Default(),
-			resource.NewWithAttributes(
-				semconv.SchemaURL,
-				semconv.ServiceNameKey.String(steps.ServiceName),
-				semconv.ServiceVersionKey.String(steps.ServiceVersion),
-			),
-		)
+func (c *CreateTraceProvider) newTraceProvider(exp sdktrace.SpanExporter) (*sdktrace.TracerProvider, error) {
+	res, rErr := newResource(c.runID)
 
 	if rErr != nil {
 		return nil, rErr
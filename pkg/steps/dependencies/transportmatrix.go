@@ -0,0 +1,67 @@
+package dependencies
+
+import (
+	"context"
+
+	"github.com/lightstep/collector-cluster-check/pkg/otlp"
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+// CreateTransportMatrix derives a client for every transport and security setting from the OTLP client
+type CreateTransportMatrix struct {
+	transports []otlp.Transport
+	security   []steps.Security
+}
+
+func NewCreateTransportMatrixFromConfig(config *steps.Config) CreateTransportMatrix {
+	return CreateTransportMatrix{transports: config.MatrixTransports, security: config.MatrixSecurity}
+}
+
+func NewCreateTransportMatrix(transports []otlp.Transport, security []steps.Security) CreateTransportMatrix {
+	return CreateTransportMatrix{transports: transports, security: security}
+}
+
+var _ steps.Dependency = CreateTransportMatrix{}
+
+func (c CreateTransportMatrix) Name() string {
+	return "Create Transport Matrix"
+}
+
+func (c CreateTransportMatrix) Description() string {
+	return "Creates a client for every transport and security setting of the matrix check"
+}
+
+func (c CreateTransportMatrix) Run(ctx context.Context, deps *steps.Deps) (steps.Option, steps.Result) {
+	if deps.OTLPClient == nil {
+		return steps.Empty, steps.NewFailureResultWithHelp(nil, "OTLP client not set")
+	}
+	transports, security := c.transports, c.security
+	if len(transports) == 0 {
+		transports = otlp.Transports
+	}
+	if len(security) == 0 {
+		security = []steps.Security{steps.SecurityTLS, steps.SecurityInsecure}
+	}
+	var cells []steps.MatrixCell
+	for _, sec := range security {
+		insecure := sec == steps.SecurityInsecure
+		client := deps.OTLPClient.WithSecurity(insecure)
+		for _, transport := range transports {
+			cells = append(cells, steps.MatrixCell{
+				Transport:  transport,
+				Security:   sec,
+				Client:     client,
+				Configured: transport == deps.OTLPTransport && insecure == deps.OTLPClient.Insecure(),
+			})
+		}
+	}
+	return steps.WithTransportMatrix(cells), steps.NewSuccessfulResult("initialized transport matrix")
+}
+
+func (c CreateTransportMatrix) Dependencies(config *steps.Config) []steps.Dependency {
+	return []steps.Dependency{NewCreateOTLPClientFromConfig(config)}
+}
+
+func (c CreateTransportMatrix) Shutdown(ctx context.Context) error {
+	return nil
+}
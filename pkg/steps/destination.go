@@ -0,0 +1,71 @@
+package steps
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// defaultPort is used for destinations that don't set a port, OTLP endpoints are usually served over TLS
+const defaultPort = "443"
+
+// Destination is a host and port that telemetry is sent to
+type Destination struct {
+	Host string
+	Port string
+}
+
+// Address is the destination in a form that can be dialed, IPv6 hosts are bracketed
+func (d Destination) Address() string {
+	return net.JoinHostPort(d.Host, d.Port)
+}
+
+func (d Destination) String() string {
+	return d.Address()
+}
+
+// ParseDestination accepts the forms an endpoint is usually written in: a URL (https://host:port/path),
+// host:port, a bare host or IP, and bracketed or bare IPv6 literals. A missing port defaults to the scheme's port,
+// or 443 when there is no scheme.
+func ParseDestination(endpoint string) (Destination, error) {
+	endpoint = strings.TrimSpace(endpoint)
+	if endpoint == "" {
+		return Destination{}, fmt.Errorf("empty destination")
+	}
+	var d Destination
+	if strings.Contains(endpoint, "://") {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return Destination{}, fmt.Errorf("invalid destination %q: %w", endpoint, err)
+		}
+		d = Destination{Host: u.Hostname(), Port: u.Port()}
+		if d.Port == "" {
+			d.Port = schemePort(u.Scheme)
+		}
+	} else if host, port, err := net.SplitHostPort(endpoint); err == nil {
+		d = Destination{Host: host, Port: port}
+	} else if addr, err := netip.ParseAddr(strings.TrimSuffix(strings.TrimPrefix(endpoint, "["), "]")); err == nil {
+		d = Destination{Host: addr.String(), Port: defaultPort}
+	} else if !strings.Contains(endpoint, ":") {
+		d = Destination{Host: endpoint, Port: defaultPort}
+	} else {
+		return Destination{}, fmt.Errorf("invalid destination %q: %w", endpoint, err)
+	}
+	if d.Host == "" {
+		return Destination{}, fmt.Errorf("invalid destination %q: missing host", endpoint)
+	}
+	if p, err := strconv.Atoi(d.Port); err != nil || p < 1 || p > 65535 {
+		return Destination{}, fmt.Errorf("invalid destination %q: invalid port %q", endpoint, d.Port)
+	}
+	return d, nil
+}
+
+func schemePort(scheme string) string {
+	if strings.EqualFold(scheme, "http") {
+		return "80"
+	}
+	return defaultPort
+}
@@ -0,0 +1,103 @@
+package steps
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDestination(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		want     Destination
+		wantErr  string
+	}{
+		{
+			name:     "host and port",
+			endpoint: "ingest.lightstep.com:443",
+			want:     Destination{Host: "ingest.lightstep.com", Port: "443"},
+		},
+		{
+			name:     "bare host",
+			endpoint: "ingest.eu.lightstep.com",
+			want:     Destination{Host: "ingest.eu.lightstep.com", Port: "443"},
+		},
+		{
+			name:     "https url",
+			endpoint: "https://otlp.example.com/v1/traces",
+			want:     Destination{Host: "otlp.example.com", Port: "443"},
+		},
+		{
+			name:     "http url",
+			endpoint: "http://gateway.internal",
+			want:     Destination{Host: "gateway.internal", Port: "80"},
+		},
+		{
+			name:     "url with port",
+			endpoint: "http://gateway.internal:4318/",
+			want:     Destination{Host: "gateway.internal", Port: "4318"},
+		},
+		{
+			name:     "ipv4",
+			endpoint: "10.0.0.1:4317",
+			want:     Destination{Host: "10.0.0.1", Port: "4317"},
+		},
+		{
+			name:     "bracketed ipv6 and port",
+			endpoint: "[2001:db8::1]:4317",
+			want:     Destination{Host: "2001:db8::1", Port: "4317"},
+		},
+		{
+			name:     "bare ipv6",
+			endpoint: "2001:db8::1",
+			want:     Destination{Host: "2001:db8::1", Port: "443"},
+		},
+		{
+			name:     "bracketed ipv6",
+			endpoint: "[::1]",
+			want:     Destination{Host: "::1", Port: "443"},
+		},
+		{
+			name:     "ipv6 url",
+			endpoint: "https://[::1]:8443",
+			want:     Destination{Host: "::1", Port: "8443"},
+		},
+		{
+			name:     "empty",
+			endpoint: " ",
+			wantErr:  "empty destination",
+		},
+		{
+			name:     "invalid port",
+			endpoint: "example.com:otlp",
+			wantErr:  `invalid destination "example.com:otlp": invalid port "otlp"`,
+		},
+		{
+			name:     "port out of range",
+			endpoint: "example.com:70000",
+			wantErr:  `invalid destination "example.com:70000": invalid port "70000"`,
+		},
+		{
+			name:     "missing host",
+			endpoint: ":4317",
+			wantErr:  `invalid destination ":4317": missing host`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDestination(tt.endpoint)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDestination_Address(t *testing.T) {
+	assert.Equal(t, "example.com:443", Destination{Host: "example.com", Port: "443"}.Address())
+	assert.Equal(t, "[::1]:4317", Destination{Host: "::1", Port: "4317"}.Address())
+}
@@ -7,41 +7,57 @@ import (
 	"time"
 
 	"github.com/lightstep/collector-cluster-check/pkg/steps"
+	"github.com/lightstep/collector-cluster-check/pkg/steps/dependencies"
 )
 
 type Dial struct{}
 
 var _ steps.Step = Dial{}
+var _ steps.PolicyProvider = Dial{}
 
-const (
-	timeout = 1 * time.Second
-	port    = "443"
-)
+// timeout bounds a single dial or ping of a destination
+const timeout = 1 * time.Second
 
 func (c Dial) Name() string {
-	return "Dial Lightstep"
+	return "Dial"
 }
 
 func (c Dial) Description() string {
-	return "Dials the lightstep address"
+	return "Dials every destination"
+}
+
+// Policy retries the dial since a single dropped SYN shouldn't fail the check
+func (c Dial) Policy() steps.Policy {
+	return steps.Policy{MaxAttempts: 3}
 }
 
 func (c Dial) Run(ctx context.Context, deps *steps.Deps) steps.Results {
-	conn, err := net.DialTimeout("tcp", destination+":"+port, timeout)
-	if err != nil {
-		return steps.NewResults(c, steps.NewFailureResultWithHelp(err, "failed to connect"))
+	if len(deps.Destinations) == 0 {
+		return steps.NewResults(c, steps.NewFailureResultWithHelp(nil, "destinations not set"))
 	}
-	err = conn.SetReadDeadline(time.Now().Add(timeout))
+	results := make([]steps.Result, len(deps.Destinations))
+	for i, d := range deps.Destinations {
+		results[i] = c.dial(ctx, d).WithAttribute("destination", d.Address())
+	}
+	return steps.NewResults(c, results...)
+}
+
+func (c Dial) dial(ctx context.Context, d steps.Destination) steps.Result {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", d.Address())
 	if err != nil {
-		return steps.NewResults(c, steps.NewFailureResult(err))
+		return steps.NewFailureResultWithHelp(err, fmt.Sprintf("failed to connect to %s", d))
 	}
 	err = conn.Close()
 	if err != nil {
-		return steps.NewResults(c, steps.NewFailureResult(err))
+		return steps.NewFailureResult(err)
 	}
-	return steps.NewResults(c, steps.NewSuccessfulResult(fmt.Sprintf("can dial %s", destination)))
+	return steps.NewSuccessfulResult(fmt.Sprintf("can dial %s", d)).
+		WithAttribute("remote_address", conn.RemoteAddr().String())
 }
 
 func (c Dial) Dependencies(config *steps.Config) []steps.Dependency {
-	return []steps.Dependency{}
+	return []steps.Dependency{dependencies.NewParseDestinationsFromConfig(config)}
 }
@@ -0,0 +1,55 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+func TestDial_Run(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+	// closing a second listener frees a port that nothing listens on
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	_, closedPort, err := net.SplitHostPort(closed.Addr().String())
+	require.NoError(t, err)
+	require.NoError(t, closed.Close())
+	defer listener.Close()
+
+	tests := []struct {
+		name         string
+		destinations []steps.Destination
+		want         []steps.Status
+	}{
+		{
+			name: "no destinations",
+			want: []steps.Status{steps.StatusFail},
+		},
+		{
+			name:         "every destination is dialed",
+			destinations: []steps.Destination{{Host: "127.0.0.1", Port: port}, {Host: "127.0.0.1", Port: closedPort}},
+			want:         []steps.Status{steps.StatusPass, steps.StatusFail},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Dial{}.Run(context.Background(), &steps.Deps{Destinations: tt.destinations})
+			var statuses []steps.Status
+			for _, r := range got.Steps() {
+				statuses = append(statuses, r.Status())
+			}
+			assert.Equal(t, tt.want, statuses)
+			for i, d := range tt.destinations {
+				assert.Equal(t, d.Address(), got.Steps()[i].Attributes()["destination"])
+			}
+		})
+	}
+}
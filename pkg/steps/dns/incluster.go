@@ -0,0 +1,135 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+	"github.com/lightstep/collector-cluster-check/pkg/steps/dependencies"
+)
+
+// curl exit codes that mean a phase failed, any other failure is attributed to the first phase that didn't finish
+const (
+	curlCouldNotResolve = 6
+	curlCouldNotConnect = 7
+)
+
+// curlTLSErrors are curl exit codes for failed handshakes and certificates that can't be verified
+var curlTLSErrors = map[int]bool{35: true, 51: true, 53: true, 54: true, 58: true, 59: true, 60: true, 66: true, 77: true, 80: true, 83: true, 90: true, 91: true}
+
+// phases are compared between the local machine and the cluster, in the order they happen
+var phases = []string{"lookup", "dial", "tls"}
+
+type InClusterProbe struct{}
+
+var _ steps.Step = InClusterProbe{}
+
+func (c InClusterProbe) Name() string {
+	return "InClusterProbe"
+}
+
+func (c InClusterProbe) Description() string {
+	return "Compares the DNS lookup, TCP dial and TLS handshake of every destination from the cluster and from the local machine"
+}
+
+func (c InClusterProbe) Run(ctx context.Context, deps *steps.Deps) steps.Results {
+	if len(deps.ClusterProbes) == 0 {
+		return steps.NewResults(c, steps.NewFailureResultWithHelp(nil, "cluster probes not set"))
+	}
+	var results []steps.Result
+	for _, probe := range deps.ClusterProbes {
+		d := probe.Destination
+		local := []steps.Result{
+			IPLookup{}.lookup(ctx, d.Host),
+			Dial{}.dial(ctx, d),
+			TLSHandshake{}.handshake(ctx, d, nil, nil, deps.OTLPTransport),
+		}
+		for i, cluster := range clusterPhases(probe) {
+			results = append(results, compare(d, phases[i], local[i], cluster).
+				WithAttribute("destination", d.Address()).
+				WithAttribute("phase", phases[i]))
+		}
+	}
+	return steps.NewResults(c, results...)
+}
+
+// phaseOutcome is how a phase went in the cluster
+type phaseOutcome struct {
+	status steps.Status
+	// took is how long after the start the phase finished
+	took time.Duration
+	err  string
+}
+
+func (o phaseOutcome) String() string {
+	switch o.status {
+	case steps.StatusPass:
+		return fmt.Sprintf("pass in %s", o.took)
+	case steps.StatusFail:
+		return fmt.Sprintf("fail (%s)", o.err)
+	}
+	return o.status.String()
+}
+
+// clusterPhases works out how far the probe got from curl's exit code and timings
+func clusterPhases(p steps.ProbeResult) []phaseOutcome {
+	took := []time.Duration{p.Lookup, p.Connect, p.Handshake}
+	failed := len(phases)
+	switch {
+	case p.ExitCode == 0:
+	case p.ExitCode == curlCouldNotResolve:
+		failed = 0
+	case p.ExitCode == curlCouldNotConnect:
+		failed = 1
+	case curlTLSErrors[p.ExitCode]:
+		failed = 2
+	default:
+		// timeouts and the like fail whichever phase didn't finish, failures after the handshake don't matter here
+		for i, t := range took {
+			if t == 0 {
+				failed = i
+				break
+			}
+		}
+	}
+	outcomes := make([]phaseOutcome, len(phases))
+	for i := range phases {
+		switch {
+		case i < failed:
+			outcomes[i] = phaseOutcome{status: steps.StatusPass, took: took[i]}
+		case i == failed:
+			outcomes[i] = phaseOutcome{status: steps.StatusFail, err: fmt.Sprintf("curl exit code %d: %s", p.ExitCode, p.Error)}
+		default:
+			outcomes[i] = phaseOutcome{status: steps.StatusSkipped}
+		}
+	}
+	return outcomes
+}
+
+// compare reports the cluster's outcome, with the local one next to it to tell cluster egress problems apart
+func compare(d steps.Destination, phase string, local steps.Result, cluster phaseOutcome) steps.Result {
+	message := fmt.Sprintf("%s %s: local %s, cluster %s", phase, d, local.Status(), cluster)
+	var r steps.Result
+	switch cluster.status {
+	case steps.StatusPass:
+		r = steps.NewSuccessfulResult(message)
+	case steps.StatusSkipped:
+		r = steps.NewSkippedResult(message)
+	default:
+		help := message + ", it fails locally too"
+		if local.ShouldContinue() {
+			help = message + ", check the cluster's egress rules, network policies and proxy"
+		}
+		r = steps.NewFailureResultWithHelp(nil, help)
+	}
+	r = r.WithAttribute("local", local.Status().String()).WithAttribute("cluster", cluster.String())
+	if local.Err() != nil {
+		r = r.WithAttribute("local_error", local.Err().Error())
+	}
+	return r
+}
+
+func (c InClusterProbe) Dependencies(config *steps.Config) []steps.Dependency {
+	return []steps.Dependency{dependencies.NewRunProbePodFromConfig(config)}
+}
@@ -0,0 +1,109 @@
+package dns
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+func TestClusterPhases(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		name  string
+		probe steps.ProbeResult
+		want  []string
+	}{
+		{
+			name:  "reachable",
+			probe: steps.ProbeResult{Lookup: ms, Connect: 2 * ms, Handshake: 3 * ms},
+			want:  []string{"pass in 1ms", "pass in 2ms", "pass in 3ms"},
+		},
+		{
+			name:  "could not resolve",
+			probe: steps.ProbeResult{ExitCode: 6, Error: "Could not resolve host: example.com"},
+			want:  []string{"fail (curl exit code 6: Could not resolve host: example.com)", "skip", "skip"},
+		},
+		{
+			name:  "could not connect",
+			probe: steps.ProbeResult{Lookup: ms, ExitCode: 7, Error: "Failed to connect"},
+			want:  []string{"pass in 1ms", "fail (curl exit code 7: Failed to connect)", "skip"},
+		},
+		{
+			name:  "untrusted certificate",
+			probe: steps.ProbeResult{Lookup: ms, Connect: 2 * ms, ExitCode: 60, Error: "SSL certificate problem"},
+			want:  []string{"pass in 1ms", "pass in 2ms", "fail (curl exit code 60: SSL certificate problem)"},
+		},
+		{
+			name:  "timed out connecting",
+			probe: steps.ProbeResult{Lookup: ms, ExitCode: 28, Error: "Connection timed out"},
+			want:  []string{"pass in 1ms", "fail (curl exit code 28: Connection timed out)", "skip"},
+		},
+		{
+			name:  "failed after the handshake",
+			probe: steps.ProbeResult{Lookup: ms, Connect: 2 * ms, Handshake: 3 * ms, ExitCode: 52, Error: "Empty reply from server"},
+			want:  []string{"pass in 1ms", "pass in 2ms", "pass in 3ms"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, o := range clusterPhases(tt.probe) {
+				got = append(got, o.String())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	d := steps.Destination{Host: "example.com", Port: "443"}
+	pass := phaseOutcome{status: steps.StatusPass, took: time.Millisecond}
+	fail := phaseOutcome{status: steps.StatusFail, err: "curl exit code 7: Failed to connect"}
+	tests := []struct {
+		name        string
+		local       steps.Result
+		cluster     phaseOutcome
+		wantStatus  steps.Status
+		wantMessage string
+	}{
+		{
+			name:        "both pass",
+			local:       steps.NewSuccessfulResult("ok"),
+			cluster:     pass,
+			wantStatus:  steps.StatusPass,
+			wantMessage: "dial example.com:443: local pass, cluster pass in 1ms",
+		},
+		{
+			name:        "only the cluster fails",
+			local:       steps.NewSuccessfulResult("ok"),
+			cluster:     fail,
+			wantStatus:  steps.StatusFail,
+			wantMessage: "dial example.com:443: local pass, cluster fail (curl exit code 7: Failed to connect), check the cluster's egress rules, network policies and proxy",
+		},
+		{
+			name:        "both fail",
+			local:       steps.NewFailureResult(fmt.Errorf("connection refused")),
+			cluster:     fail,
+			wantStatus:  steps.StatusFail,
+			wantMessage: "dial example.com:443: local fail, cluster fail (curl exit code 7: Failed to connect), it fails locally too",
+		},
+		{
+			name:        "only local fails",
+			local:       steps.NewFailureResult(fmt.Errorf("connection refused")),
+			cluster:     pass,
+			wantStatus:  steps.StatusPass,
+			wantMessage: "dial example.com:443: local fail, cluster pass in 1ms",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := compare(d, "dial", tt.local, tt.cluster)
+			assert.Equal(t, tt.wantStatus, got.Status())
+			assert.Equal(t, tt.wantMessage, got.Message())
+		})
+	}
+}
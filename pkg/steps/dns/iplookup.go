@@ -6,34 +6,47 @@ import (
 	"net"
 
 	"github.com/lightstep/collector-cluster-check/pkg/steps"
+	"github.com/lightstep/collector-cluster-check/pkg/steps/dependencies"
 )
 
 type IPLookup struct{}
 
 var _ steps.Step = IPLookup{}
 
-const (
-	destination = "ingest.lightstep.com"
-)
-
 func (c IPLookup) Name() string {
-	return "Lightstep IP Lookup"
+	return "IP Lookup"
 }
 
 func (c IPLookup) Description() string {
-	return "Looks up the IP address of lightstep"
+	return "Looks up the IP addresses of every destination"
 }
 
 func (c IPLookup) Run(ctx context.Context, deps *steps.Deps) steps.Results {
-	ips, err := net.LookupIP(destination)
+	if len(deps.Destinations) == 0 {
+		return steps.NewResults(c, steps.NewFailureResultWithHelp(nil, "destinations not set"))
+	}
+	results := make([]steps.Result, len(deps.Destinations))
+	for i, d := range deps.Destinations {
+		results[i] = c.lookup(ctx, d.Host).WithAttribute("host", d.Host)
+	}
+	return steps.NewResults(c, results...)
+}
+
+func (c IPLookup) lookup(ctx context.Context, host string) steps.Result {
+	ips, err := net.DefaultResolver.LookupIP(ctx, "ip", host)
 	if err != nil {
-		return steps.NewResults(c, steps.NewFailureResult(err))
+		return steps.NewFailureResult(err)
 	} else if len(ips) == 0 {
-		return steps.NewResults(c, steps.NewFailureResultWithHelp(nil, "no ips found"))
+		return steps.NewFailureResultWithHelp(nil, fmt.Sprintf("no ips found for %s", host))
+	}
+	addresses := make([]string, len(ips))
+	for i, ip := range ips {
+		addresses[i] = ip.String()
 	}
-	return steps.NewResults(c, steps.NewSuccessfulResult(fmt.Sprintf("%v", ips)))
+	return steps.NewSuccessfulResult(fmt.Sprintf("%s: %v", host, ips)).
+		WithAttribute("ips", addresses)
 }
 
 func (c IPLookup) Dependencies(config *steps.Config) []steps.Dependency {
-	return nil
+	return []steps.Dependency{dependencies.NewParseDestinationsFromConfig(config)}
 }
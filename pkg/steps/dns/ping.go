@@ -2,14 +2,34 @@ package dns
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"sort"
+	"syscall"
+	"time"
 
 	probing "github.com/prometheus-community/pro-bing"
 
 	"github.com/lightstep/collector-cluster-check/pkg/steps"
+	"github.com/lightstep/collector-cluster-check/pkg/steps/dependencies"
 )
 
-type Ping struct{}
+const (
+	pingCount    = 5
+	pingInterval = 200 * time.Millisecond
+	// maxLoss is the percentage of unanswered probes above which a ping fails, any less is a warning
+	maxLoss = 50
+)
+
+// pingFunc sends count probes to a destination and returns the round trip time of every probe that was answered
+type pingFunc func(ctx context.Context, d steps.Destination, count int) ([]time.Duration, error)
+
+type Ping struct {
+	// icmp and tcp are replaced in tests, pro-bing and TCP connects are used when unset
+	icmp pingFunc
+	tcp  pingFunc
+}
 
 var _ steps.Step = Ping{}
 
@@ -18,27 +38,142 @@ func (c Ping) Name() string {
 }
 
 func (c Ping) Description() string {
-	return "Pings the lightstep address"
+	return "Pings every destination, timing TCP connects instead when ICMP is unavailable"
 }
 
 func (c Ping) Run(ctx context.Context, deps *steps.Deps) steps.Results {
-	pinger, err := probing.NewPinger("ingest.lightstep.com")
+	if len(deps.Destinations) == 0 {
+		return steps.NewResults(c, steps.NewFailureResultWithHelp(nil, "destinations not set"))
+	}
+	results := make([]steps.Result, len(deps.Destinations))
+	for i, d := range deps.Destinations {
+		results[i] = c.ping(ctx, d).WithAttribute("host", d.Host)
+	}
+	return steps.NewResults(c, results...)
+}
+
+func (c Ping) ping(ctx context.Context, d steps.Destination) steps.Result {
+	icmp, tcp := c.icmp, c.tcp
+	if icmp == nil {
+		icmp = icmpPing
+	}
+	if tcp == nil {
+		tcp = tcpPing
+	}
+	rtts, err := icmp(ctx, d, pingCount)
+	var unavailable string
+	switch {
+	case errors.Is(err, os.ErrPermission):
+		unavailable = "ICMP isn't permitted for this user"
+	case errors.Is(err, syscall.EPROTONOSUPPORT), errors.Is(err, syscall.EAFNOSUPPORT):
+		// containers and some kernels don't support ICMP sockets at all
+		unavailable = "ICMP isn't supported here"
+	case err != nil:
+		return steps.NewFailureResult(err)
+	case len(rtts) == 0:
+		// a host that doesn't answer pings may still accept connections, networks often drop ICMP
+		unavailable = "ICMP got no replies"
+	default:
+		stats := newLatency(rtts, pingCount)
+		if stats.loss > maxLoss {
+			return stats.attributes(steps.NewFailureResultWithHelp(nil, fmt.Sprintf("%s: %v%% packet loss", d.Host, stats.loss)), "icmp")
+		}
+		if stats.loss > 0 {
+			return stats.attributes(steps.NewAcceptableFailureResultWithHelp(nil, fmt.Sprintf("%s: %v%% packet loss", d.Host, stats.loss)), "icmp")
+		}
+		return stats.attributes(steps.NewSuccessfulResult(fmt.Sprintf("pong from %s in %s", d.Host, stats.avg)), "icmp")
+	}
+
+	rtts, err = tcp(ctx, d, pingCount)
 	if err != nil {
-		return steps.NewResults(c, steps.NewFailureResult(err))
+		return steps.NewFailureResult(err)
 	}
-	pinger.Count = 3
-	pinger.Timeout = timeout
+	stats := newLatency(rtts, pingCount)
+	if len(rtts) == 0 {
+		return stats.attributes(steps.NewFailureResultWithHelp(nil, fmt.Sprintf("%s, and %s doesn't accept TCP connections", unavailable, d)), "tcp")
+	}
+	help := fmt.Sprintf("%s, TCP connects to %s took %s with %v%% loss", unavailable, d, stats.avg, stats.loss)
+	if stats.loss > maxLoss {
+		return stats.attributes(steps.NewFailureResultWithHelp(nil, help), "tcp")
+	}
+	return stats.attributes(steps.NewAcceptableFailureResultWithHelp(nil, help), "tcp")
+}
+
+func (c Ping) Dependencies(config *steps.Config) []steps.Dependency {
+	return []steps.Dependency{dependencies.NewParseDestinationsFromConfig(config)}
+}
+
+func icmpPing(ctx context.Context, d steps.Destination, count int) ([]time.Duration, error) {
+	pinger, err := probing.NewPinger(d.Host)
+	if err != nil {
+		return nil, err
+	}
+	pinger.Count = count
+	pinger.Interval = pingInterval
+	// the timeout covers every probe, not just one
+	pinger.Timeout = time.Duration(count)*pingInterval + timeout
 	err = pinger.RunWithContext(ctx)
 	if err != nil {
-		return steps.NewResults(c, steps.NewFailureResult(err))
+		return nil, err
 	}
-	stats := pinger.Statistics()
-	if stats.PacketLoss > 0 {
-		return steps.NewResults(c, steps.NewFailureResultWithHelp(nil, fmt.Sprintf("%v%% packet loss", stats.PacketLoss)))
+	return pinger.Statistics().Rtts, nil
+}
+
+// tcpPing times how long TCP connects to the destination's port take
+func tcpPing(ctx context.Context, d steps.Destination, count int) ([]time.Duration, error) {
+	var rtts []time.Duration
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return rtts, ctx.Err()
+			case <-time.After(pingInterval):
+			}
+		}
+		start := time.Now()
+		err := Dial{}.dial(ctx, d).Err()
+		if err == nil && ctx.Err() == nil {
+			rtts = append(rtts, time.Since(start))
+		}
 	}
-	return steps.NewResults(c, steps.NewSuccessfulResult("pong"))
+	return rtts, ctx.Err()
 }
 
-func (c Ping) Dependencies(config *steps.Config) []steps.Dependency {
-	return nil
+// latency summarizes the round trip times of a number of probes
+type latency struct {
+	min, avg, max, p95 time.Duration
+	// loss is the percentage of probes that weren't answered
+	loss float64
+}
+
+func newLatency(rtts []time.Duration, sent int) latency {
+	var l latency
+	if sent > 0 {
+		l.loss = float64(sent-len(rtts)) / float64(sent) * 100
+	}
+	if len(rtts) == 0 {
+		return l
+	}
+	sorted := append([]time.Duration(nil), rtts...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var total time.Duration
+	for _, rtt := range sorted {
+		total += rtt
+	}
+	l.min = sorted[0]
+	l.max = sorted[len(sorted)-1]
+	l.avg = total / time.Duration(len(sorted))
+	// nearest rank percentile
+	rank := (95*len(sorted) + 99) / 100
+	l.p95 = sorted[rank-1]
+	return l
+}
+
+func (l latency) attributes(r steps.Result, method string) steps.Result {
+	return r.WithAttribute("method", method).
+		WithAttribute("min", l.min.String()).
+		WithAttribute("avg", l.avg.String()).
+		WithAttribute("max", l.max.String()).
+		WithAttribute("p95", l.p95.String()).
+		WithAttribute("loss", fmt.Sprintf("%v%%", l.loss))
 }
@@ -0,0 +1,151 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+func replies(rtts ...time.Duration) pingFunc {
+	return func(ctx context.Context, d steps.Destination, count int) ([]time.Duration, error) {
+		return rtts, nil
+	}
+}
+
+func fails(err error) pingFunc {
+	return func(ctx context.Context, d steps.Destination, count int) ([]time.Duration, error) {
+		return nil, err
+	}
+}
+
+func TestPing_Run(t *testing.T) {
+	ms := time.Millisecond
+	permissionDenied := &net.OpError{Op: "listen", Net: "udp", Err: os.NewSyscallError("socket", syscall.EACCES)}
+	unsupported := &net.OpError{Op: "listen", Net: "ip4:icmp", Err: os.NewSyscallError("socket", syscall.EPROTONOSUPPORT)}
+	noAddressFamily := &net.OpError{Op: "listen", Net: "ip6:ipv6-icmp", Err: os.NewSyscallError("socket", syscall.EAFNOSUPPORT)}
+	tests := []struct {
+		name        string
+		ping        Ping
+		wantStatus  steps.Status
+		wantMessage string
+		wantMethod  string
+	}{
+		{
+			name:        "icmp",
+			ping:        Ping{icmp: replies(ms, 2*ms, 3*ms, 4*ms, 5*ms)},
+			wantStatus:  steps.StatusPass,
+			wantMessage: "pong from example.com in 3ms",
+			wantMethod:  "icmp",
+		},
+		{
+			name:        "icmp partial loss",
+			ping:        Ping{icmp: replies(ms, 2*ms, 3*ms, 4*ms)},
+			wantStatus:  steps.StatusWarning,
+			wantMessage: "example.com: 20% packet loss",
+			wantMethod:  "icmp",
+		},
+		{
+			name:        "icmp loss",
+			ping:        Ping{icmp: replies(ms, 2*ms)},
+			wantStatus:  steps.StatusFail,
+			wantMessage: "example.com: 60% packet loss",
+			wantMethod:  "icmp",
+		},
+		{
+			name:        "not permitted falls back to tcp",
+			ping:        Ping{icmp: fails(permissionDenied), tcp: replies(2*ms, 2*ms, 2*ms, 2*ms, 2*ms)},
+			wantStatus:  steps.StatusWarning,
+			wantMessage: "ICMP isn't permitted for this user, TCP connects to example.com:443 took 2ms with 0% loss",
+			wantMethod:  "tcp",
+		},
+		{
+			name:        "unsupported falls back to tcp",
+			ping:        Ping{icmp: fails(unsupported), tcp: replies(2*ms, 2*ms, 2*ms, 2*ms, 2*ms)},
+			wantStatus:  steps.StatusWarning,
+			wantMessage: "ICMP isn't supported here, TCP connects to example.com:443 took 2ms with 0% loss",
+			wantMethod:  "tcp",
+		},
+		{
+			name:        "no address family falls back to tcp",
+			ping:        Ping{icmp: fails(noAddressFamily), tcp: replies(2*ms, 2*ms, 2*ms, 2*ms, 2*ms)},
+			wantStatus:  steps.StatusWarning,
+			wantMessage: "ICMP isn't supported here, TCP connects to example.com:443 took 2ms with 0% loss",
+			wantMethod:  "tcp",
+		},
+		{
+			name:        "blocked falls back to tcp",
+			ping:        Ping{icmp: replies(), tcp: replies(2*ms, 4*ms, 6*ms, 8*ms)},
+			wantStatus:  steps.StatusWarning,
+			wantMessage: "ICMP got no replies, TCP connects to example.com:443 took 5ms with 20% loss",
+			wantMethod:  "tcp",
+		},
+		{
+			name:        "tcp loss",
+			ping:        Ping{icmp: replies(), tcp: replies(2*ms, 4*ms)},
+			wantStatus:  steps.StatusFail,
+			wantMessage: "ICMP got no replies, TCP connects to example.com:443 took 3ms with 60% loss",
+			wantMethod:  "tcp",
+		},
+		{
+			name:        "unreachable",
+			ping:        Ping{icmp: replies(), tcp: replies()},
+			wantStatus:  steps.StatusFail,
+			wantMessage: "ICMP got no replies, and example.com:443 doesn't accept TCP connections",
+			wantMethod:  "tcp",
+		},
+		{
+			name:        "other icmp errors fail",
+			ping:        Ping{icmp: fails(fmt.Errorf("lookup example.com: no such host"))},
+			wantStatus:  steps.StatusFail,
+			wantMessage: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := &steps.Deps{Destinations: []steps.Destination{{Host: "example.com", Port: "443"}}}
+			got := tt.ping.Run(context.Background(), deps)
+			require.Len(t, got.Steps(), 1)
+			assert.Equal(t, tt.wantStatus, got.Steps()[0].Status())
+			assert.Equal(t, tt.wantMessage, got.Steps()[0].Message())
+			if tt.wantMethod != "" {
+				assert.Equal(t, tt.wantMethod, got.Steps()[0].Attributes()["method"])
+			}
+		})
+	}
+}
+
+func TestNewLatency(t *testing.T) {
+	var rtts []time.Duration
+	for i := 20; i >= 1; i-- {
+		rtts = append(rtts, time.Duration(i)*time.Millisecond)
+	}
+	got := newLatency(rtts, 25)
+	assert.Equal(t, latency{
+		min:  time.Millisecond,
+		avg:  10500 * time.Microsecond,
+		max:  20 * time.Millisecond,
+		p95:  19 * time.Millisecond,
+		loss: 20,
+	}, got)
+	assert.Equal(t, latency{loss: 100}, newLatency(nil, 5))
+}
+
+func TestTcpPing(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+	rtts, err := tcpPing(context.Background(), steps.Destination{Host: host, Port: port}, 2)
+	assert.NoError(t, err)
+	assert.Len(t, rtts, 2)
+}
@@ -0,0 +1,96 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+	"github.com/lightstep/collector-cluster-check/pkg/steps/dependencies"
+)
+
+type ProxyDetection struct{}
+
+var _ steps.Step = ProxyDetection{}
+
+func (c ProxyDetection) Name() string {
+	return "Proxy Detection"
+}
+
+func (c ProxyDetection) Description() string {
+	return "Shows which proxy, if any, traffic to every destination goes through"
+}
+
+func (c ProxyDetection) Run(ctx context.Context, deps *steps.Deps) steps.Results {
+	if len(deps.Proxies) == 0 {
+		return steps.NewResults(c, steps.NewFailureResultWithHelp(nil, "proxies not set"))
+	}
+	results := make([]steps.Result, len(deps.Proxies))
+	for i, p := range deps.Proxies {
+		var r steps.Result
+		switch {
+		case p.URL != nil:
+			r = steps.NewSuccessfulResult(fmt.Sprintf("%s goes through %s", p.Destination, p.URL.Redacted())).
+				WithAttribute("proxy", p.URL.Redacted())
+		case p.Source == "NO_PROXY":
+			r = steps.NewSuccessfulResult(fmt.Sprintf("%s connects directly, it is excluded by NO_PROXY", p.Destination))
+		default:
+			r = steps.NewSuccessfulResult(fmt.Sprintf("%s connects directly", p.Destination))
+		}
+		results[i] = r.WithAttribute("destination", p.Destination.Address()).WithAttribute("source", p.Source)
+	}
+	return steps.NewResults(c, results...)
+}
+
+func (c ProxyDetection) Dependencies(config *steps.Config) []steps.Dependency {
+	return []steps.Dependency{dependencies.NewResolveProxiesFromConfig(config)}
+}
+
+type ProxyDial struct{}
+
+var _ steps.Step = ProxyDial{}
+var _ steps.PolicyProvider = ProxyDial{}
+
+func (c ProxyDial) Name() string {
+	return "Dial Through Proxy"
+}
+
+func (c ProxyDial) Description() string {
+	return "Opens a tunnel to every destination through its proxy with HTTP CONNECT, like the exporters do"
+}
+
+func (c ProxyDial) Policy() steps.Policy {
+	return steps.Policy{MaxAttempts: 3}
+}
+
+func (c ProxyDial) Run(ctx context.Context, deps *steps.Deps) steps.Results {
+	if len(deps.Proxies) == 0 {
+		return steps.NewResults(c, steps.NewFailureResultWithHelp(nil, "proxies not set"))
+	}
+	results := make([]steps.Result, len(deps.Proxies))
+	for i, p := range deps.Proxies {
+		results[i] = c.dial(ctx, p).WithAttribute("destination", p.Destination.Address())
+	}
+	return steps.NewResults(c, results...)
+}
+
+func (c ProxyDial) dial(ctx context.Context, p steps.Proxy) steps.Result {
+	if p.URL == nil {
+		return steps.NewSkippedResult(fmt.Sprintf("no proxy applies to %s", p.Destination))
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	conn, err := steps.DialProxy(ctx, p.URL, p.Destination.Address())
+	if err != nil {
+		return steps.NewFailureResultWithHelp(err, fmt.Sprintf("failed to connect to %s through %s", p.Destination, p.URL.Redacted()))
+	}
+	err = conn.Close()
+	if err != nil {
+		return steps.NewFailureResult(err)
+	}
+	return steps.NewSuccessfulResult(fmt.Sprintf("can dial %s through %s", p.Destination, p.URL.Redacted())).
+		WithAttribute("proxy", p.URL.Redacted())
+}
+
+func (c ProxyDial) Dependencies(config *steps.Config) []steps.Dependency {
+	return []steps.Dependency{dependencies.NewResolveProxiesFromConfig(config)}
+}
@@ -0,0 +1,206 @@
+package dns
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lightstep/collector-cluster-check/pkg/otlp"
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+	"github.com/lightstep/collector-cluster-check/pkg/steps/dependencies"
+)
+
+const (
+	handshakeTimeout = 5 * time.Second
+	// expiryWarning is how close to expiring a certificate may get before it is worth a warning
+	expiryWarning = 14 * 24 * time.Hour
+)
+
+// mitmIssuers are vendors of TLS inspecting proxies, a chain issued by one of them means traffic is intercepted
+var mitmIssuers = []string{
+	"zscaler",
+	"netskope",
+	"fortinet",
+	"fortigate",
+	"palo alto",
+	"blue coat",
+	"bluecoat",
+	"forcepoint",
+	"sophos",
+	"check point",
+	"cisco umbrella",
+	"mcafee",
+	"kaspersky",
+	"smoothwall",
+	"menlo security",
+}
+
+type TLSHandshake struct {
+	// roots verify the chain unless the endpoint has a CA of its own, the system roots are used when unset
+	roots *x509.CertPool
+	now   func() time.Time
+}
+
+var _ steps.Step = TLSHandshake{}
+
+func (c TLSHandshake) Name() string {
+	return "TLS Handshake"
+}
+
+func (c TLSHandshake) Description() string {
+	return "Performs a TLS handshake with every destination and checks its certificates"
+}
+
+func (c TLSHandshake) Run(ctx context.Context, deps *steps.Deps) steps.Results {
+	if len(deps.Destinations) == 0 {
+		return steps.NewResults(c, steps.NewFailureResultWithHelp(nil, "destinations not set"))
+	}
+	results := make([]steps.Result, len(deps.Destinations))
+	for i, d := range deps.Destinations {
+		var proxy *url.URL
+		if i < len(deps.Proxies) {
+			proxy = deps.Proxies[i].URL
+		}
+		// the exporters' TLS settings only apply to the endpoint, which comes first
+		var conf *tls.Config
+		if i == 0 {
+			conf = deps.EndpointTLS
+		}
+		results[i] = c.handshake(ctx, d, proxy, conf, deps.OTLPTransport).WithAttribute("destination", d.Address())
+		if proxy != nil {
+			results[i] = results[i].WithAttribute("proxy", proxy.Redacted())
+		}
+	}
+	return steps.NewResults(c, results...)
+}
+
+func (c TLSHandshake) handshake(ctx context.Context, d steps.Destination, proxy *url.URL, conf *tls.Config, transport otlp.Transport) steps.Result {
+	ctx, cancel := context.WithTimeout(ctx, handshakeTimeout)
+	defer cancel()
+	serverName, roots := d.Host, c.roots
+	var certificates []tls.Certificate
+	if conf != nil {
+		if conf.ServerName != "" {
+			serverName = conf.ServerName
+		}
+		if conf.RootCAs != nil {
+			roots = conf.RootCAs
+		}
+		certificates = conf.Certificates
+	}
+	raw, err := dial(ctx, d, proxy)
+	if err != nil {
+		return steps.NewFailureResultWithHelp(err, fmt.Sprintf("TLS handshake with %s failed", d))
+	}
+	conn := tls.Client(raw, &tls.Config{
+		ServerName:   serverName,
+		Certificates: certificates,
+		// http/1.1 is offered too so that servers without h2 still complete the handshake and can be diagnosed
+		NextProtos: []string{"h2", "http/1.1"},
+		// the chain is verified below so that a bad certificate is reported along with everything else
+		InsecureSkipVerify: true,
+	})
+	defer conn.Close()
+	if err := conn.HandshakeContext(ctx); err != nil {
+		return steps.NewFailureResultWithHelp(err, fmt.Sprintf("TLS handshake with %s failed", d))
+	}
+	state := conn.ConnectionState()
+	now := time.Now
+	if c.now != nil {
+		now = c.now
+	}
+
+	chain := make([]string, len(state.PeerCertificates))
+	var expiry time.Time
+	var mitm string
+	for i, cert := range state.PeerCertificates {
+		chain[i] = fmt.Sprintf("%s (issuer: %s, expires: %s)", cert.Subject, cert.Issuer, cert.NotAfter.Format(time.RFC3339))
+		if expiry.IsZero() || cert.NotAfter.Before(expiry) {
+			expiry = cert.NotAfter
+		}
+		if mitm == "" {
+			mitm = mitmIssuer(cert)
+		}
+	}
+	alpn := state.NegotiatedProtocol
+	if alpn == "" {
+		alpn = "none"
+	}
+	withDetails := func(r steps.Result) steps.Result {
+		return r.WithAttribute("version", tls.VersionName(state.Version)).
+			WithAttribute("cipher", tls.CipherSuiteName(state.CipherSuite)).
+			WithAttribute("alpn", alpn).
+			WithAttribute("chain", chain).
+			WithAttribute("expires", expiry.Format(time.RFC3339))
+	}
+
+	if err := verify(serverName, roots, state.PeerCertificates, now()); err != nil {
+		help := fmt.Sprintf("certificate of %s can't be verified", d)
+		if mitm != "" {
+			help = fmt.Sprintf("certificate of %s was issued by %s, a TLS inspecting proxy, its CA must be trusted or the endpoint excluded from inspection", d, mitm)
+		}
+		return withDetails(steps.NewFailureResultWithHelp(err, help).WithAttribute("verification_error", err.Error()))
+	}
+	if mitm != "" {
+		return withDetails(steps.NewAcceptableFailureResultWithHelp(nil, fmt.Sprintf("traffic to %s is intercepted by %s", d, mitm)))
+	}
+	if expiry.Sub(now()) < expiryWarning {
+		return withDetails(steps.NewAcceptableFailureResultWithHelp(nil, fmt.Sprintf("a certificate of %s expires on %s", d, expiry.Format(time.RFC3339))))
+	}
+	// only gRPC needs h2, HTTP exporters work over http/1.1
+	if alpn != "h2" && transport != otlp.TransportHTTPProtobuf && transport != otlp.TransportHTTPJSON {
+		return withDetails(steps.NewAcceptableFailureResultWithHelp(nil, fmt.Sprintf("%s didn't negotiate h2, gRPC won't work through it", d)))
+	}
+	return withDetails(steps.NewSuccessfulResult(fmt.Sprintf("%s with %s", tls.VersionName(state.Version), d)))
+}
+
+// dial connects to the destination, through its proxy when it has one
+func dial(ctx context.Context, d steps.Destination, proxy *url.URL) (net.Conn, error) {
+	if proxy != nil {
+		return steps.DialProxy(ctx, proxy, d.Address())
+	}
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, "tcp", d.Address())
+}
+
+func verify(host string, roots *x509.CertPool, certs []*x509.Certificate, now time.Time) error {
+	if len(certs) == 0 {
+		return errors.New("no certificates presented")
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		DNSName:       host,
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+	})
+	return err
+}
+
+// mitmIssuer returns the vendor of a TLS inspecting proxy that issued the certificate, if any
+func mitmIssuer(cert *x509.Certificate) string {
+	names := append([]string{cert.Issuer.CommonName}, cert.Issuer.Organization...)
+	names = append(names, cert.Issuer.OrganizationalUnit...)
+	for _, name := range names {
+		lower := strings.ToLower(name)
+		for _, vendor := range mitmIssuers {
+			if strings.Contains(lower, vendor) {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+func (c TLSHandshake) Dependencies(config *steps.Config) []steps.Dependency {
+	return []steps.Dependency{dependencies.NewResolveProxiesFromConfig(config), dependencies.NewLoadEndpointTLSFromConfig(config)}
+}
@@ -0,0 +1,199 @@
+package dns
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lightstep/collector-cluster-check/pkg/otlp"
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+func newTLSServer(t *testing.T, http2 bool) (*httptest.Server, steps.Destination) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.EnableHTTP2 = http2
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	return server, steps.Destination{Host: host, Port: port}
+}
+
+func TestTLSHandshake_Run(t *testing.T) {
+	h2Server, h2Destination := newTLSServer(t, true)
+	http1Server, http1Destination := newTLSServer(t, false)
+	trusted := x509.NewCertPool()
+	trusted.AddCert(h2Server.Certificate())
+	trusted.AddCert(http1Server.Certificate())
+	expiresSoon := func() time.Time {
+		return h2Server.Certificate().NotAfter.Add(-24 * time.Hour)
+	}
+
+	tests := []struct {
+		name        string
+		step        TLSHandshake
+		destination steps.Destination
+		transport   otlp.Transport
+		wantStatus  steps.Status
+		wantMessage string
+		wantALPN    string
+	}{
+		{
+			name:        "trusted",
+			step:        TLSHandshake{roots: trusted},
+			destination: h2Destination,
+			wantStatus:  steps.StatusPass,
+			wantMessage: "TLS 1.3 with " + h2Destination.Address(),
+			wantALPN:    "h2",
+		},
+		{
+			name:        "untrusted",
+			step:        TLSHandshake{roots: x509.NewCertPool()},
+			destination: h2Destination,
+			wantStatus:  steps.StatusFail,
+			wantMessage: "certificate of " + h2Destination.Address() + " can't be verified",
+			wantALPN:    "h2",
+		},
+		{
+			name:        "expires soon",
+			step:        TLSHandshake{roots: trusted, now: expiresSoon},
+			destination: h2Destination,
+			wantStatus:  steps.StatusWarning,
+			wantMessage: "a certificate of " + h2Destination.Address() + " expires on " + h2Server.Certificate().NotAfter.Format(time.RFC3339),
+			wantALPN:    "h2",
+		},
+		{
+			name:        "no h2",
+			step:        TLSHandshake{roots: trusted},
+			destination: http1Destination,
+			wantStatus:  steps.StatusWarning,
+			wantMessage: http1Destination.Address() + " didn't negotiate h2, gRPC won't work through it",
+			wantALPN:    "http/1.1",
+		},
+		{
+			name:        "no h2 with http exporters",
+			step:        TLSHandshake{roots: trusted},
+			destination: http1Destination,
+			transport:   otlp.TransportHTTPProtobuf,
+			wantStatus:  steps.StatusPass,
+			wantMessage: "TLS 1.3 with " + http1Destination.Address(),
+			wantALPN:    "http/1.1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.step.Run(context.Background(), &steps.Deps{Destinations: []steps.Destination{tt.destination}, OTLPTransport: tt.transport})
+			require.Len(t, got.Steps(), 1)
+			result := got.Steps()[0]
+			assert.Equal(t, tt.wantStatus, result.Status())
+			assert.Equal(t, tt.wantMessage, result.Message())
+			assert.Equal(t, tt.wantALPN, result.Attributes()["alpn"])
+			assert.Equal(t, "TLS 1.3", result.Attributes()["version"])
+			assert.Len(t, result.Attributes()["chain"], 1)
+		})
+	}
+}
+
+// newTunnelingProxy tunnels CONNECT requests and counts them
+func newTunnelingProxy(t *testing.T, tunnels *atomic.Int32) *url.URL {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstream, err := net.Dial("tcp", r.Host)
+		if r.Method != http.MethodConnect || err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		tunnels.Add(1)
+		w.WriteHeader(http.StatusOK)
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			upstream.Close()
+			return
+		}
+		go func() {
+			_, _ = io.Copy(upstream, buf)
+			upstream.Close()
+		}()
+		_, _ = io.Copy(conn, upstream)
+		conn.Close()
+	}))
+	t.Cleanup(proxy.Close)
+	u, err := url.Parse(proxy.URL)
+	require.NoError(t, err)
+	return u
+}
+
+func TestTLSHandshake_RunEndpointTLS(t *testing.T) {
+	server, endpoint := newTLSServer(t, true)
+	_, extra := newTLSServer(t, true)
+	privateCA := x509.NewCertPool()
+	privateCA.AddCert(server.Certificate())
+	var tunnels atomic.Int32
+	proxy := newTunnelingProxy(t, &tunnels)
+
+	got := TLSHandshake{roots: x509.NewCertPool()}.Run(context.Background(), &steps.Deps{
+		Destinations: []steps.Destination{endpoint, extra},
+		Proxies:      []steps.Proxy{{Destination: endpoint, URL: proxy}, {Destination: extra}},
+		// the test server's certificate is for example.com and 127.0.0.1
+		EndpointTLS: &tls.Config{RootCAs: privateCA, ServerName: "example.com"},
+	})
+	require.Len(t, got.Steps(), 2)
+	assert.Equal(t, steps.StatusPass, got.Steps()[0].Status(), "the endpoint is verified with its own CA and server name")
+	assert.Equal(t, proxy.Redacted(), got.Steps()[0].Attributes()["proxy"])
+	assert.Equal(t, steps.StatusFail, got.Steps()[1].Status(), "other destinations don't use the endpoint's CA")
+	assert.Equal(t, int32(1), tunnels.Load())
+}
+
+func TestTLSHandshake_RunNotTLS(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	got := TLSHandshake{}.Run(context.Background(), &steps.Deps{Destinations: []steps.Destination{{Host: host, Port: port}}})
+	assert.Equal(t, steps.StatusFail, got.Status())
+	assert.Equal(t, "TLS handshake with "+net.JoinHostPort(host, port)+" failed", got.Steps()[0].Message())
+}
+
+func TestMitmIssuer(t *testing.T) {
+	tests := []struct {
+		name   string
+		issuer pkix.Name
+		want   string
+	}{
+		{
+			name:   "public CA",
+			issuer: pkix.Name{CommonName: "R11", Organization: []string{"Let's Encrypt"}},
+		},
+		{
+			name:   "common name",
+			issuer: pkix.Name{CommonName: "Zscaler Intermediate Root CA (zscaler.net)"},
+			want:   "Zscaler Intermediate Root CA (zscaler.net)",
+		},
+		{
+			name:   "organization",
+			issuer: pkix.Name{CommonName: "ca.corp.example.com", Organization: []string{"Netskope Inc"}},
+			want:   "Netskope Inc",
+		},
+		{
+			name:   "organizational unit",
+			issuer: pkix.Name{CommonName: "FGT60F", OrganizationalUnit: []string{"FortiGate"}},
+			want:   "FortiGate",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, mitmIssuer(&x509.Certificate{Issuer: tt.issuer}))
+		})
+	}
+}
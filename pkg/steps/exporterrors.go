@@ -0,0 +1,38 @@
+package steps
+
+import (
+	"fmt"
+
+	"github.com/lightstep/collector-cluster-check/pkg/otlp"
+)
+
+// ShutdownResults reports a provider's exports when it's shut down: one result for every problem its exports ran into,
+// including background exports that only the error collector saw, and one for a flush error that wasn't an export's
+func ShutdownResults(flushErr error, errs *otlp.ErrorCollector, success string) []Result {
+	var problems []otlp.CollectedProblem
+	if errs != nil {
+		problems = errs.Problems()
+	}
+	var results []Result
+	for _, p := range problems {
+		help := fmt.Sprintf("%d exports failed, %s", p.Count, p.Help)
+		if p.Count == 1 {
+			help = fmt.Sprintf("1 export failed, %s", p.Help)
+		}
+		var r Result
+		// a transient problem is only a warning when the final flush made it through
+		if p.Transient() && flushErr == nil {
+			r = NewAcceptableFailureResultWithHelp(p.Err, help)
+		} else {
+			r = NewFailureResultWithHelp(p.Err, help)
+		}
+		results = append(results, r.WithAttribute("problem", string(p.Problem)).WithAttribute("failed_exports", p.Count))
+	}
+	if flushErr != nil && (errs == nil || !errs.Owns(flushErr)) {
+		results = append(results, NewFailureResultWithHelp(flushErr, otlp.Diagnose(flushErr).Help))
+	}
+	if len(results) == 0 {
+		return []Result{NewSuccessfulResult(success)}
+	}
+	return results
+}
@@ -0,0 +1,75 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/lightstep/collector-cluster-check/pkg/otlp"
+)
+
+func TestShutdownResults(t *testing.T) {
+	unauthenticated := status.Error(codes.Unauthenticated, "invalid token")
+	limited := fmt.Errorf("failed to send to https://ingest.lightstep.com:443/v1/traces: 429 Too Many Requests")
+	tests := []struct {
+		name     string
+		flushErr error
+		exports  []error
+		want     []Status
+		wantHelp []string
+	}{
+		{
+			name:     "every export succeeded",
+			want:     []Status{StatusPass},
+			wantHelp: []string{"shutdown tracer provider"},
+		},
+		{
+			name:     "background exports failed but the flush succeeded",
+			exports:  []error{unauthenticated, status.Error(codes.Unauthenticated, "expired token")},
+			want:     []Status{StatusFail},
+			wantHelp: []string{"2 exports failed, the access token is missing or invalid, check --accessToken"},
+		},
+		{
+			name:     "rate limited before a successful flush",
+			exports:  []error{limited},
+			want:     []Status{StatusWarning},
+			wantHelp: []string{"1 export failed, the endpoint is rate limiting requests, telemetry will be retried but may be dropped"},
+		},
+		{
+			name:     "the flush failed on an export that was collected",
+			flushErr: limited,
+			exports:  []error{limited},
+			want:     []Status{StatusFail},
+			wantHelp: []string{"1 export failed, the endpoint is rate limiting requests, telemetry will be retried but may be dropped"},
+		},
+		{
+			name:     "the flush timed out after an export failed",
+			flushErr: context.DeadlineExceeded,
+			exports:  []error{unauthenticated},
+			want:     []Status{StatusFail, StatusFail},
+			wantHelp: []string{
+				"1 export failed, the access token is missing or invalid, check --accessToken",
+				"the request timed out, check firewall rules and proxies",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := otlp.NewErrorCollector()
+			for _, err := range tt.exports {
+				errs.Record(err)
+			}
+			results := ShutdownResults(tt.flushErr, errs, "shutdown tracer provider")
+			require.Len(t, results, len(tt.want))
+			for i, r := range results {
+				assert.Equal(t, tt.want[i], r.Status())
+				assert.Equal(t, tt.wantHelp[i], r.Message())
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package steps
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// ExporterTLS secures the exporters' connections with a private CA or a client certificate, it has no effect with
+// --insecure
+type ExporterTLS struct {
+	// CAFile is a PEM bundle that replaces the system roots
+	CAFile string
+	// CertFile and KeyFile are the PEM client certificate and key for mTLS, both or neither are set
+	CertFile string
+	KeyFile  string
+	// ServerName replaces the endpoint's host when verifying the server certificate
+	ServerName string
+}
+
+// IsSet is whether any setting differs from the defaults, i.e. the system roots and the endpoint's host
+func (t ExporterTLS) IsSet() bool {
+	return t != ExporterTLS{}
+}
+
+// ClientConfig loads the CA and client certificate into a TLS config
+func (t ExporterTLS) ClientConfig() (*tls.Config, error) {
+	conf := &tls.Config{ServerName: t.ServerName}
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", t.CAFile)
+		}
+		conf.RootCAs = pool
+	}
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return nil, fmt.Errorf("a client certificate needs both a certificate and a key file")
+	}
+	if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+	return conf, nil
+}
+
+// ExportOptions are the connection settings every exporter is created with, whatever its signal and protocol
+type ExportOptions struct {
+	Endpoint string
+	Insecure bool
+	// Headers are sent with every export request, e.g. the access token
+	Headers map[string]string
+	// Proxy replaces the proxy from the environment when set
+	Proxy       string
+	TLS         ExporterTLS
+	Compression Compression
+}
+
+func ExportOptionsFromConfig(config *Config) ExportOptions {
+	return ExportOptions{
+		Endpoint:    config.Endpoint,
+		Insecure:    config.Insecure,
+		Headers:     config.Headers,
+		Proxy:       config.Proxy,
+		TLS:         config.TLS,
+		Compression: config.Compression,
+	}
+}
+
+// TLSConfig is what the exporters verify the endpoint with, nil when they send in plaintext or use the defaults
+func (o ExportOptions) TLSConfig() (*tls.Config, error) {
+	if o.Insecure || !o.TLS.IsSet() {
+		return nil, nil
+	}
+	return o.TLS.ClientConfig()
+}
+
+// ExporterOptionFuncs are an exporter package's constructors for the options ExporterOptions sets
+type ExporterOptionFuncs[O any] struct {
+	Headers  func(map[string]string) O
+	Endpoint func(string) O
+	Insecure func() O
+	TLS      func(*tls.Config) O
+	Gzip     func() O
+	// Proxy is set by HTTP exporters, which resolve the proxy of every request
+	Proxy func(func(*http.Request) (*url.URL, error)) O
+	// Dialer is set by gRPC exporters, which only read the proxy from the environment, so a proxy set on the command
+	// line needs its own dialer
+	Dialer func(func(context.Context, string) (net.Conn, error)) O
+}
+
+// ExporterOptions builds the options of an OTLP exporter from the export options
+func ExporterOptions[O any](o ExportOptions, with ExporterOptionFuncs[O]) ([]O, error) {
+	opts := []O{with.Headers(o.Headers), with.Endpoint(o.Endpoint)}
+	if with.Proxy != nil {
+		opts = append(opts, with.Proxy(ProxyFromEnvironment(o.Proxy)))
+	}
+	if with.Dialer != nil && o.Proxy != "" {
+		opts = append(opts, with.Dialer(ProxyDialer(o.Proxy, !o.Insecure)))
+	}
+	conf, err := o.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	if o.Insecure {
+		opts = append(opts, with.Insecure())
+	} else if conf != nil {
+		opts = append(opts, with.TLS(conf))
+	}
+	if o.Compression == CompressionGzip {
+		opts = append(opts, with.Gzip())
+	}
+	return opts, nil
+}
+
+// Compression is how export requests are compressed, empty keeps every exporter's default
+type Compression string
+
+const (
+	CompressionDefault Compression = ""
+	CompressionNone    Compression = "none"
+	CompressionGzip    Compression = "gzip"
+)
+
+func ParseCompression(s string) (Compression, error) {
+	switch c := Compression(s); c {
+	case CompressionDefault, CompressionNone, CompressionGzip:
+		return c, nil
+	}
+	return "", fmt.Errorf("unknown compression %q, must be one of none, gzip", s)
+}
+
+// Temporality is how the metric exporters aggregate sums and histograms over time
+type Temporality string
+
+const (
+	TemporalityCumulative Temporality = "cumulative"
+	TemporalityDelta      Temporality = "delta"
+)
+
+func ParseTemporality(s string) (Temporality, error) {
+	switch t := Temporality(s); t {
+	case TemporalityCumulative, TemporalityDelta:
+		return t, nil
+	}
+	return "", fmt.Errorf("unknown temporality %q, must be one of cumulative, delta", s)
+}
+
+// Selector picks the temporality of every instrument kind. Up-down counters stay cumulative with delta temporality,
+// as the OTLP exporter specification recommends, since their deltas are rarely meaningful on their own.
+func (t Temporality) Selector() sdkmetric.TemporalitySelector {
+	if t != TemporalityDelta {
+		return sdkmetric.DefaultTemporalitySelector
+	}
+	return func(kind sdkmetric.InstrumentKind) metricdata.Temporality {
+		switch kind {
+		case sdkmetric.InstrumentKindUpDownCounter, sdkmetric.InstrumentKindObservableUpDownCounter:
+			return metricdata.CumulativeTemporality
+		}
+		return metricdata.DeltaTemporality
+	}
+}
@@ -0,0 +1,194 @@
+package steps
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// writeCertificate writes a self-signed certificate and its key as PEM files
+func writeCertificate(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "gateway.internal"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+func TestExporterTLS_ClientConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCertificate(t, dir)
+	empty := filepath.Join(dir, "empty.pem")
+	require.NoError(t, os.WriteFile(empty, nil, 0o600))
+
+	tests := []struct {
+		name      string
+		tls       ExporterTLS
+		wantRoots bool
+		wantCerts int
+		wantErr   string
+	}{
+		{
+			name: "server name only",
+			tls:  ExporterTLS{ServerName: "gateway.internal"},
+		},
+		{
+			name:      "private CA and client certificate",
+			tls:       ExporterTLS{CAFile: certFile, CertFile: certFile, KeyFile: keyFile},
+			wantRoots: true,
+			wantCerts: 1,
+		},
+		{
+			name:    "CA bundle without certificates",
+			tls:     ExporterTLS{CAFile: empty},
+			wantErr: "no certificates found in CA bundle " + empty,
+		},
+		{
+			name:    "certificate without key",
+			tls:     ExporterTLS{CertFile: certFile},
+			wantErr: "a client certificate needs both a certificate and a key file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, tt.tls.IsSet())
+			conf, err := tt.tls.ClientConfig()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.tls.ServerName, conf.ServerName)
+			assert.Equal(t, tt.wantRoots, conf.RootCAs != nil)
+			assert.Len(t, conf.Certificates, tt.wantCerts)
+		})
+	}
+}
+
+// namedOptions builds options that are just their names, so tests can tell which options were set
+func namedOptions(withProxy bool) ExporterOptionFuncs[string] {
+	with := ExporterOptionFuncs[string]{
+		Headers:  func(map[string]string) string { return "headers" },
+		Endpoint: func(endpoint string) string { return "endpoint=" + endpoint },
+		Insecure: func() string { return "insecure" },
+		TLS:      func(*tls.Config) string { return "tls" },
+		Gzip:     func() string { return "gzip" },
+	}
+	if withProxy {
+		with.Proxy = func(func(*http.Request) (*url.URL, error)) string { return "proxy" }
+	} else {
+		with.Dialer = func(func(context.Context, string) (net.Conn, error)) string { return "dialer" }
+	}
+	return with
+}
+
+func TestExporterOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options ExportOptions
+		http    bool
+		want    []string
+		wantErr string
+	}{
+		{
+			name:    "http defaults",
+			options: ExportOptions{Endpoint: "ingest.lightstep.com:443"},
+			http:    true,
+			want:    []string{"headers", "endpoint=ingest.lightstep.com:443", "proxy"},
+		},
+		{
+			name:    "grpc only dials through a proxy set on the command line",
+			options: ExportOptions{Endpoint: "ingest.lightstep.com:443"},
+			want:    []string{"headers", "endpoint=ingest.lightstep.com:443"},
+		},
+		{
+			name:    "grpc with a proxy, tls and gzip",
+			options: ExportOptions{Endpoint: "gateway:4317", Proxy: "http://proxy:3128", TLS: ExporterTLS{ServerName: "gateway.internal"}, Compression: CompressionGzip},
+			want:    []string{"headers", "endpoint=gateway:4317", "dialer", "tls", "gzip"},
+		},
+		{
+			name:    "insecure ignores tls",
+			options: ExportOptions{Endpoint: "localhost:4317", Insecure: true, TLS: ExporterTLS{CAFile: "missing.pem"}},
+			want:    []string{"headers", "endpoint=localhost:4317", "insecure"},
+		},
+		{
+			name:    "tls error",
+			options: ExportOptions{Endpoint: "gateway:4317", TLS: ExporterTLS{CAFile: "missing.pem"}},
+			wantErr: "failed to read CA bundle: open missing.pem: no such file or directory",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := ExporterOptions(tt.options, namedOptions(tt.http))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, opts)
+		})
+	}
+}
+
+func TestParseCompression(t *testing.T) {
+	for _, s := range []string{"", "none", "gzip"} {
+		c, err := ParseCompression(s)
+		assert.NoError(t, err)
+		assert.Equal(t, Compression(s), c)
+	}
+	_, err := ParseCompression("zstd")
+	assert.EqualError(t, err, `unknown compression "zstd", must be one of none, gzip`)
+}
+
+func TestTemporality_Selector(t *testing.T) {
+	_, err := ParseTemporality("both")
+	assert.EqualError(t, err, `unknown temporality "both", must be one of cumulative, delta`)
+
+	tests := []struct {
+		temporality string
+		kind        sdkmetric.InstrumentKind
+		want        metricdata.Temporality
+	}{
+		{"cumulative", sdkmetric.InstrumentKindCounter, metricdata.CumulativeTemporality},
+		{"cumulative", sdkmetric.InstrumentKindHistogram, metricdata.CumulativeTemporality},
+		{"delta", sdkmetric.InstrumentKindCounter, metricdata.DeltaTemporality},
+		{"delta", sdkmetric.InstrumentKindHistogram, metricdata.DeltaTemporality},
+		{"delta", sdkmetric.InstrumentKindUpDownCounter, metricdata.CumulativeTemporality},
+	}
+	for _, tt := range tests {
+		temporality, err := ParseTemporality(tt.temporality)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, temporality.Selector()(tt.kind), "%s %v", tt.temporality, tt.kind)
+	}
+}
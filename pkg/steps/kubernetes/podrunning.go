@@ -41,10 +41,12 @@ func (p PodRunning) Run(ctx context.Context, deps *steps.Deps) steps.Results {
 		return steps.NewResults(p, steps.NewFailureResultWithHelp(nil, fmt.Sprintf("no pods matching selector %s running", p.LabelSelector)))
 	}
 	podNames := ""
+	var pods []string
 	for _, item := range operatorPodList.Items {
 		podNames = fmt.Sprintf("%s, %s", item.Name, podNames)
+		pods = append(pods, fmt.Sprintf("%s/%s", item.Namespace, item.Name))
 	}
-	return steps.NewResults(p, steps.NewSuccessfulResult(podNames))
+	return steps.NewResults(p, steps.NewSuccessfulResult(podNames).WithAttribute("pods", pods))
 }
 
 func (p PodRunning) Dependencies(config *steps.Config) []steps.Dependency {
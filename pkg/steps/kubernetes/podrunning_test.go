@@ -75,7 +75,7 @@ func TestPodRunning_Run(t *testing.T) {
 					}),
 				},
 			},
-			want: steps.NewResults(PodRunning{}, steps.NewSuccessfulResult("test, ")),
+			want: steps.NewResults(PodRunning{}, steps.NewSuccessfulResult("test, ").WithAttribute("pods", []string{"somewhere/test"})),
 		},
 	}
 	for _, tt := range tests {
@@ -94,6 +94,7 @@ func TestPodRunning_Run(t *testing.T) {
 				assert.Equal(t, tt.want.Steps()[i].Message(), result.Message())
 				assert.Equal(t, tt.want.Steps()[i].Successful(), result.Successful())
 				assert.Equal(t, tt.want.Steps()[i].ShouldContinue(), result.ShouldContinue())
+				assert.Equal(t, tt.want.Steps()[i].Attributes(), result.Attributes())
 			}
 		})
 	}
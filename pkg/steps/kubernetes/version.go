@@ -27,7 +27,7 @@ func (c Version) Run(ctx context.Context, deps *steps.Deps) steps.Results {
 	if err != nil {
 		return steps.NewResults(c, steps.NewFailureResult(err))
 	}
-	return steps.NewResults(c, steps.NewSuccessfulResult(version.String()))
+	return steps.NewResults(c, steps.NewSuccessfulResult(version.String()).WithAttribute("git_version", version.GitVersion))
 }
 
 func (c Version) Dependencies(config *steps.Config) []steps.Dependency {
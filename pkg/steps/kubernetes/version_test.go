@@ -21,7 +21,7 @@ func TestVersionChecker_Run(t *testing.T) {
 		{
 			name:   "base case",
 			client: fake.NewSimpleClientset(),
-			want:   steps.NewResults(v, steps.NewSuccessfulResult("v0.0.0-master+$Format:%H$")),
+			want:   steps.NewResults(v, steps.NewSuccessfulResult("v0.0.0-master+$Format:%H$").WithAttribute("git_version", "v0.0.0-master+$Format:%H$")),
 		},
 		{
 			name: "no client",
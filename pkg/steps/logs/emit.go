@@ -0,0 +1,57 @@
+package logs
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+
+	"github.com/lightstep/collector-cluster-check/pkg/loopback"
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+	"github.com/lightstep/collector-cluster-check/pkg/steps/dependencies"
+)
+
+type EmitLog struct {
+	endpoint string
+	insecure bool
+}
+
+func NewEmitLog(endpoint string, insecure bool) EmitLog {
+	return EmitLog{endpoint: endpoint, insecure: insecure}
+}
+
+var _ steps.Step = EmitLog{}
+
+const (
+	instrumentation = "collector-cluster-check"
+	Body            = "logChecker.Run"
+)
+
+func (c EmitLog) Name() string {
+	return "Emit log"
+}
+
+func (c EmitLog) Description() string {
+	return "Emits an otel log record"
+}
+
+func (c EmitLog) Run(ctx context.Context, deps *steps.Deps) steps.Results {
+	var record log.Record
+	record.SetTimestamp(time.Now())
+	record.SetSeverity(log.SeverityInfo)
+	record.SetSeverityText("INFO")
+	record.SetBody(log.StringValue(Body))
+	record.AddAttributes(log.String(loopback.StepAttribute, c.Name()))
+	deps.LoggerProvider.Logger(instrumentation).Emit(ctx, record)
+	if deps.LoopbackSent != nil {
+		deps.LoopbackSent.RecordLogRecord(loopback.LogRecord{Body: Body, Attributes: map[string]string{loopback.StepAttribute: c.Name()}})
+	}
+	return steps.NewResults(c, steps.NewSuccessfulResult("emitted log record"))
+}
+
+func (c EmitLog) Dependencies(config *steps.Config) []steps.Dependency {
+	if len(c.endpoint) > 0 {
+		return []steps.Dependency{dependencies.NewCreateLoggerProvider(c.endpoint, c.insecure, config.Http, config.Headers, config.RunID)}
+	}
+	return []steps.Dependency{dependencies.CreateLoggerProviderFromConfig(config)}
+}
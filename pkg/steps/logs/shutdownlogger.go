@@ -0,0 +1,42 @@
+package logs
+
+import (
+	"context"
+
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+	"github.com/lightstep/collector-cluster-check/pkg/steps/dependencies"
+)
+
+type ShutdownLogger struct {
+	endpoint string
+	insecure bool
+}
+
+func NewShutdownLogger(endpoint string, insecure bool) *ShutdownLogger {
+	return &ShutdownLogger{endpoint: endpoint, insecure: insecure}
+}
+
+var _ steps.Step = ShutdownLogger{}
+
+func (c ShutdownLogger) Name() string {
+	return "ShutdownLogger"
+}
+
+func (c ShutdownLogger) Description() string {
+	return "Shut down and flush the open logger provider"
+}
+
+func (c ShutdownLogger) Run(ctx context.Context, deps *steps.Deps) steps.Results {
+	err := deps.LoggerProvider.ForceFlush(ctx)
+	if err == nil {
+		err = deps.LoggerProvider.Shutdown(ctx)
+	}
+	return steps.NewResults(c, steps.ShutdownResults(err, deps.LogExportErrors, "shutdown logger provider")...)
+}
+
+func (c ShutdownLogger) Dependencies(config *steps.Config) []steps.Dependency {
+	if len(c.endpoint) > 0 {
+		return []steps.Dependency{dependencies.NewCreateLoggerProvider(c.endpoint, c.insecure, config.Http, config.Headers, config.RunID)}
+	}
+	return []steps.Dependency{dependencies.CreateLoggerProviderFromConfig(config)}
+}
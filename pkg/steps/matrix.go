@@ -0,0 +1,32 @@
+package steps
+
+import (
+	"fmt"
+
+	"github.com/lightstep/collector-cluster-check/pkg/otlp"
+)
+
+// Security is whether the transport matrix connects with TLS or in plaintext
+type Security string
+
+const (
+	SecurityTLS      Security = "tls"
+	SecurityInsecure Security = "insecure"
+)
+
+func ParseSecurity(s string) (Security, error) {
+	switch sec := Security(s); sec {
+	case SecurityTLS, SecurityInsecure:
+		return sec, nil
+	}
+	return "", fmt.Errorf("unknown security %q, must be one of tls, insecure", s)
+}
+
+// MatrixCell is a transport and security setting the transport matrix sends every signal with
+type MatrixCell struct {
+	Transport otlp.Transport
+	Security  Security
+	Client    *otlp.Client
+	// Configured is whether the exporters use the same transport and security setting
+	Configured bool
+}
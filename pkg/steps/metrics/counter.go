@@ -4,6 +4,10 @@ import (
 	"context"
 	"fmt"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/lightstep/collector-cluster-check/pkg/loopback"
 	"github.com/lightstep/collector-cluster-check/pkg/steps"
 	"github.com/lightstep/collector-cluster-check/pkg/steps/dependencies"
 )
@@ -21,7 +25,7 @@ var _ steps.Step = CreateCounter{}
 
 const (
 	instrumentation = "collector-cluster-check"
-	metricName      = "collector.check.alive"
+	MetricName      = "collector.check.alive"
 )
 
 func (c CreateCounter) Name() string {
@@ -33,17 +37,20 @@ func (c CreateCounter) Description() string {
 }
 
 func (c CreateCounter) Run(ctx context.Context, deps *steps.Deps) steps.Results {
-	counter, err := deps.MeterProvider.Meter(instrumentation).Int64Counter(metricName)
+	counter, err := deps.MeterProvider.Meter(instrumentation).Int64Counter(MetricName)
 	if err != nil {
 		return steps.NewResults(c, steps.NewFailureResult(err))
 	}
-	counter.Add(ctx, 1)
-	return steps.NewResults(c, steps.NewSuccessfulResult(fmt.Sprintf("incremented counter %s", metricName)))
+	counter.Add(ctx, 1, metric.WithAttributes(attribute.String(loopback.StepAttribute, c.Name())))
+	if deps.LoopbackSent != nil {
+		deps.LoopbackSent.RecordMetric(loopback.Metric{Name: MetricName, Value: 1, Attributes: map[string]string{loopback.StepAttribute: c.Name()}})
+	}
+	return steps.NewResults(c, steps.NewSuccessfulResult(fmt.Sprintf("incremented counter %s", MetricName)))
 }
 
 func (c CreateCounter) Dependencies(config *steps.Config) []steps.Dependency {
 	if len(c.endpoint) > 0 {
-		return []steps.Dependency{dependencies.NewCreateMeterProvider(c.endpoint, c.insecure, config.Http, config.Token)}
+		return []steps.Dependency{dependencies.NewCreateMeterProvider(c.endpoint, c.insecure, config.Http, config.Headers, config.RunID)}
 	}
 	return []steps.Dependency{dependencies.CreateMeterProviderFromConfig(config)}
 }
@@ -0,0 +1,198 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+
+	"github.com/lightstep/collector-cluster-check/pkg/otlp"
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+	"github.com/lightstep/collector-cluster-check/pkg/steps/dependencies"
+)
+
+// instrument records a measurement with one kind of instrument, so that every data shape can be exported on its own
+type instrument struct {
+	name   string
+	kind   string
+	record func(ctx context.Context, m metric.Meter) error
+}
+
+var instruments = []instrument{
+	{
+		name: "collector.check.counter",
+		kind: "counter",
+		record: func(ctx context.Context, m metric.Meter) error {
+			c, err := m.Int64Counter("collector.check.counter")
+			if err == nil {
+				c.Add(ctx, 1)
+			}
+			return err
+		},
+	},
+	{
+		name: "collector.check.updowncounter",
+		kind: "up-down counter",
+		record: func(ctx context.Context, m metric.Meter) error {
+			c, err := m.Int64UpDownCounter("collector.check.updowncounter")
+			if err == nil {
+				c.Add(ctx, -1)
+			}
+			return err
+		},
+	},
+	{
+		name: "collector.check.gauge",
+		kind: "gauge",
+		record: func(ctx context.Context, m metric.Meter) error {
+			g, err := m.Float64Gauge("collector.check.gauge")
+			if err == nil {
+				g.Record(ctx, 0.5)
+			}
+			return err
+		},
+	},
+	{
+		name: "collector.check.histogram",
+		kind: "histogram",
+		record: func(ctx context.Context, m metric.Meter) error {
+			h, err := m.Float64Histogram("collector.check.histogram", metric.WithExplicitBucketBoundaries(1, 10, 100))
+			if err == nil {
+				h.Record(ctx, 5)
+				h.Record(ctx, 50)
+			}
+			return err
+		},
+	},
+	{
+		name: "collector.check.exponential_histogram",
+		kind: "exponential histogram",
+		record: func(ctx context.Context, m metric.Meter) error {
+			h, err := m.Float64Histogram("collector.check.exponential_histogram")
+			if err == nil {
+				h.Record(ctx, 5)
+				h.Record(ctx, 50)
+			}
+			return err
+		},
+	},
+}
+
+// exponentialHistograms aggregates the exponential histogram instrument as one, the SDK defaults to explicit buckets
+var exponentialHistograms = sdkmetric.NewView(
+	sdkmetric.Instrument{Name: "collector.check.exponential_histogram"},
+	sdkmetric.Stream{Aggregation: sdkmetric.AggregationBase2ExponentialHistogram{MaxSize: 160, MaxScale: 20}},
+)
+
+type ExportInstruments struct {
+	endpoint string
+	insecure bool
+}
+
+func NewExportInstruments(endpoint string, insecure bool) ExportInstruments {
+	return ExportInstruments{endpoint: endpoint, insecure: insecure}
+}
+
+var _ steps.Step = ExportInstruments{}
+
+func (c ExportInstruments) Name() string {
+	return "ExportInstruments"
+}
+
+func (c ExportInstruments) Description() string {
+	return "Exports a counter, an up-down counter, a gauge, a histogram and an exponential histogram one at a time"
+}
+
+func (c ExportInstruments) Run(ctx context.Context, deps *steps.Deps) steps.Results {
+	if deps.MetricExporter == nil {
+		return steps.NewResults(c, steps.NewFailureResultWithHelp(nil, "metric exporter not set"))
+	}
+	results := make([]steps.Result, len(instruments))
+	for i, inst := range instruments {
+		results[i] = c.export(ctx, deps.MetricExporter, deps.MetricResource, inst).WithAttribute("instrument", inst.name)
+	}
+	return steps.NewResults(c, results...)
+}
+
+// export collects a single instrument with the exporter's temporality and aggregation, then exports it
+func (c ExportInstruments) export(ctx context.Context, exp sdkmetric.Exporter, res *resource.Resource, inst instrument) steps.Result {
+	reader := sdkmetric.NewManualReader(
+		sdkmetric.WithTemporalitySelector(exp.Temporality),
+		sdkmetric.WithAggregationSelector(exp.Aggregation),
+	)
+	opts := []sdkmetric.Option{sdkmetric.WithReader(reader), sdkmetric.WithView(exponentialHistograms)}
+	if res != nil {
+		opts = append(opts, sdkmetric.WithResource(res))
+	}
+	mp := sdkmetric.NewMeterProvider(opts...)
+	defer mp.Shutdown(context.WithoutCancel(ctx))
+
+	if err := inst.record(ctx, mp.Meter(instrumentation)); err != nil {
+		return steps.NewErrorResult(err)
+	}
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		return steps.NewErrorResult(err)
+	}
+	shape, temporality := describe(rm)
+	label := shape
+	if temporality != "" {
+		label = temporality + " " + shape
+	}
+	var r steps.Result
+	if err := exp.Export(ctx, &rm); err != nil {
+		r = steps.NewFailureResultWithHelp(fmt.Errorf("failed to export %s: %w", label, err), otlp.Diagnose(err).Help)
+	} else {
+		r = steps.NewSuccessfulResult(fmt.Sprintf("exported %s", label))
+	}
+	r = r.WithAttribute("kind", inst.kind).WithAttribute("data", shape)
+	if temporality != "" {
+		r = r.WithAttribute("temporality", temporality)
+	}
+	return r
+}
+
+// describe names the data shape and temporality of the only metric that was collected, gauges have no temporality
+func describe(rm metricdata.ResourceMetrics) (string, string) {
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch d := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				return "sum", temporality(d.Temporality)
+			case metricdata.Sum[float64]:
+				return "sum", temporality(d.Temporality)
+			case metricdata.Gauge[int64], metricdata.Gauge[float64]:
+				return "gauge", ""
+			case metricdata.Histogram[float64]:
+				return "histogram", temporality(d.Temporality)
+			case metricdata.Histogram[int64]:
+				return "histogram", temporality(d.Temporality)
+			case metricdata.ExponentialHistogram[float64]:
+				return "exponential histogram", temporality(d.Temporality)
+			case metricdata.ExponentialHistogram[int64]:
+				return "exponential histogram", temporality(d.Temporality)
+			}
+		}
+	}
+	return "nothing", ""
+}
+
+func temporality(t metricdata.Temporality) string {
+	switch t {
+	case metricdata.DeltaTemporality:
+		return "delta"
+	case metricdata.CumulativeTemporality:
+		return "cumulative"
+	}
+	return ""
+}
+
+func (c ExportInstruments) Dependencies(config *steps.Config) []steps.Dependency {
+	if len(c.endpoint) > 0 {
+		return []steps.Dependency{dependencies.NewCreateMetricExporter(c.endpoint, c.insecure, config.Http, config.Headers, config.RunID)}
+	}
+	return []steps.Dependency{dependencies.CreateMetricExporterFromConfig(config)}
+}
@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+
+	"github.com/lightstep/collector-cluster-check/pkg/loopback"
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+// fakeExporter rejects exponential histograms, like backends that don't support them yet
+type fakeExporter struct {
+	temporality steps.Temporality
+	// resources are the resources of every export, when set
+	resources *[]*resource.Resource
+}
+
+func (e fakeExporter) Temporality(k sdkmetric.InstrumentKind) metricdata.Temporality {
+	return e.temporality.Selector()(k)
+}
+
+func (e fakeExporter) Aggregation(k sdkmetric.InstrumentKind) sdkmetric.Aggregation {
+	return sdkmetric.DefaultAggregationSelector(k)
+}
+
+func (e fakeExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	if e.resources != nil {
+		*e.resources = append(*e.resources, rm.Resource)
+	}
+	if shape, _ := describe(*rm); shape == "exponential histogram" {
+		return fmt.Errorf("unsupported metric type")
+	}
+	return nil
+}
+
+func (e fakeExporter) ForceFlush(ctx context.Context) error { return nil }
+
+func (e fakeExporter) Shutdown(ctx context.Context) error { return nil }
+
+func TestExportInstruments_Run(t *testing.T) {
+	tests := []struct {
+		name         string
+		temporality  steps.Temporality
+		wantStatuses []steps.Status
+		wantMessages []string
+	}{
+		{
+			name:         "cumulative",
+			temporality:  steps.TemporalityCumulative,
+			wantStatuses: []steps.Status{steps.StatusPass, steps.StatusPass, steps.StatusPass, steps.StatusPass, steps.StatusFail},
+			wantMessages: []string{
+				"exported cumulative sum",
+				"exported cumulative sum",
+				"exported gauge",
+				"exported cumulative histogram",
+				"failed to export cumulative exponential histogram: unsupported metric type",
+			},
+		},
+		{
+			name:         "delta",
+			temporality:  steps.TemporalityDelta,
+			wantStatuses: []steps.Status{steps.StatusPass, steps.StatusPass, steps.StatusPass, steps.StatusPass, steps.StatusFail},
+			wantMessages: []string{
+				"exported delta sum",
+				"exported cumulative sum",
+				"exported gauge",
+				"exported delta histogram",
+				"failed to export delta exponential histogram: unsupported metric type",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := &steps.Deps{MetricExporter: fakeExporter{temporality: tt.temporality}}
+			results := ExportInstruments{}.Run(context.Background(), deps).Steps()
+			var statuses []steps.Status
+			var messages []string
+			for _, r := range results {
+				statuses = append(statuses, r.Status())
+				if r.Err() != nil {
+					messages = append(messages, r.Err().Error())
+				} else {
+					messages = append(messages, r.Message())
+				}
+			}
+			assert.Equal(t, tt.wantStatuses, statuses)
+			assert.Equal(t, tt.wantMessages, messages)
+		})
+	}
+}
+
+func TestExportInstruments_RunResource(t *testing.T) {
+	res := resource.NewSchemaless(attribute.String(loopback.RunIDAttribute, "run-1"))
+	var exported []*resource.Resource
+	deps := &steps.Deps{MetricExporter: fakeExporter{resources: &exported}, MetricResource: res}
+	ExportInstruments{}.Run(context.Background(), deps)
+	assert.NotEmpty(t, exported)
+	for _, r := range exported {
+		assert.Equal(t, res, r)
+	}
+}
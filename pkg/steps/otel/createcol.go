@@ -7,6 +7,8 @@ import (
 
 	apiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/lightstep/collector-cluster-check/pkg/steps"
 	"github.com/lightstep/collector-cluster-check/pkg/steps/dependencies"
@@ -25,15 +27,28 @@ func (c CreateCollector) Description() string {
 }
 
 func (c CreateCollector) Run(ctx context.Context, deps *steps.Deps) steps.Results {
-	res, err := deps.DynamicClient.Resource(steps.ColRes).Namespace(apiv1.NamespaceDefault).Create(ctx, deps.OtelColConfig, metav1.CreateOptions{})
+	r := c.create(ctx, deps, steps.ColRes, deps.OtelColConfig)
+	if deps.CollectorWorkload == nil || r.Status() == steps.StatusFail {
+		return steps.NewResults(c, r)
+	}
+	// the sidecar is only injected into pods created after the collector, so the workload comes second
+	return steps.NewResults(c, r, c.create(ctx, deps, steps.PodRes, deps.CollectorWorkload))
+}
+
+func (c CreateCollector) create(ctx context.Context, deps *steps.Deps, resource schema.GroupVersionResource, obj *unstructured.Unstructured) steps.Result {
+	res, err := deps.DynamicClient.Resource(resource).Namespace(apiv1.NamespaceDefault).Create(ctx, obj, metav1.CreateOptions{})
 	if err != nil && strings.Contains(err.Error(), "already exists") {
-		return steps.NewResults(c, steps.NewAcceptableFailureResult(err))
+		return steps.NewAcceptableFailureResult(err)
 	} else if err != nil {
-		return steps.NewResults(c, steps.NewFailureResult(err))
+		return steps.NewFailureResult(err)
 	}
-	return steps.NewResults(c, steps.NewSuccessfulResult(fmt.Sprintf("%s has been created", res.GetName())))
+	return steps.NewSuccessfulResult(fmt.Sprintf("%s has been created", res.GetName()))
 }
 
 func (c CreateCollector) Dependencies(config *steps.Config) []steps.Dependency {
-	return []steps.Dependency{dependencies.NewCollectorConfigFromConfig(config), dependencies.NewCreateDynamicClientFromConfig(config)}
+	deps := []steps.Dependency{dependencies.NewCollectorConfigFromConfig(config), dependencies.NewCreateDynamicClientFromConfig(config)}
+	if config.TLS.CAFile != "" || config.TLS.CertFile != "" {
+		deps = append(deps, dependencies.NewCreateTLSSecretFromConfig(config))
+	}
+	return deps
 }
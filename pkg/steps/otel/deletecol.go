@@ -5,7 +5,9 @@ import (
 	"fmt"
 
 	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/lightstep/collector-cluster-check/pkg/steps"
 	"github.com/lightstep/collector-cluster-check/pkg/steps/dependencies"
@@ -24,11 +26,22 @@ func (c DeleteCollector) Description() string {
 }
 
 func (c DeleteCollector) Run(ctx context.Context, deps *steps.Deps) steps.Results {
-	err := deps.DynamicClient.Resource(steps.ColRes).Namespace(apiv1.NamespaceDefault).Delete(ctx, deps.OtelColConfig.GetName(), metav1.DeleteOptions{})
-	if err != nil {
-		return steps.NewResults(c, steps.NewFailureResult(err))
+	results := []steps.Result{c.delete(ctx, deps, steps.ColRes, deps.OtelColConfig.GetName())}
+	if deps.CollectorWorkload != nil {
+		results = append(results, c.delete(ctx, deps, steps.PodRes, deps.CollectorWorkload.GetName()))
 	}
-	return steps.NewResults(c, steps.NewSuccessfulResult(fmt.Sprintf("%s has been deleted", deps.OtelColConfig.GetName())))
+	return steps.NewResults(c, results...)
+}
+
+func (c DeleteCollector) delete(ctx context.Context, deps *steps.Deps, resource schema.GroupVersionResource, name string) steps.Result {
+	err := deps.DynamicClient.Resource(resource).Namespace(apiv1.NamespaceDefault).Delete(ctx, name, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		// DeleteCollector runs as a finalizer, so the collector may never have been created
+		return steps.NewSuccessfulResult(fmt.Sprintf("%s not found, nothing to delete", name))
+	} else if err != nil {
+		return steps.NewFailureResult(err)
+	}
+	return steps.NewSuccessfulResult(fmt.Sprintf("%s has been deleted", name))
 }
 
 func (c DeleteCollector) Dependencies(config *steps.Config) []steps.Dependency {
@@ -0,0 +1,58 @@
+package otel
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lightstep/collector-cluster-check/pkg/otlp"
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+	"github.com/lightstep/collector-cluster-check/pkg/steps/dependencies"
+)
+
+// probeTransports are tried in order, every one of them is reported
+var probeTransports = []otlp.Transport{otlp.TransportGRPC, otlp.TransportHTTPProtobuf}
+
+type ExportProbe struct{}
+
+var _ steps.Step = ExportProbe{}
+
+func (c ExportProbe) Name() string {
+	return "ExportProbe"
+}
+
+func (c ExportProbe) Description() string {
+	return "Sends an empty OTLP export request over gRPC and HTTP and explains how the endpoint answered"
+}
+
+func (c ExportProbe) Run(ctx context.Context, deps *steps.Deps) steps.Results {
+	if deps.OTLPClient == nil {
+		return steps.NewResults(c, steps.NewFailureResultWithHelp(nil, "OTLP client not set"))
+	}
+	results := make([]steps.Result, len(probeTransports))
+	for i, transport := range probeTransports {
+		results[i] = c.probe(ctx, deps.OTLPClient, transport, transport == deps.OTLPTransport)
+	}
+	return steps.NewResults(c, results...)
+}
+
+// probe fails when the transport the exporters use doesn't work, the other transport only warrants a warning
+func (c ExportProbe) probe(ctx context.Context, client *otlp.Client, transport otlp.Transport, configured bool) steps.Result {
+	err := client.Export(ctx, transport)
+	diagnosis := otlp.Diagnose(err)
+	var r steps.Result
+	switch {
+	case err == nil:
+		r = steps.NewSuccessfulResult(fmt.Sprintf("%s export to %s accepted", transport, client.Endpoint()))
+	case diagnosis.Transient() || !configured:
+		r = steps.NewAcceptableFailureResultWithHelp(err, fmt.Sprintf("%s: %s", transport, diagnosis.Help))
+	default:
+		r = steps.NewFailureResultWithHelp(err, fmt.Sprintf("%s: %s", transport, diagnosis.Help))
+	}
+	return r.WithAttribute("transport", string(transport)).
+		WithAttribute("configured", configured).
+		WithAttribute("problem", string(diagnosis.Problem))
+}
+
+func (c ExportProbe) Dependencies(config *steps.Config) []steps.Dependency {
+	return []steps.Dependency{dependencies.NewCreateOTLPClientFromConfig(config)}
+}
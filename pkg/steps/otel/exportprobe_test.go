@@ -0,0 +1,70 @@
+package otel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lightstep/collector-cluster-check/pkg/otlp"
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+func TestExportProbe_Run(t *testing.T) {
+	// an OTLP/HTTP only endpoint, gRPC requests to it fail
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("lightstep-access-token") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+	endpoint := strings.TrimPrefix(server.URL, "http://")
+
+	tests := []struct {
+		name         string
+		token        string
+		transport    otlp.Transport
+		wantStatuses []steps.Status
+		wantProblems []string
+	}{
+		{
+			name:         "configured transport works",
+			token:        "secret",
+			transport:    otlp.TransportHTTPProtobuf,
+			wantStatuses: []steps.Status{steps.StatusWarning, steps.StatusPass},
+			wantProblems: []string{"unavailable", "none"},
+		},
+		{
+			name:         "configured transport doesn't work",
+			token:        "secret",
+			transport:    otlp.TransportGRPC,
+			wantStatuses: []steps.Status{steps.StatusFail, steps.StatusPass},
+			wantProblems: []string{"unavailable", "none"},
+		},
+		{
+			name:         "wrong token",
+			token:        "wrong",
+			transport:    otlp.TransportHTTPProtobuf,
+			wantStatuses: []steps.Status{steps.StatusWarning, steps.StatusFail},
+			wantProblems: []string{"unavailable", "unauthenticated"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := &steps.Deps{
+				OTLPClient:    otlp.NewClient(endpoint, true, map[string]string{"lightstep-access-token": tt.token}),
+				OTLPTransport: tt.transport,
+			}
+			got := ExportProbe{}.Run(context.Background(), deps)
+			require.Len(t, got.Steps(), 2)
+			for i, r := range got.Steps() {
+				assert.Equal(t, tt.wantStatuses[i], r.Status())
+				assert.Equal(t, tt.wantProblems[i], r.Attributes()["problem"])
+			}
+		})
+	}
+}
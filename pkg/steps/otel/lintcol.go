@@ -0,0 +1,79 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/lightstep/collector-cluster-check/pkg/colconfig"
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+	"github.com/lightstep/collector-cluster-check/pkg/steps/dependencies"
+)
+
+// LintCollector checks the collector config before the collector is created, so a config the collector can't start
+// with fails right away instead of when PodWatcher gives up on a crash looping pod
+type LintCollector struct{}
+
+var _ steps.Step = LintCollector{}
+
+func (c LintCollector) Name() string {
+	return "LintCollector"
+}
+
+func (c LintCollector) Description() string {
+	return "Checks the collector config's pipelines, env vars, ports and processors before creating the collector"
+}
+
+func (c LintCollector) Run(ctx context.Context, deps *steps.Deps) steps.Results {
+	if deps.OtelColConfig == nil {
+		return steps.NewResults(c, steps.NewFailureResultWithHelp(nil, "collector config not set"))
+	}
+	// the config holds values straight from YAML, e.g. ints, which can't be deep copied as JSON
+	value, _, err := unstructured.NestedFieldNoCopy(deps.OtelColConfig.Object, "spec", "config")
+	if err != nil {
+		return steps.NewResults(c, steps.NewErrorResult(err))
+	}
+	config, ok := value.(map[string]interface{})
+	if !ok {
+		return steps.NewResults(c, steps.NewFailureResultWithHelp(fmt.Errorf("collector has no config"), "set the collector config's receivers, exporters and service"))
+	}
+	findings := colconfig.Lint(config, envNames(deps.OtelColConfig))
+	if len(findings) == 0 {
+		return steps.NewResults(c, steps.NewSuccessfulResult("collector config has no problems"))
+	}
+	results := make([]steps.Result, len(findings))
+	for i, f := range findings {
+		err := errors.New(f.Message)
+		r := steps.NewAcceptableFailureResultWithHelp(err, f.Help)
+		if f.Fatal {
+			r = steps.NewFailureResultWithHelp(err, f.Help)
+		}
+		results[i] = r.WithAttribute("rule", string(f.Rule))
+	}
+	return steps.NewResults(c, results...)
+}
+
+// envNames returns the names of the collector's env vars, the ones read from Secrets included, however the CR was built
+func envNames(col *unstructured.Unstructured) []string {
+	var names []string
+	value, _, _ := unstructured.NestedFieldNoCopy(col.Object, "spec", "env")
+	switch env := value.(type) {
+	case []map[string]interface{}:
+		for _, e := range env {
+			names = append(names, fmt.Sprint(e["name"]))
+		}
+	case []interface{}:
+		for _, e := range env {
+			if m, ok := e.(map[string]interface{}); ok {
+				names = append(names, fmt.Sprint(m["name"]))
+			}
+		}
+	}
+	return names
+}
+
+func (c LintCollector) Dependencies(config *steps.Config) []steps.Dependency {
+	return []steps.Dependency{dependencies.NewCollectorConfigFromConfig(config)}
+}
@@ -0,0 +1,104 @@
+package otel
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+	"github.com/lightstep/collector-cluster-check/pkg/steps/dependencies"
+)
+
+func TestLintCollector_Run(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       string
+		env          []steps.CollectorEnv
+		wantStatuses []steps.Status
+		wantRules    []string
+	}{
+		{
+			name:         "embedded config",
+			wantStatuses: []steps.Status{steps.StatusPass},
+		},
+		{
+			name: "unset env var and no batch",
+			config: `
+receivers:
+  otlp:
+    protocols:
+      grpc: {}
+processors:
+  memory_limiter:
+    check_interval: 1s
+    limit_percentage: 80
+exporters:
+  otlp:
+    endpoint: ${DESTINATION}
+    headers:
+      authorization: ${env:LS_TOKEN}
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      processors: [memory_limiter]
+      exporters: [otlp]
+`,
+			wantStatuses: []steps.Status{steps.StatusWarning, steps.StatusWarning},
+			wantRules:    []string{"unset-env", "batch"},
+		},
+		{
+			name: "env var from a secret",
+			config: `
+receivers:
+  otlp:
+    protocols:
+      grpc: {}
+processors:
+  memory_limiter:
+    check_interval: 1s
+    limit_percentage: 80
+  batch: {}
+exporters:
+  otlp:
+    endpoint: ${DESTINATION}
+    headers:
+      authorization: ${env:LS_TOKEN}
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      processors: [memory_limiter, batch]
+      exporters: [otlp]
+`,
+			env:          []steps.CollectorEnv{{Name: "LS_TOKEN", SecretName: "lightstep", SecretKey: "token"}},
+			wantStatuses: []steps.Status{steps.StatusPass},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := &steps.Config{Endpoint: "ingest.lightstep.com:443", CollectorEnv: tt.env}
+			if tt.config != "" {
+				conf.CollectorConfigFile = filepath.Join(t.TempDir(), "collector.yaml")
+				require.NoError(t, os.WriteFile(conf.CollectorConfigFile, []byte(tt.config), 0o600))
+			}
+			deps := steps.NewDependencies()
+			option, result := dependencies.NewCollectorConfigFromConfig(conf).Run(context.Background(), deps)
+			require.Equal(t, steps.StatusPass, result.Status())
+			option(deps)
+
+			got := LintCollector{}.Run(context.Background(), deps)
+			require.Len(t, got.Steps(), len(tt.wantStatuses))
+			for i, r := range got.Steps() {
+				assert.Equal(t, tt.wantStatuses[i], r.Status(), r.Err())
+				if tt.wantRules != nil {
+					assert.Equal(t, tt.wantRules[i], r.Attributes()["rule"])
+				}
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -25,10 +26,15 @@ func (c QueryCollector) Description() string {
 }
 
 func (c QueryCollector) Run(ctx context.Context, deps *steps.Deps) steps.Results {
-	r, err := http.Get("http://localhost:8888/metrics")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost:8888/metrics", nil)
 	if err != nil {
 		return steps.NewResults(c, steps.NewFailureResult(err))
 	}
+	r, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return steps.NewResults(c, steps.NewFailureResult(err))
+	}
+	defer r.Body.Close()
 	data, err := io.ReadAll(r.Body)
 	if err != nil {
 		return steps.NewResults(c, steps.NewFailureResult(err))
@@ -36,39 +42,85 @@ func (c QueryCollector) Run(ctx context.Context, deps *steps.Deps) steps.Results
 	return c.processMetrics(string(data))
 }
 
+// expectedTelemetry is what the inflight steps send through the collector, in the order it's reported
+var expectedTelemetry = []string{"spans", "metric_points", "log_records"}
+
+// exporterMetric matches the collector's exporter counters, e.g. otelcol_exporter_send_failed_spans{exporter="otlp"} 0
+var exporterMetric = regexp.MustCompile(`(?m)^otelcol_exporter_(sent|send_failed)_(\w+)(\{[^}]*\})?\s+(\S+)`)
+
+// exporterLabel is the name of the exporter a counter is about
+var exporterLabel = regexp.MustCompile(`exporter="([^"]*)"`)
+
+// localExporters don't send to the backend, so they can't tell whether it accepted the telemetry
+var localExporters = map[string]bool{"debug": true, "logging": true, "file": true, "nop": true}
+
+// isBackendExporter is whether the exporter sends to the backend rather than to the collector's log or the loopback
+// receiver
+func isBackendExporter(name string) bool {
+	kind, id, _ := strings.Cut(name, "/")
+	return !localExporters[kind] && id != "loopback"
+}
+
+// exporterCounts are an exporter's sent and failed items of a telemetry type
+type exporterCounts struct {
+	sent, failed float64
+}
+
 func (c QueryCollector) processMetrics(metrics string) steps.Results {
-	// maps from telemetry type to success ratio
 	var toReturn []steps.Result
-	successMap := map[string]int{}
-	failMap := map[string]int{}
-	groups := regexp.MustCompile(`otelcol_exporter_sen[td]_(.*)\{.*([0-9]+)`).FindAllStringSubmatch(metrics, -1)
-	for _, m := range groups {
-		name, count := m[1], m[2]
-		i, err := strconv.Atoi(count)
+	// maps from telemetry type to the backend exporters' counts
+	counts := map[string]map[string]*exporterCounts{}
+	for _, m := range exporterMetric.FindAllStringSubmatch(metrics, -1) {
+		kind, telemetry, labels, value := m[1], m[2], m[3], m[4]
+		var exporter string
+		if l := exporterLabel.FindStringSubmatch(labels); l != nil {
+			exporter = l[1]
+		}
+		if !isBackendExporter(exporter) {
+			continue
+		}
+		count, err := strconv.ParseFloat(value, 64)
 		if err != nil {
 			return steps.NewResults(c, steps.NewAcceptableFailureResult(err))
 		}
-		failureGroup := strings.Split(name, "failed_")
-		// looking at a failed metric
-		if len(failureGroup) == 2 {
-			failMap[failureGroup[1]] = i
-		} else {
-			successMap[name] = i
+		if counts[telemetry] == nil {
+			counts[telemetry] = map[string]*exporterCounts{}
 		}
-	}
-	for telemetry, count := range successMap {
-		if failMap[telemetry] > count {
-			toReturn = append(toReturn, steps.NewFailureResult(fmt.Errorf("collector failed to send %s", telemetry)))
+		if counts[telemetry][exporter] == nil {
+			counts[telemetry][exporter] = &exporterCounts{}
+		}
+		if kind == "send_failed" {
+			counts[telemetry][exporter].failed += count
 		} else {
-			toReturn = append(toReturn, steps.NewSuccessfulResult(fmt.Sprintf("sent %d %s", count, telemetry)))
+			counts[telemetry][exporter].sent += count
 		}
 	}
-	if len(toReturn) == 0 {
-		toReturn = append(toReturn, steps.NewAcceptableFailureResultWithHelp(nil, "no telemetry metrics found"))
+	for _, telemetry := range expectedTelemetry {
+		toReturn = append(toReturn, c.telemetryResult(telemetry, counts[telemetry]))
 	}
 	return steps.NewResults(c, toReturn...)
 }
 
+// telemetryResult fails when a backend exporter failed to send any of the telemetry or none of them sent it
+func (c QueryCollector) telemetryResult(telemetry string, exporters map[string]*exporterCounts) steps.Result {
+	names := make([]string, 0, len(exporters))
+	for name := range exporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var sent float64
+	for _, name := range names {
+		if failed := exporters[name].failed; failed > 0 {
+			return steps.NewFailureResult(fmt.Errorf("collector failed to send %.0f %s with exporter %s", failed, telemetry, name))
+		}
+		sent += exporters[name].sent
+	}
+	if sent == 0 {
+		return steps.NewFailureResultWithHelp(fmt.Errorf("collector sent no %s", telemetry), "check that the collector has a pipeline for this signal and the debug exporter logs")
+	}
+	return steps.NewSuccessfulResult(fmt.Sprintf("sent %.0f %s", sent, telemetry))
+}
+
 func (c QueryCollector) Dependencies(config *steps.Config) []steps.Dependency {
 	return []steps.Dependency{}
 }
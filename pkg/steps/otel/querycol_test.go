@@ -0,0 +1,84 @@
+package otel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+func TestQueryCollector_processMetrics(t *testing.T) {
+	tests := []struct {
+		name         string
+		metrics      string
+		wantStatuses []steps.Status
+		wantMessages []string
+	}{
+		{
+			name: "every signal sent",
+			metrics: `# HELP otelcol_exporter_sent_spans Number of spans successfully sent to destination.
+# TYPE otelcol_exporter_sent_spans counter
+otelcol_exporter_sent_spans{exporter="debug",service_instance_id="abc"} 1
+otelcol_exporter_sent_spans{exporter="otlp",service_instance_id="abc"} 1
+otelcol_exporter_send_failed_spans{exporter="otlp",service_instance_id="abc"} 0
+otelcol_exporter_sent_metric_points{exporter="otlp",service_instance_id="abc"} 12
+otelcol_exporter_send_failed_metric_points{exporter="otlp",service_instance_id="abc"} 0
+otelcol_exporter_sent_log_records{exporter="otlp",service_instance_id="abc"} 1
+otelcol_exporter_send_failed_log_records{exporter="otlp",service_instance_id="abc"} 0
+`,
+			wantStatuses: []steps.Status{steps.StatusPass, steps.StatusPass, steps.StatusPass},
+			wantMessages: []string{"sent 1 spans", "sent 12 metric_points", "sent 1 log_records"},
+		},
+		{
+			name: "local exporters hide nothing",
+			metrics: `otelcol_exporter_sent_spans{exporter="debug"} 1
+otelcol_exporter_sent_spans{exporter="otlp/loopback"} 1
+otelcol_exporter_send_failed_spans{exporter="otlp"} 1
+otelcol_exporter_sent_metric_points{exporter="debug"} 3
+otelcol_exporter_sent_metric_points{exporter="otlp"} 0
+otelcol_exporter_sent_log_records{exporter="otlp"} 2
+otelcol_exporter_sent_log_records{exporter="otlphttp/backup"} 2
+otelcol_exporter_send_failed_log_records{exporter="otlphttp/backup"} 1
+`,
+			wantStatuses: []steps.Status{steps.StatusFail, steps.StatusFail, steps.StatusFail},
+			wantMessages: []string{
+				"collector failed to send 1 spans with exporter otlp",
+				"collector sent no metric_points",
+				"collector failed to send 1 log_records with exporter otlphttp/backup",
+			},
+		},
+		{
+			name: "logs missing and spans failing",
+			metrics: `otelcol_exporter_sent_spans{exporter="otlp"} 1
+otelcol_exporter_send_failed_spans{exporter="otlp"} 4
+otelcol_exporter_sent_metric_points{exporter="otlp"} 3
+`,
+			wantStatuses: []steps.Status{steps.StatusFail, steps.StatusPass, steps.StatusFail},
+			wantMessages: []string{"collector failed to send 4 spans with exporter otlp", "sent 3 metric_points", "collector sent no log_records"},
+		},
+		{
+			name:         "no telemetry",
+			metrics:      "",
+			wantStatuses: []steps.Status{steps.StatusFail, steps.StatusFail, steps.StatusFail},
+			wantMessages: []string{"collector sent no spans", "collector sent no metric_points", "collector sent no log_records"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := QueryCollector{}.processMetrics(tt.metrics).Steps()
+			var statuses []steps.Status
+			var messages []string
+			for _, r := range results {
+				statuses = append(statuses, r.Status())
+				if r.Err() != nil {
+					messages = append(messages, r.Err().Error())
+				} else {
+					messages = append(messages, r.Message())
+				}
+			}
+			assert.Equal(t, tt.wantStatuses, statuses)
+			assert.Equal(t, tt.wantMessages, messages)
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package otel
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+	"github.com/lightstep/collector-cluster-check/pkg/steps/dependencies"
+)
+
+type StartLoopback struct{}
+
+var _ steps.Step = StartLoopback{}
+
+func (s StartLoopback) Name() string {
+	return "StartLoopback"
+}
+
+func (s StartLoopback) Description() string {
+	return "starts a local OTLP receiver that the test collector exports a copy of everything to"
+}
+
+func (s StartLoopback) Run(ctx context.Context, deps *steps.Deps) steps.Results {
+	if deps.LoopbackReceiver == nil {
+		return steps.NewResults(s, steps.NewFailureResultWithHelp(nil, "loopback receiver not set"))
+	}
+	return steps.NewResults(s, steps.NewSuccessfulResult(fmt.Sprintf("the collector will export run %s to %s", deps.LoopbackRunID, deps.LoopbackEndpoint)))
+}
+
+func (s StartLoopback) Dependencies(config *steps.Config) []steps.Dependency {
+	return []steps.Dependency{dependencies.NewCreateLoopbackFromConfig(config)}
+}
@@ -0,0 +1,74 @@
+package otel
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lightstep/collector-cluster-check/pkg/otlp"
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+	"github.com/lightstep/collector-cluster-check/pkg/steps/dependencies"
+)
+
+// matrixSendTimeout keeps a cell that hangs, e.g. TLS against a plaintext port, from using up the step's time
+const matrixSendTimeout = 10 * time.Second
+
+type TransportMatrix struct{}
+
+var _ steps.Step = TransportMatrix{}
+var _ steps.PolicyProvider = TransportMatrix{}
+
+func (c TransportMatrix) Name() string {
+	return "TransportMatrix"
+}
+
+func (c TransportMatrix) Description() string {
+	return "Sends every signal over every transport and security setting and reports which of them the endpoint accepts"
+}
+
+// Policy leaves time for every cell to time out
+func (c TransportMatrix) Policy() steps.Policy {
+	return steps.Policy{Timeout: 5 * time.Minute}
+}
+
+func (c TransportMatrix) Run(ctx context.Context, deps *steps.Deps) steps.Results {
+	if len(deps.TransportMatrix) == 0 {
+		return steps.NewResults(c, steps.NewFailureResultWithHelp(nil, "transport matrix not set"))
+	}
+	var results []steps.Result
+	for _, signal := range otlp.Signals {
+		for _, cell := range deps.TransportMatrix {
+			results = append(results, c.send(ctx, cell, signal))
+		}
+	}
+	return steps.NewResults(c, results...)
+}
+
+// send fails when the setting the exporters use doesn't work, any other setting only warrants a warning
+func (c TransportMatrix) send(ctx context.Context, cell steps.MatrixCell, signal otlp.Signal) steps.Result {
+	ctx, cancel := context.WithTimeout(ctx, matrixSendTimeout)
+	defer cancel()
+	start := time.Now()
+	err := cell.Client.Send(ctx, cell.Transport, signal)
+	latency := time.Since(start)
+	diagnosis := otlp.Diagnose(err)
+	var r steps.Result
+	switch {
+	case err == nil:
+		r = steps.NewSuccessfulResult(fmt.Sprintf("%s over %s (%s) accepted in %s", signal, cell.Transport, cell.Security, latency.Round(time.Millisecond)))
+	case diagnosis.Transient() || !cell.Configured:
+		r = steps.NewAcceptableFailureResultWithHelp(err, fmt.Sprintf("%s over %s (%s): %s", signal, cell.Transport, cell.Security, diagnosis.Help))
+	default:
+		r = steps.NewFailureResultWithHelp(err, fmt.Sprintf("%s over %s (%s): %s", signal, cell.Transport, cell.Security, diagnosis.Help))
+	}
+	return r.WithAttribute("signal", string(signal)).
+		WithAttribute("transport", string(cell.Transport)).
+		WithAttribute("security", string(cell.Security)).
+		WithAttribute("configured", cell.Configured).
+		WithAttribute("latency", latency.Round(10*time.Microsecond).String()).
+		WithAttribute("problem", string(diagnosis.Problem))
+}
+
+func (c TransportMatrix) Dependencies(config *steps.Config) []steps.Dependency {
+	return []steps.Dependency{dependencies.NewCreateTransportMatrixFromConfig(config)}
+}
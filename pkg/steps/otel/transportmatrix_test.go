@@ -0,0 +1,60 @@
+package otel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lightstep/collector-cluster-check/pkg/otlp"
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+func TestTransportMatrix_Run(t *testing.T) {
+	// a plaintext OTLP/HTTP only endpoint, gRPC and TLS requests to it fail
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	client := otlp.NewClient(strings.TrimPrefix(server.URL, "http://"), true, nil)
+
+	tests := []struct {
+		name         string
+		cells        []steps.MatrixCell
+		wantStatuses []steps.Status
+	}{
+		{
+			name: "configured setting works",
+			cells: []steps.MatrixCell{
+				{Transport: otlp.TransportGRPC, Security: steps.SecurityInsecure, Client: client},
+				{Transport: otlp.TransportHTTPJSON, Security: steps.SecurityInsecure, Client: client, Configured: true},
+				{Transport: otlp.TransportHTTPProtobuf, Security: steps.SecurityTLS, Client: client.WithSecurity(false)},
+			},
+			wantStatuses: []steps.Status{steps.StatusWarning, steps.StatusPass, steps.StatusWarning},
+		},
+		{
+			name: "configured setting doesn't work",
+			cells: []steps.MatrixCell{
+				{Transport: otlp.TransportGRPC, Security: steps.SecurityInsecure, Client: client, Configured: true},
+				{Transport: otlp.TransportHTTPProtobuf, Security: steps.SecurityInsecure, Client: client},
+			},
+			wantStatuses: []steps.Status{steps.StatusFail, steps.StatusPass},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TransportMatrix{}.Run(context.Background(), &steps.Deps{TransportMatrix: tt.cells})
+			require.Len(t, got.Steps(), len(otlp.Signals)*len(tt.cells))
+			for i, r := range got.Steps() {
+				signal, cell := otlp.Signals[i/len(tt.cells)], tt.cells[i%len(tt.cells)]
+				assert.Equal(t, tt.wantStatuses[i%len(tt.cells)], r.Status(), "%s over %s (%s)", signal, cell.Transport, cell.Security)
+				assert.Equal(t, string(signal), r.Attributes()["signal"])
+				assert.Equal(t, string(cell.Transport), r.Attributes()["transport"])
+				assert.Equal(t, string(cell.Security), r.Attributes()["security"])
+				assert.NotEmpty(t, r.Attributes()["latency"])
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package otel
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lightstep/collector-cluster-check/pkg/loopback"
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+// verifyInterval is how often the loopback receiver is checked while telemetry is still on its way
+const verifyInterval = 500 * time.Millisecond
+
+type VerifyLoopback struct{}
+
+var _ steps.Step = VerifyLoopback{}
+var _ steps.PolicyProvider = VerifyLoopback{}
+
+func (v VerifyLoopback) Name() string {
+	return "VerifyLoopback"
+}
+
+func (v VerifyLoopback) Description() string {
+	return "checks that the span, metric and log record of this run came back from the collector with their IDs, value, attributes and resource intact"
+}
+
+// Policy leaves time for the collector's batch processor and retries to export what it received
+func (v VerifyLoopback) Policy() steps.Policy {
+	return steps.Policy{Timeout: time.Minute}
+}
+
+func (v VerifyLoopback) Run(ctx context.Context, deps *steps.Deps) steps.Results {
+	if deps.LoopbackReceiver == nil || deps.LoopbackSent == nil {
+		return steps.NewResults(v, steps.NewFailureResultWithHelp(nil, "loopback receiver not set"))
+	}
+	expect := deps.LoopbackSent.Expect(deps.LoopbackRunID, map[string]string{
+		"service.name":    steps.ServiceName,
+		"service.version": steps.ServiceVersion,
+	})
+	verifications := deps.LoopbackReceiver.Verify(expect)
+	for !verified(verifications) {
+		select {
+		case <-ctx.Done():
+			return steps.NewResults(v, verificationResults(verifications, expect.RunID)...)
+		case <-time.After(verifyInterval):
+		}
+		verifications = deps.LoopbackReceiver.Verify(expect)
+	}
+	return steps.NewResults(v, verificationResults(verifications, expect.RunID)...)
+}
+
+func verified(verifications []loopback.Verification) bool {
+	for _, v := range verifications {
+		if !v.Ok() {
+			return false
+		}
+	}
+	return true
+}
+
+func verificationResults(verifications []loopback.Verification, runID string) []steps.Result {
+	results := make([]steps.Result, len(verifications))
+	for i, v := range verifications {
+		var r steps.Result
+		switch {
+		case v.Ok():
+			r = steps.NewSuccessfulResult(fmt.Sprintf("received %d %s of run %s intact", v.Matched, v.Signal, runID))
+		case v.Received == 0:
+			r = steps.NewFailureResultWithHelp(v.Err, fmt.Sprintf("the collector didn't export %s to the loopback receiver, check its pipelines and logs", v.Signal))
+		default:
+			r = steps.NewFailureResultWithHelp(v.Err, fmt.Sprintf("the collector changed the %s it exported, check its processors", v.Signal))
+		}
+		results[i] = r.WithAttribute("signal", v.Signal).WithAttribute("received", v.Received)
+	}
+	return results
+}
+
+func (v VerifyLoopback) Dependencies(config *steps.Config) []steps.Dependency {
+	return nil
+}
@@ -0,0 +1,54 @@
+package otel
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lightstep/collector-cluster-check/pkg/loopback"
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+func TestVerificationResults(t *testing.T) {
+	tests := []struct {
+		name         string
+		verification loopback.Verification
+		wantStatus   steps.Status
+		wantMessage  string
+		wantErr      string
+	}{
+		{
+			name:         "received intact",
+			verification: loopback.Verification{Signal: loopback.SignalSpans, Received: 1, Matched: 1},
+			wantStatus:   steps.StatusPass,
+			wantMessage:  "received 1 spans of run a1b2 intact",
+		},
+		{
+			name:         "nothing received",
+			verification: loopback.Verification{Signal: loopback.SignalLogRecords, Err: errors.New("no log_records of run a1b2 were received")},
+			wantStatus:   steps.StatusFail,
+			wantMessage:  "the collector didn't export log_records to the loopback receiver, check its pipelines and logs",
+			wantErr:      "no log_records of run a1b2 were received",
+		},
+		{
+			name:         "changed on the way",
+			verification: loopback.Verification{Signal: loopback.SignalMetrics, Received: 1, Matched: 1, Err: errors.New(`resource attribute service.name is "renamed", expected "collector-cluster-check"`)},
+			wantStatus:   steps.StatusFail,
+			wantMessage:  "the collector changed the metrics it exported, check its processors",
+			wantErr:      `resource attribute service.name is "renamed", expected "collector-cluster-check"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := verificationResults([]loopback.Verification{tt.verification}, "a1b2")
+			assert.Len(t, results, 1)
+			assert.Equal(t, tt.wantStatus, results[0].Status())
+			assert.Equal(t, tt.wantMessage, results[0].Message())
+			if tt.wantErr != "" {
+				assert.EqualError(t, results[0].Err(), tt.wantErr)
+			}
+			assert.Equal(t, tt.verification.Signal, results[0].Attributes()["signal"])
+		})
+	}
+}
@@ -6,8 +6,11 @@ import (
 	"time"
 
 	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
 
 	"github.com/lightstep/collector-cluster-check/pkg/steps"
 	"github.com/lightstep/collector-cluster-check/pkg/steps/dependencies"
@@ -16,6 +19,7 @@ import (
 type PodWatcher struct{}
 
 var _ steps.Step = PodWatcher{}
+var _ steps.PolicyProvider = PodWatcher{}
 
 func (p PodWatcher) Name() string {
 	return "PodWatcher"
@@ -25,25 +29,57 @@ func (p PodWatcher) Description() string {
 	return "checks if the collector pod is running"
 }
 
-func (p PodWatcher) waitForPodOrTimeout(ctx context.Context, watcher watch.Interface) error {
-	ctxTimeout, cancelFunc := context.WithTimeout(ctx, time.Second*5)
-	defer cancelFunc()
+func (p PodWatcher) Policy() steps.Policy {
+	return steps.Policy{Timeout: 2 * time.Minute}
+}
+
+// sidecarContainer is the name the operator gives the collector container it injects
+const sidecarContainer = "otc-container"
+
+// daemonSetPollInterval is how often the collector's DaemonSet is checked for pods that aren't ready yet
+var daemonSetPollInterval = 2 * time.Second
+
+func (p PodWatcher) waitForPodOrTimeout(ctx context.Context, watcher watch.Interface) (*apiv1.Pod, error) {
 	defer watcher.Stop()
 	for {
 		select {
 		case event := <-watcher.ResultChan():
 			if event.Type == watch.Error {
-				return fmt.Errorf("error watching")
+				return nil, fmt.Errorf("error watching")
 			}
 			p, ok := event.Object.(*apiv1.Pod)
 			if !ok {
-				return fmt.Errorf("unexpected type")
+				return nil, fmt.Errorf("unexpected type")
 			}
 			if p.Status.Phase == apiv1.PodRunning {
-				return nil
+				return p, nil
+			}
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timeout while waiting: %w", ctx.Err())
+		}
+	}
+}
+
+// waitForDaemonSet waits until the collector runs on every node it's scheduled on
+func (p PodWatcher) waitForDaemonSet(ctx context.Context, client kubernetes.Interface, name string) (int32, error) {
+	ticker := time.NewTicker(daemonSetPollInterval)
+	defer ticker.Stop()
+	var ready, desired int32
+	for {
+		ds, err := client.AppsV1().DaemonSets(apiv1.NamespaceDefault).Get(ctx, name, metav1.GetOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return 0, err
+		}
+		if err == nil {
+			ready, desired = ds.Status.NumberReady, ds.Status.DesiredNumberScheduled
+			if desired > 0 && ready == desired {
+				return ready, nil
 			}
-		case <-ctxTimeout.Done():
-			return fmt.Errorf("timeout while waiting")
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return 0, fmt.Errorf("%d of %d collector pods are ready: %w", ready, desired, ctx.Err())
 		}
 	}
 }
@@ -59,13 +95,49 @@ func (p PodWatcher) Run(ctx context.Context, deps *steps.Deps) steps.Results {
 	if err != nil {
 		return steps.NewResults(p, steps.NewFailureResult(err))
 	}
-	err = p.waitForPodOrTimeout(ctx, w)
+	pod, err := p.waitForPodOrTimeout(ctx, w)
 	if err != nil {
 		return steps.NewResults(p, steps.NewFailureResult(err))
 	}
+	switch collectorMode(deps.OtelColConfig) {
+	case steps.CollectorModeSidecar:
+		if !hasContainer(pod, sidecarContainer) {
+			return steps.NewResults(p, steps.NewFailureResultWithHelp(
+				fmt.Errorf("pod %s is running without the collector sidecar", pod.Name),
+				"the operator didn't inject the collector, check that its webhook is running"))
+		}
+		return steps.NewResults(p, steps.NewSuccessfulResult(fmt.Sprintf("collector sidecar is running in pod %s", pod.Name)))
+	case steps.CollectorModeDaemonSet:
+		ready, err := p.waitForDaemonSet(ctx, deps.KubeClient, deps.OtelColConfig.GetName()+"-collector")
+		if err != nil {
+			return steps.NewResults(p, steps.NewFailureResultWithHelp(err, "check the nodes' taints and the collector pods' events"))
+		}
+		return steps.NewResults(p, steps.NewSuccessfulResult(fmt.Sprintf("%d collector pods are running, one per node", ready)).WithAttribute("pods", ready))
+	}
 	return steps.NewResults(p, steps.NewSuccessfulResult("successfully waited for running pod"))
 }
 
+// collectorMode reads the mode of the collector from its spec, the operator's default is deployment
+func collectorMode(col *unstructured.Unstructured) steps.CollectorMode {
+	if col == nil {
+		return steps.CollectorModeDeployment
+	}
+	mode, _, _ := unstructured.NestedString(col.Object, "spec", "mode")
+	if mode == "" {
+		return steps.CollectorModeDeployment
+	}
+	return steps.CollectorMode(mode)
+}
+
+func hasContainer(pod *apiv1.Pod, name string) bool {
+	for _, c := range pod.Spec.Containers {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 func (p PodWatcher) Dependencies(config *steps.Config) []steps.Dependency {
-	return []steps.Dependency{dependencies.NewCreateKubeClientFromConfig(config)}
+	return []steps.Dependency{dependencies.NewCreateKubeClientFromConfig(config), dependencies.NewCollectorConfigFromConfig(config)}
 }
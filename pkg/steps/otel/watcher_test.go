@@ -0,0 +1,109 @@
+package otel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+func TestPodWatcher_Run(t *testing.T) {
+	daemonSetPollInterval = 10 * time.Millisecond
+	runningPod := func(containers ...string) *apiv1.Pod {
+		pod := &apiv1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-col-collector-abc", Namespace: apiv1.NamespaceDefault},
+			Status:     apiv1.PodStatus{Phase: apiv1.PodRunning},
+		}
+		for _, c := range containers {
+			pod.Spec.Containers = append(pod.Spec.Containers, apiv1.Container{Name: c})
+		}
+		return pod
+	}
+	daemonSet := func(ready int32, desired int32) *appsv1.DaemonSet {
+		return &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-col-collector", Namespace: apiv1.NamespaceDefault},
+			Status:     appsv1.DaemonSetStatus{NumberReady: ready, DesiredNumberScheduled: desired},
+		}
+	}
+	tests := []struct {
+		name        string
+		mode        steps.CollectorMode
+		pod         *apiv1.Pod
+		objects     []runtime.Object
+		wantStatus  steps.Status
+		wantMessage string
+	}{
+		{
+			name:        "deployment",
+			mode:        steps.CollectorModeDeployment,
+			pod:         runningPod(sidecarContainer),
+			wantStatus:  steps.StatusPass,
+			wantMessage: "successfully waited for running pod",
+		},
+		{
+			name:        "sidecar injected",
+			mode:        steps.CollectorModeSidecar,
+			pod:         runningPod("workload", sidecarContainer),
+			wantStatus:  steps.StatusPass,
+			wantMessage: "collector sidecar is running in pod test-col-collector-abc",
+		},
+		{
+			name:        "sidecar not injected",
+			mode:        steps.CollectorModeSidecar,
+			pod:         runningPod("workload"),
+			wantStatus:  steps.StatusFail,
+			wantMessage: "the operator didn't inject the collector, check that its webhook is running",
+		},
+		{
+			name:        "daemonset on every node",
+			mode:        steps.CollectorModeDaemonSet,
+			pod:         runningPod(sidecarContainer),
+			objects:     []runtime.Object{daemonSet(3, 3)},
+			wantStatus:  steps.StatusPass,
+			wantMessage: "3 collector pods are running, one per node",
+		},
+		{
+			name:        "daemonset missing a node",
+			mode:        steps.CollectorModeDaemonSet,
+			pod:         runningPod(sidecarContainer),
+			objects:     []runtime.Object{daemonSet(2, 3)},
+			wantStatus:  steps.StatusFail,
+			wantMessage: "check the nodes' taints and the collector pods' events",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(tt.objects...)
+			client.PrependWatchReactor("pods", func(action k8stesting.Action) (bool, watch.Interface, error) {
+				w := watch.NewFakeWithChanSize(1, false)
+				w.Add(tt.pod)
+				return true, w, nil
+			})
+			deps := &steps.Deps{
+				KubeClient: client,
+				OtelColConfig: &unstructured.Unstructured{Object: map[string]interface{}{
+					"metadata": map[string]interface{}{"name": "test-col"},
+					"spec":     map[string]interface{}{"mode": string(tt.mode)},
+				}},
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			got := PodWatcher{}.Run(ctx, deps)
+			require.Len(t, got.Steps(), 1)
+			assert.Equal(t, tt.wantStatus, got.Steps()[0].Status())
+			assert.Equal(t, tt.wantMessage, got.Steps()[0].Message())
+		})
+	}
+}
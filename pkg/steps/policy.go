@@ -0,0 +1,86 @@
+package steps
+
+import (
+	"strings"
+	"time"
+)
+
+var defaultBackoff = Backoff{
+	Initial:    time.Second,
+	Max:        10 * time.Second,
+	Multiplier: 2,
+}
+
+// Policy controls how long a step or dependency may run for and how it is retried when it fails.
+// Zero values are unset, so policies can be layered on top of each other.
+type Policy struct {
+	// Timeout bounds every single attempt
+	Timeout time.Duration `mapstructure:"timeout"`
+	// MaxAttempts is how many attempts are made before giving up, including the first one
+	MaxAttempts int `mapstructure:"maxAttempts"`
+	// Backoff is how long to wait between attempts
+	Backoff Backoff `mapstructure:"backoff"`
+}
+
+// Backoff is an exponential backoff between attempts
+type Backoff struct {
+	Initial    time.Duration `mapstructure:"initial"`
+	Max        time.Duration `mapstructure:"max"`
+	Multiplier float64       `mapstructure:"multiplier"`
+}
+
+// PolicyProvider is implemented by steps and dependencies that know how long they need or whether they are flaky
+type PolicyProvider interface {
+	Policy() Policy
+}
+
+// merge returns p with every unset field taken from fallback
+func (p Policy) merge(fallback Policy) Policy {
+	if p.Timeout == 0 {
+		p.Timeout = fallback.Timeout
+	}
+	if p.MaxAttempts == 0 {
+		p.MaxAttempts = fallback.MaxAttempts
+	}
+	if p.Backoff.Initial == 0 {
+		p.Backoff.Initial = fallback.Backoff.Initial
+	}
+	if p.Backoff.Max == 0 {
+		p.Backoff.Max = fallback.Backoff.Max
+	}
+	if p.Backoff.Multiplier == 0 {
+		p.Backoff.Multiplier = fallback.Backoff.Multiplier
+	}
+	return p
+}
+
+// Delay is how long to wait before the given attempt, attempts start at one
+func (b Backoff) Delay(attempt int) time.Duration {
+	delay := float64(b.Initial)
+	for i := 2; i < attempt; i++ {
+		delay *= b.Multiplier
+	}
+	if b.Max > 0 && time.Duration(delay) > b.Max {
+		return b.Max
+	}
+	return time.Duration(delay)
+}
+
+// PolicyFor resolves the policy of a step or dependency. A policy configured for its name wins over the one it
+// declares itself, which wins over the default policy.
+func (c *Config) PolicyFor(d Describable) Policy {
+	var p Policy
+	for name, configured := range c.Policies {
+		// names are matched loosely since config file keys lose their case
+		if strings.EqualFold(name, d.Name()) {
+			p = configured
+			break
+		}
+	}
+	if provider, ok := d.(PolicyProvider); ok {
+		p = p.merge(provider.Policy())
+	}
+	p = p.merge(c.DefaultPolicy)
+	p = p.merge(Policy{MaxAttempts: 1, Backoff: defaultBackoff})
+	return p
+}
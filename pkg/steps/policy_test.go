@@ -0,0 +1,64 @@
+package steps
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type policyStep struct {
+	fakeStep
+	policy Policy
+}
+
+func (p policyStep) Policy() Policy {
+	return p.policy
+}
+
+func TestConfig_PolicyFor(t *testing.T) {
+	tests := []struct {
+		name string
+		conf *Config
+		step Describable
+		want Policy
+	}{
+		{
+			name: "defaults",
+			conf: &Config{},
+			step: fakeStep{name: "step"},
+			want: Policy{MaxAttempts: 1, Backoff: defaultBackoff},
+		},
+		{
+			name: "default policy",
+			conf: &Config{DefaultPolicy: Policy{Timeout: time.Second, MaxAttempts: 2}},
+			step: fakeStep{name: "step"},
+			want: Policy{Timeout: time.Second, MaxAttempts: 2, Backoff: defaultBackoff},
+		},
+		{
+			name: "declared policy wins over the default",
+			conf: &Config{DefaultPolicy: Policy{Timeout: time.Second, MaxAttempts: 2}},
+			step: policyStep{fakeStep{name: "step"}, Policy{Timeout: time.Minute}},
+			want: Policy{Timeout: time.Minute, MaxAttempts: 2, Backoff: defaultBackoff},
+		},
+		{
+			name: "configured policy wins over the declared one",
+			conf: &Config{Policies: map[string]Policy{"STEP": {MaxAttempts: 5, Backoff: Backoff{Initial: time.Millisecond}}}},
+			step: policyStep{fakeStep{name: "step"}, Policy{Timeout: time.Minute, MaxAttempts: 3}},
+			want: Policy{Timeout: time.Minute, MaxAttempts: 5, Backoff: Backoff{Initial: time.Millisecond, Max: defaultBackoff.Max, Multiplier: defaultBackoff.Multiplier}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.conf.PolicyFor(tt.step))
+		})
+	}
+}
+
+func TestBackoff_Delay(t *testing.T) {
+	b := Backoff{Initial: time.Second, Max: 5 * time.Second, Multiplier: 2}
+	assert.Equal(t, time.Second, b.Delay(2))
+	assert.Equal(t, 2*time.Second, b.Delay(3))
+	assert.Equal(t, 4*time.Second, b.Delay(4))
+	assert.Equal(t, 5*time.Second, b.Delay(5))
+}
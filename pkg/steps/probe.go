@@ -0,0 +1,17 @@
+package steps
+
+import "time"
+
+// ProbeResult is what a probe pod found out about a destination from inside the cluster
+type ProbeResult struct {
+	Destination Destination
+	// RemoteIP is the address the destination resolved to, empty when the lookup failed
+	RemoteIP string
+	// Lookup, Connect and Handshake are how long after the start each phase finished, zero when it didn't
+	Lookup    time.Duration
+	Connect   time.Duration
+	Handshake time.Duration
+	// ExitCode is curl's exit code, zero when everything worked
+	ExitCode int
+	Error    string
+}
@@ -0,0 +1,72 @@
+package steps
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Profile describes how to send telemetry to a backend, profiles are defined in the config file and picked with
+// --profile, e.g.
+//
+//	profiles:
+//	  - name: honeycomb
+//	    endpoint: api.honeycomb.io:443
+//	    tokenHeader: x-honeycomb-team
+//	    tokenEnv: HONEYCOMB_API_KEY
+type Profile struct {
+	Name     string `mapstructure:"name"`
+	Endpoint string `mapstructure:"endpoint"`
+	// TokenHeader is the header the access token is sent in, no token is sent without one
+	TokenHeader string `mapstructure:"tokenHeader"`
+	// TokenEnv is the environment variable the access token is read from when --accessToken isn't set
+	TokenEnv string `mapstructure:"tokenEnv"`
+	// Headers are sent with every export request along with the token
+	Headers map[string]string `mapstructure:"headers"`
+}
+
+// DefaultProfile is used without --profile, it sends telemetry to Lightstep
+var DefaultProfile = Profile{
+	Name:        "lightstep",
+	Endpoint:    "ingest.lightstep.com:443",
+	TokenHeader: "lightstep-access-token",
+	TokenEnv:    "LS_TOKEN",
+}
+
+// Token reads the access token from the profile's environment variable
+func (p Profile) Token() string {
+	if p.TokenEnv == "" {
+		return ""
+	}
+	return os.Getenv(p.TokenEnv)
+}
+
+// ExportHeaders returns the headers every exporter sends, extra headers win over the token and the profile's headers.
+// An empty token isn't sent.
+func (p Profile) ExportHeaders(token string, extra map[string]string) map[string]string {
+	headers := map[string]string{}
+	if p.TokenHeader != "" && token != "" {
+		headers[p.TokenHeader] = token
+	}
+	for k, v := range p.Headers {
+		headers[k] = v
+	}
+	for k, v := range extra {
+		headers[k] = v
+	}
+	return headers
+}
+
+// ParseHeaders parses headers written as key=value, the value may be empty but the key may not
+func ParseHeaders(headers []string) (map[string]string, error) {
+	parsed := map[string]string{}
+	for _, h := range headers {
+		key, value, ok := strings.Cut(h, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid header %q, must be key=value", h)
+		}
+		parsed[key] = value
+	}
+	return parsed, nil
+}
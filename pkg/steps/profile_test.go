@@ -0,0 +1,98 @@
+package steps
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name:    "no headers",
+			headers: nil,
+			want:    map[string]string{},
+		},
+		{
+			name:    "headers",
+			headers: []string{"x-honeycomb-team=abc", "x-honeycomb-dataset=", "authorization=Api-Token a=b"},
+			want: map[string]string{
+				"x-honeycomb-team":    "abc",
+				"x-honeycomb-dataset": "",
+				"authorization":       "Api-Token a=b",
+			},
+		},
+		{
+			name:    "missing value",
+			headers: []string{"x-honeycomb-team"},
+			wantErr: `invalid header "x-honeycomb-team", must be key=value`,
+		},
+		{
+			name:    "missing key",
+			headers: []string{"=abc"},
+			wantErr: `invalid header "=abc", must be key=value`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseHeaders(tt.headers)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestProfile_ExportHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile Profile
+		token   string
+		extra   map[string]string
+		want    map[string]string
+	}{
+		{
+			name:    "default profile",
+			profile: DefaultProfile,
+			token:   "secret",
+			want:    map[string]string{"lightstep-access-token": "secret"},
+		},
+		{
+			name:    "empty token isn't sent",
+			profile: DefaultProfile,
+			want:    map[string]string{},
+		},
+		{
+			name: "profile headers",
+			profile: Profile{
+				TokenHeader: "x-honeycomb-team",
+				Headers:     map[string]string{"x-honeycomb-dataset": "checks"},
+			},
+			token: "secret",
+			want:  map[string]string{"x-honeycomb-team": "secret", "x-honeycomb-dataset": "checks"},
+		},
+		{
+			name: "extra headers win",
+			profile: Profile{
+				TokenHeader: "authorization",
+				Headers:     map[string]string{"x-scope-orgid": "1"},
+			},
+			token: "Basic abc",
+			extra: map[string]string{"authorization": "Api-Token def", "x-scope-orgid": "2"},
+			want:  map[string]string{"authorization": "Api-Token def", "x-scope-orgid": "2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.profile.ExportHeaders(tt.token, tt.extra))
+		})
+	}
+}
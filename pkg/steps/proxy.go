@@ -0,0 +1,150 @@
+package steps
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// Proxy is the proxy that traffic to a destination goes through, URL is nil when it connects directly
+type Proxy struct {
+	Destination Destination
+	URL         *url.URL
+	// Source explains where the proxy came from, e.g. the environment variable that set it
+	Source string
+}
+
+// ProxyConfig reads the proxy environment variables, a proxy set on the command line replaces HTTP_PROXY and
+// HTTPS_PROXY while NO_PROXY still applies
+func ProxyConfig(proxy string) *httpproxy.Config {
+	conf := httpproxy.FromEnvironment()
+	if proxy != "" {
+		conf.HTTPProxy = proxy
+		conf.HTTPSProxy = proxy
+	}
+	return conf
+}
+
+// ResolveProxy finds the proxy for a destination the same way the exporters do
+func ResolveProxy(conf *httpproxy.Config, proxy string, d Destination, secure bool) (Proxy, error) {
+	target := &url.URL{Scheme: "http", Host: d.Address()}
+	source, configured := "HTTP_PROXY", conf.HTTPProxy
+	if secure {
+		target.Scheme = "https"
+		source, configured = "HTTPS_PROXY", conf.HTTPSProxy
+	}
+	if proxy != "" {
+		source = "--proxy"
+	}
+	u, err := conf.ProxyFunc()(target)
+	if err != nil {
+		return Proxy{}, fmt.Errorf("invalid proxy for %s: %w", d, err)
+	}
+	if u == nil {
+		if configured != "" {
+			return Proxy{Destination: d, Source: "NO_PROXY"}, nil
+		}
+		return Proxy{Destination: d, Source: "none"}, nil
+	}
+	return Proxy{Destination: d, URL: u, Source: source}, nil
+}
+
+// ProxyFromEnvironment is like http.ProxyFromEnvironment, with the proxy set on the command line taking precedence
+func ProxyFromEnvironment(proxy string) func(*http.Request) (*url.URL, error) {
+	proxyFunc := ProxyConfig(proxy).ProxyFunc()
+	return func(r *http.Request) (*url.URL, error) {
+		return proxyFunc(r.URL)
+	}
+}
+
+// DialProxy opens a tunnel to address through an HTTP or HTTPS proxy with an HTTP CONNECT request
+func DialProxy(ctx context.Context, proxy *url.URL, address string) (net.Conn, error) {
+	proxyAddress := proxy.Host
+	if proxy.Port() == "" {
+		proxyAddress = net.JoinHostPort(proxy.Hostname(), schemePort(proxy.Scheme))
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", proxyAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to proxy %s: %w", proxy.Redacted(), err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if proxy.Scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: proxy.Hostname()})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("TLS handshake with proxy %s failed: %w", proxy.Redacted(), err)
+		}
+		conn = tlsConn
+	}
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: http.Header{},
+	}
+	if proxy.User != nil {
+		password, _ := proxy.User.Password()
+		credentials := proxy.User.Username() + ":" + password
+		req.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(credentials)))
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send CONNECT to proxy %s: %w", proxy.Redacted(), err)
+	}
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read CONNECT response from proxy %s: %w", proxy.Redacted(), err)
+	}
+	// the body of a successful CONNECT response is the tunnel itself, so it is only closed on failure
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s refused to CONNECT to %s: %s", proxy.Redacted(), address, resp.Status)
+	}
+	_ = conn.SetDeadline(time.Time{})
+	return &tunnel{Conn: conn, reader: reader}, nil
+}
+
+// tunnel reads anything the proxy sent after its CONNECT response before reading from the connection again
+type tunnel struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (t *tunnel) Read(b []byte) (int, error) {
+	return t.reader.Read(b)
+}
+
+// ProxyDialer dials addresses through the proxy set on the command line unless NO_PROXY excludes them,
+// for transports like gRPC that dial connections themselves
+func ProxyDialer(proxy string, secure bool) func(context.Context, string) (net.Conn, error) {
+	conf := ProxyConfig(proxy)
+	return func(ctx context.Context, address string) (net.Conn, error) {
+		d, err := ParseDestination(address)
+		if err != nil {
+			return nil, err
+		}
+		p, err := ResolveProxy(conf, proxy, d, secure)
+		if err != nil {
+			return nil, err
+		}
+		if p.URL == nil {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "tcp", address)
+		}
+		return DialProxy(ctx, p.URL, address)
+	}
+}
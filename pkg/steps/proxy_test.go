@@ -0,0 +1,191 @@
+package steps
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// newConnectProxy is a stand-in for a forward proxy, it tunnels CONNECT requests and rejects anything else
+func newConnectProxy(t *testing.T, auth string) *httptest.Server {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if auth != "" && r.Header.Get("Proxy-Authorization") != auth {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			upstream.Close()
+			return
+		}
+		go func() {
+			_, _ = io.Copy(upstream, buf)
+			upstream.Close()
+		}()
+		_, _ = io.Copy(conn, upstream)
+		conn.Close()
+	}))
+	t.Cleanup(proxy.Close)
+	return proxy
+}
+
+// newEchoServer writes back everything it reads
+func newEchoServer(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, _ = io.Copy(conn, conn)
+				conn.Close()
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestResolveProxy(t *testing.T) {
+	destination := Destination{Host: "ingest.lightstep.com", Port: "443"}
+	tests := []struct {
+		name       string
+		conf       *httpproxy.Config
+		proxy      string
+		secure     bool
+		wantURL    string
+		wantSource string
+	}{
+		{
+			name:       "no proxy",
+			conf:       &httpproxy.Config{},
+			secure:     true,
+			wantSource: "none",
+		},
+		{
+			name:       "https proxy",
+			conf:       &httpproxy.Config{HTTPSProxy: "http://proxy.internal:3128", HTTPProxy: "http://other.internal:3128"},
+			secure:     true,
+			wantURL:    "http://proxy.internal:3128",
+			wantSource: "HTTPS_PROXY",
+		},
+		{
+			name:       "http proxy when insecure",
+			conf:       &httpproxy.Config{HTTPSProxy: "http://proxy.internal:3128", HTTPProxy: "http://other.internal:3128"},
+			wantURL:    "http://other.internal:3128",
+			wantSource: "HTTP_PROXY",
+		},
+		{
+			name:       "excluded by no proxy",
+			conf:       &httpproxy.Config{HTTPSProxy: "http://proxy.internal:3128", NoProxy: ".lightstep.com"},
+			secure:     true,
+			wantSource: "NO_PROXY",
+		},
+		{
+			name:       "flag",
+			conf:       &httpproxy.Config{HTTPSProxy: "http://flag.internal:8080"},
+			proxy:      "http://flag.internal:8080",
+			secure:     true,
+			wantURL:    "http://flag.internal:8080",
+			wantSource: "--proxy",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveProxy(tt.conf, tt.proxy, destination, tt.secure)
+			require.NoError(t, err)
+			assert.Equal(t, destination, got.Destination)
+			assert.Equal(t, tt.wantSource, got.Source)
+			if tt.wantURL == "" {
+				assert.Nil(t, got.URL)
+			} else {
+				assert.Equal(t, tt.wantURL, got.URL.String())
+			}
+		})
+	}
+}
+
+func TestDialProxy(t *testing.T) {
+	echo := newEchoServer(t)
+	open := newConnectProxy(t, "")
+	authenticated := newConnectProxy(t, "Basic dXNlcjpwYXNz")
+	// me@corp:p@ss:w/rd %1
+	reserved := newConnectProxy(t, "Basic bWVAY29ycDpwQHNzOncvcmQgJTE=")
+	tests := []struct {
+		name    string
+		proxy   string
+		user    *url.Userinfo
+		wantErr string
+	}{
+		{
+			name:  "tunnels",
+			proxy: open.URL,
+		},
+		{
+			name:  "authenticates",
+			proxy: authenticated.URL,
+			user:  url.UserPassword("user", "pass"),
+		},
+		{
+			name:  "authenticates with reserved characters",
+			proxy: reserved.URL,
+			user:  url.UserPassword("me@corp", "p@ss:w/rd %1"),
+		},
+		{
+			name:    "refused",
+			proxy:   authenticated.URL,
+			wantErr: "proxy " + authenticated.URL + " refused to CONNECT to " + echo + ": 407 Proxy Authentication Required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxy, err := url.Parse(tt.proxy)
+			require.NoError(t, err)
+			proxy.User = tt.user
+			conn, err := DialProxy(context.Background(), proxy, echo)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			defer conn.Close()
+			_, err = conn.Write([]byte("ping"))
+			require.NoError(t, err)
+			got := make([]byte, 4)
+			_, err = io.ReadFull(conn, got)
+			require.NoError(t, err)
+			assert.Equal(t, "ping", string(got))
+		})
+	}
+}
+
+func TestProxyDialer(t *testing.T) {
+	echo := newEchoServer(t)
+	proxy := newConnectProxy(t, "")
+	// loopback destinations are never proxied, so the dialer connects directly
+	conn, err := ProxyDialer(proxy.URL, true)(context.Background(), echo)
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, echo, conn.RemoteAddr().String())
+}
@@ -0,0 +1,325 @@
+package steps
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type nodeState int
+
+const (
+	pending nodeState = iota
+	running
+	// succeeded nodes let the nodes that need them run
+	succeeded
+	// failed nodes ran, but the nodes that need them must not
+	failed
+	// skipped nodes never ran
+	skipped
+)
+
+// node is a single dependency or step in a check's execution graph
+type node struct {
+	dep  Dependency
+	step Step
+
+	// needs must all succeed before this node can run, otherwise it is skipped
+	needs []*node
+	// after must all be finished before this node can run, regardless of how they went
+	after []*node
+
+	state   nodeState
+	option  Option
+	results Results
+	// timedOut nodes reached their timeout or were abandoned in at least one attempt, so they may have left something
+	// behind
+	timedOut bool
+}
+
+func (n *node) finished() bool {
+	return n.state != pending && n.state != running
+}
+
+// ready reports whether every prerequisite of the node is finished and which of them, if any, blocks it from running
+func (n *node) ready() (ready bool, blocker *node) {
+	for _, need := range n.needs {
+		if !need.finished() {
+			return false, nil
+		}
+	}
+	for _, a := range n.after {
+		if !a.finished() {
+			return false, nil
+		}
+	}
+	for _, need := range n.needs {
+		if need.state != succeeded {
+			return true, need
+		}
+	}
+	return true, nil
+}
+
+// skip marks a node that will never run, with the reason why
+func (n *node) skip(reason string) {
+	n.state = skipped
+	n.results = NewResults(n.describable(), NewSkippedResult(reason))
+}
+
+// skipReason explains that the node can't run because blocker didn't succeed
+func skipReason(blocker *node) string {
+	outcome := "failed"
+	if blocker.state == skipped {
+		outcome = "was skipped"
+	}
+	if blocker.dep != nil {
+		return fmt.Sprintf("skipped: dependency %s %s", blocker.dep.Name(), outcome)
+	}
+	return fmt.Sprintf("skipped: %s %s", blocker.step.Name(), outcome)
+}
+
+// graph is the set of steps and dependencies a check runs. Nodes are stored in the order they were added,
+// which is always a valid topological order since a node is only added once its prerequisites are.
+type graph struct {
+	nodes []*node
+	deps  map[string]*node
+	// initialized are dependencies that already ran in a previous graph and must not be run again
+	initialized map[string]Dependency
+}
+
+func newGraph(initialized map[string]Dependency) *graph {
+	return &graph{
+		deps:        map[string]*node{},
+		initialized: initialized,
+	}
+}
+
+// addStep adds a step and its dependencies to the graph. The step and any dependency that isn't in the graph yet
+// run after the predecessors; when blocking is set they only run if every predecessor succeeded.
+func (g *graph) addStep(step Step, conf *Config, predecessors []*node, blocking bool) *node {
+	n := &node{step: step}
+	for _, dep := range step.Dependencies(conf) {
+		if d := g.addDep(dep, conf, predecessors, blocking); d != nil {
+			n.needs = append(n.needs, d)
+		}
+	}
+	g.order(n, predecessors, blocking)
+	g.nodes = append(g.nodes, n)
+	return n
+}
+
+func (g *graph) addDep(dep Dependency, conf *Config, predecessors []*node, blocking bool) *node {
+	if _, ok := g.initialized[dep.Name()]; ok {
+		return nil
+	}
+	if n, ok := g.deps[dep.Name()]; ok {
+		return n
+	}
+	n := &node{dep: dep}
+	for _, d := range dep.Dependencies(conf) {
+		if child := g.addDep(d, conf, predecessors, blocking); child != nil {
+			n.needs = append(n.needs, child)
+		}
+	}
+	g.order(n, predecessors, blocking)
+	g.deps[dep.Name()] = n
+	g.nodes = append(g.nodes, n)
+	return n
+}
+
+func (g *graph) order(n *node, predecessors []*node, blocking bool) {
+	if blocking {
+		n.needs = append(n.needs, predecessors...)
+	} else {
+		n.after = append(n.after, predecessors...)
+	}
+}
+
+// execute runs every node of the graph, at most parallelism at a time. Ready nodes are always started in the order
+// they were added so that a parallelism of one behaves exactly like running the steps in sequence. Options returned
+// by dependencies are applied from this goroutine only, never concurrently with each other.
+func (c *Check) execute(ctx context.Context, g *graph, deps *Deps, conf *Config, parallelism int) ([]Results, []Results) {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	type outcome struct {
+		n     *node
+		state nodeState
+	}
+	done := make(chan outcome)
+	inflight := 0
+	for {
+		for _, n := range g.nodes {
+			if inflight >= parallelism {
+				break
+			}
+			if n.state != pending {
+				continue
+			}
+			ready, blocker := n.ready()
+			if !ready {
+				continue
+			}
+			if blocker != nil {
+				n.skip(skipReason(blocker))
+				continue
+			}
+			if ctx.Err() != nil {
+				n.skip("skipped: check was cancelled")
+				continue
+			}
+			n.state = running
+			inflight++
+			go func(n *node) {
+				done <- outcome{n: n, state: runNode(ctx, n, deps, conf.PolicyFor(n.describable()))}
+			}(n)
+		}
+		if inflight == 0 {
+			break
+		}
+		o := <-done
+		inflight--
+		o.n.state = o.state
+		if o.state == succeeded && o.n.dep != nil {
+			o.n.option(deps)
+		}
+	}
+
+	var depAcc []Results
+	var acc []Results
+	for _, n := range g.nodes {
+		if n.dep != nil {
+			depAcc = append(depAcc, n.results)
+			switch {
+			case n.state == succeeded:
+				c.depMap[n.dep.Name()] = n.dep
+				c.initialized = append(c.initialized, n.dep)
+			case n.timedOut:
+				// only shut down, a dependency that timed out must not be reused
+				c.initialized = append(c.initialized, n.dep)
+			}
+		} else {
+			acc = append(acc, n.results)
+		}
+	}
+	return depAcc, acc
+}
+
+func (n *node) describable() Describable {
+	if n.dep != nil {
+		return n.dep
+	}
+	return n.step
+}
+
+// runNode runs a single node following its policy, records its results and returns the state it finished in.
+// It runs concurrently with other nodes, so it must not touch anything but the node itself.
+func runNode(ctx context.Context, n *node, deps *Deps, policy Policy) nodeState {
+	var lastErr error
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		a := attemptNode(ctx, n, deps, policy.Timeout)
+		results := a.results
+		results.attempts = attempt
+		results.lastErr = lastErr
+		results.start = start
+		results.duration = time.Since(start)
+		if !a.ok {
+			results.lastErr = results.err()
+		}
+		n.option, n.results = a.option, results
+		n.timedOut = n.timedOut || a.timedOut
+		if a.ok {
+			return succeeded
+		}
+		// an abandoned attempt may still be running, a retry would overlap with it
+		if a.abandoned || attempt >= policy.MaxAttempts {
+			return failed
+		}
+		lastErr = results.lastErr
+		select {
+		case <-ctx.Done():
+			return failed
+		case <-time.After(policy.Backoff.Delay(attempt + 1)):
+		}
+	}
+}
+
+// timeoutGrace is how long an attempt has to return once its timeout is reached or the check is cancelled, its own
+// results explain the timeout better than a generic message
+var timeoutGrace = 5 * time.Second
+
+// errAttemptTimeout is the cause of an attempt's context when its timeout is reached
+var errAttemptTimeout = errors.New("attempt timed out")
+
+type attemptResult struct {
+	option  Option
+	results Results
+	ok      bool
+	// timedOut attempts reached their timeout, they count as failed unless they reported how far they got
+	timedOut bool
+	// abandoned attempts didn't return within the grace period after their timeout or cancellation and may still be
+	// running
+	abandoned bool
+}
+
+// attemptNode runs the node once. When the timeout is reached or ctx is cancelled the node's context is cancelled and it
+// has timeoutGrace to return its own results, after which the attempt is abandoned so that a node ignoring its context
+// can't keep the finalizers from running.
+func attemptNode(ctx context.Context, n *node, deps *Deps, timeout time.Duration) attemptResult {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, errAttemptTimeout)
+		defer cancel()
+	}
+	// buffered so an abandoned attempt doesn't block forever once it returns
+	attempted := make(chan attemptResult, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				attempted <- attemptResult{results: NewResults(n.describable(), NewErrorResult(fmt.Errorf("panic: %v", r)))}
+			}
+		}()
+		if n.dep != nil {
+			opt, r := n.dep.Run(ctx, deps)
+			attempted <- attemptResult{option: opt, results: NewResults(n.dep, r), ok: r.ShouldContinue()}
+			return
+		}
+		results := n.step.Run(ctx, deps)
+		attempted <- attemptResult{results: results, ok: !results.ShouldStop()}
+	}()
+	timedOut := func(a attemptResult) attemptResult {
+		err := fmt.Errorf("%s timed out after %s", n.describable().Name(), timeout)
+		// results that explain the timeout are kept, only a success that came too late is replaced
+		if a.abandoned || a.results.Status() == StatusPass {
+			a.option, a.results, a.ok = Empty, NewResults(n.describable(), NewFailureResult(err)), false
+		}
+		a.timedOut = true
+		return a
+	}
+	select {
+	case a := <-attempted:
+		if context.Cause(ctx) == errAttemptTimeout {
+			return timedOut(a)
+		}
+		return a
+	case <-ctx.Done():
+	}
+	if context.Cause(ctx) != errAttemptTimeout {
+		select {
+		case a := <-attempted:
+			return a
+		case <-time.After(timeoutGrace):
+			err := fmt.Errorf("%s didn't return within %s of being cancelled", n.describable().Name(), timeoutGrace)
+			return attemptResult{option: Empty, results: NewResults(n.describable(), NewFailureResult(err)), timedOut: true, abandoned: true}
+		}
+	}
+	select {
+	case a := <-attempted:
+		return timedOut(a)
+	case <-time.After(timeoutGrace):
+		return timedOut(attemptResult{abandoned: true})
+	}
+}
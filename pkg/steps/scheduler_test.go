@@ -0,0 +1,386 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// slowStep records how many slow steps are running at once
+type slowStep struct {
+	fakeStep
+	running *atomic.Int32
+	peak    *atomic.Int32
+}
+
+func (s slowStep) Run(ctx context.Context, deps *Deps) Results {
+	current := s.running.Add(1)
+	defer s.running.Add(-1)
+	for {
+		peak := s.peak.Load()
+		if current <= peak || s.peak.CompareAndSwap(peak, current) {
+			break
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	return s.fakeStep.Run(ctx, deps)
+}
+
+func TestCheck_RunParallelism(t *testing.T) {
+	tests := []struct {
+		name        string
+		parallelism int
+		wantPeak    int32
+	}{
+		{
+			name:        "sequential",
+			parallelism: 1,
+			wantPeak:    1,
+		},
+		{
+			name:        "unset is sequential",
+			parallelism: 0,
+			wantPeak:    1,
+		},
+		{
+			name:        "limited",
+			parallelism: 2,
+			wantPeak:    2,
+		},
+		{
+			name:        "unlimited",
+			parallelism: 10,
+			wantPeak:    4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var running, peak atomic.Int32
+			var lane []Step
+			for i := 0; i < 4; i++ {
+				lane = append(lane, slowStep{fakeStep{name: fmt.Sprintf("step%d", i), result: NewSuccessfulResult("ok")}, &running, &peak})
+			}
+			c := NewCheck("test", "test", Independent(lane...)...)
+			_, results := c.Run(context.Background(), NewDependencies(), &Config{Parallelism: tt.parallelism})
+			assert.Equal(t, tt.wantPeak, peak.Load())
+			var names []string
+			for _, r := range results {
+				names = append(names, r.StepName())
+			}
+			assert.Equal(t, []string{"step0", "step1", "step2", "step3"}, names)
+		})
+	}
+}
+
+func TestCheck_RunLanes(t *testing.T) {
+	var shutdowns []string
+	failing := fakeDependency{name: "failing", result: NewFailureResult(fmt.Errorf("boom")), shutdowns: &shutdowns}
+	shared := fakeDependency{name: "shared", result: NewSuccessfulResult("ok"), shutdowns: &shutdowns}
+	tests := []struct {
+		name      string
+		check     *Check
+		wantSteps []string
+		wantDeps  []string
+	}{
+		{
+			name: "a failed lane doesn't stop the others",
+			check: NewCheck("test", "test",
+				[]Step{
+					fakeStep{name: "a1", result: NewFailureResult(fmt.Errorf("boom"))},
+					fakeStep{name: "a2", result: NewSuccessfulResult("ok")},
+				},
+				[]Step{
+					fakeStep{name: "b1", result: NewSuccessfulResult("ok")},
+					fakeStep{name: "b2", result: NewSuccessfulResult("ok")},
+				},
+			),
+			wantSteps: []string{"a1=fail", "a2=skip", "b1=pass", "b2=pass"},
+		},
+		{
+			name: "a failed dependency only stops the steps that need it",
+			check: NewCheck("test", "test", Independent(
+				fakeStep{name: "a", deps: []Dependency{failing}, result: NewSuccessfulResult("ok")},
+				fakeStep{name: "b", deps: []Dependency{shared}, result: NewSuccessfulResult("ok")},
+				fakeStep{name: "c", deps: []Dependency{failing}, result: NewSuccessfulResult("ok")},
+			)...),
+			wantSteps: []string{"a=skip", "b=pass", "c=skip"},
+			wantDeps:  []string{"failing=fail", "shared=pass"},
+		},
+		{
+			name: "shared dependencies only run once",
+			check: NewCheck("test", "test", Independent(
+				fakeStep{name: "a", deps: []Dependency{shared}, result: NewSuccessfulResult("ok")},
+				fakeStep{name: "b", deps: []Dependency{shared}, result: NewSuccessfulResult("ok")},
+			)...),
+			wantSteps: []string{"a=pass", "b=pass"},
+			wantDeps:  []string{"shared=pass"},
+		},
+		{
+			name: "stages wait for every lane before them",
+			check: NewCheck("test", "test", Independent(
+				fakeStep{name: "a", result: NewSuccessfulResult("ok")},
+				fakeStep{name: "b", result: NewFailureResult(fmt.Errorf("boom"))},
+			)...).Then([]Step{
+				fakeStep{name: "c", deps: []Dependency{shared}, result: NewSuccessfulResult("ok")},
+			}),
+			wantSteps: []string{"a=pass", "b=fail", "c=skip"},
+			wantDeps:  []string{"shared=skip"},
+		},
+		{
+			name: "after stages wait for every lane before them however it went",
+			check: NewCheck("test", "test", Independent(
+				fakeStep{name: "a", result: NewSuccessfulResult("ok")},
+				fakeStep{name: "b", deps: []Dependency{failing}, result: NewSuccessfulResult("ok")},
+			)...).After([]Step{
+				fakeStep{name: "c", deps: []Dependency{shared}, result: NewSuccessfulResult("ok")},
+				fakeStep{name: "d", deps: []Dependency{failing}, result: NewSuccessfulResult("ok")},
+				fakeStep{name: "e", result: NewSuccessfulResult("ok")},
+			}),
+			wantSteps: []string{"a=pass", "b=skip", "c=pass", "d=skip", "e=skip"},
+			wantDeps:  []string{"failing=fail", "shared=pass"},
+		},
+		{
+			name: "dependencies of a later step wait for the lane",
+			check: NewCheck("test", "test", []Step{
+				fakeStep{name: "a", result: NewFailureResult(fmt.Errorf("boom"))},
+				fakeStep{name: "b", deps: []Dependency{shared}, result: NewSuccessfulResult("ok")},
+			}),
+			wantSteps: []string{"a=fail", "b=skip"},
+			wantDeps:  []string{"shared=skip"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			depResults, results := tt.check.Run(context.Background(), NewDependencies(), &Config{Parallelism: 4})
+			assert.Equal(t, tt.wantSteps, summarize(results))
+			assert.Equal(t, tt.wantDeps, summarize(depResults))
+		})
+	}
+}
+
+// flakyStep fails until it has been attempted enough times
+type flakyStep struct {
+	fakeStep
+	failures int
+	attempts *int
+	delay    time.Duration
+}
+
+func (f flakyStep) Run(ctx context.Context, deps *Deps) Results {
+	*f.attempts++
+	if *f.attempts <= f.failures {
+		return NewResults(f, NewFailureResult(fmt.Errorf("attempt %d failed", *f.attempts)))
+	}
+	select {
+	case <-time.After(f.delay):
+	case <-ctx.Done():
+	}
+	return f.fakeStep.Run(ctx, deps)
+}
+
+func TestCheck_RunSkipReasons(t *testing.T) {
+	var shutdowns []string
+	failing := fakeDependency{name: "failing", result: NewFailureResult(fmt.Errorf("boom")), shutdowns: &shutdowns}
+	c := NewCheck("test", "test", []Step{
+		fakeStep{name: "a", deps: []Dependency{failing}, result: NewSuccessfulResult("ok")},
+	}, []Step{
+		fakeStep{name: "b", result: NewFailureResult(fmt.Errorf("boom"))},
+		fakeStep{name: "c", result: NewSuccessfulResult("ok")},
+		fakeStep{name: "d", result: NewSuccessfulResult("ok")},
+	})
+	_, results := c.Run(context.Background(), NewDependencies(), &Config{})
+	var reasons []string
+	for _, r := range results {
+		if r.Status() == StatusSkipped {
+			reasons = append(reasons, r.Steps()[0].Message())
+		}
+	}
+	assert.Equal(t, []string{"skipped: dependency failing failed", "skipped: b failed", "skipped: c was skipped"}, reasons)
+}
+
+// panicStep fails in a way the tool didn't plan for
+type panicStep struct {
+	fakeStep
+}
+
+func (p panicStep) Run(ctx context.Context, deps *Deps) Results {
+	panic("oops")
+}
+
+func TestCheck_RunPanic(t *testing.T) {
+	c := NewCheck("test", "test", []Step{panicStep{fakeStep{name: "a"}}})
+	_, results := c.Run(context.Background(), NewDependencies(), &Config{})
+	assert.Equal(t, []string{"a=error"}, summarize(results))
+	assert.EqualError(t, results[0].Steps()[0].Err(), "panic: oops")
+}
+
+func TestCheck_RunPolicy(t *testing.T) {
+	fastBackoff := Backoff{Initial: time.Millisecond}
+	tests := []struct {
+		name           string
+		failures       int
+		delay          time.Duration
+		policy         Policy
+		wantSuccessful bool
+		wantAttempts   int
+		wantLastErr    string
+	}{
+		{
+			name:           "no retries",
+			failures:       1,
+			policy:         Policy{Backoff: fastBackoff},
+			wantSuccessful: false,
+			wantAttempts:   1,
+			wantLastErr:    "attempt 1 failed",
+		},
+		{
+			name:           "succeeds after retries",
+			failures:       2,
+			policy:         Policy{MaxAttempts: 3, Backoff: fastBackoff},
+			wantSuccessful: true,
+			wantAttempts:   3,
+			wantLastErr:    "attempt 2 failed",
+		},
+		{
+			name:           "gives up after max attempts",
+			failures:       5,
+			policy:         Policy{MaxAttempts: 3, Backoff: fastBackoff},
+			wantSuccessful: false,
+			wantAttempts:   3,
+			wantLastErr:    "attempt 3 failed",
+		},
+		{
+			name:           "times out",
+			delay:          time.Second,
+			policy:         Policy{Timeout: 10 * time.Millisecond, Backoff: fastBackoff},
+			wantSuccessful: false,
+			wantAttempts:   1,
+			wantLastErr:    "step timed out after 10ms",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int
+			c := NewCheck("test", "test", []Step{
+				flakyStep{fakeStep{name: "step", result: NewSuccessfulResult("ok")}, tt.failures, &attempts, tt.delay},
+			})
+			_, results := c.Run(context.Background(), NewDependencies(), &Config{Policies: map[string]Policy{"step": tt.policy}})
+			assert.Len(t, results, 1)
+			assert.Equal(t, tt.wantAttempts, results[0].Attempts())
+			assert.EqualError(t, results[0].LastErr(), tt.wantLastErr)
+			assert.Equal(t, tt.wantSuccessful, results[0].Steps()[0].Successful())
+		})
+	}
+}
+
+func TestCheck_RunTiming(t *testing.T) {
+	var running, peak atomic.Int32
+	c := NewCheck("test", "test", []Step{
+		slowStep{fakeStep{name: "slow", result: NewSuccessfulResult("ok")}, &running, &peak},
+		fakeStep{name: "fast", result: NewFailureResult(fmt.Errorf("boom"))},
+		fakeStep{name: "skipped", result: NewSuccessfulResult("ok")},
+	})
+	_, results := c.Run(context.Background(), NewDependencies(), &Config{})
+	assert.False(t, results[0].Start().IsZero())
+	assert.GreaterOrEqual(t, results[0].Duration(), 20*time.Millisecond)
+	assert.False(t, results[1].Start().Before(results[0].Start().Add(results[0].Duration())))
+	assert.True(t, results[2].Start().IsZero())
+}
+
+// deadlineStep fails with its own error once its context is done and records whether attempts overlapped
+type deadlineStep struct {
+	fakeStep
+	running *atomic.Int32
+	overlap *atomic.Bool
+}
+
+func (d deadlineStep) Run(ctx context.Context, deps *Deps) Results {
+	if d.running.Add(1) > 1 {
+		d.overlap.Store(true)
+	}
+	defer d.running.Add(-1)
+	<-ctx.Done()
+	time.Sleep(5 * time.Millisecond)
+	return NewResults(d, NewFailureResultWithHelp(fmt.Errorf("pod is still pending"), "check the pod's events"))
+}
+
+func TestCheck_RunTimeoutKeepsResults(t *testing.T) {
+	var running atomic.Int32
+	var overlap atomic.Bool
+	c := NewCheck("test", "test", []Step{deadlineStep{fakeStep{name: "step"}, &running, &overlap}})
+	policy := Policy{Timeout: 10 * time.Millisecond, MaxAttempts: 3, Backoff: Backoff{Initial: time.Millisecond}}
+	_, results := c.Run(context.Background(), NewDependencies(), &Config{Policies: map[string]Policy{"step": policy}})
+	assert.Equal(t, 3, results[0].Attempts())
+	assert.EqualError(t, results[0].Steps()[0].Err(), "pod is still pending")
+	assert.False(t, overlap.Load(), "a retry doesn't start before the previous attempt returned")
+}
+
+// slowDependency only returns once its context is done
+type slowDependency struct {
+	fakeDependency
+}
+
+func (s slowDependency) Run(ctx context.Context, deps *Deps) (Option, Result) {
+	<-ctx.Done()
+	return Empty, NewFailureResult(ctx.Err())
+}
+
+func TestCheck_RunTimeoutShutsDownDependency(t *testing.T) {
+	var shutdowns []string
+	dep := slowDependency{fakeDependency{name: "slow", shutdowns: &shutdowns}}
+	c := NewCheck("test", "test", []Step{fakeStep{name: "step", deps: []Dependency{dep}, result: NewSuccessfulResult("ok")}})
+	policy := Policy{Timeout: 10 * time.Millisecond}
+	depResults, results := c.Run(context.Background(), NewDependencies(), &Config{Policies: map[string]Policy{"slow": policy}})
+	assert.Equal(t, []string{"slow=fail"}, summarize(depResults))
+	assert.Equal(t, []string{"step=skip"}, summarize(results))
+	assert.Equal(t, []string{"slow"}, shutdowns)
+}
+
+// partialStep reports how far it got once its context is done
+type partialStep struct {
+	fakeStep
+}
+
+func (p partialStep) Run(ctx context.Context, deps *Deps) Results {
+	<-ctx.Done()
+	return NewResults(p, NewAcceptableFailureResultWithHelp(ctx.Err(), "only 1 of 2 destinations were probed"))
+}
+
+func TestCheck_RunTimeoutKeepsPartialResults(t *testing.T) {
+	c := NewCheck("test", "test", []Step{
+		partialStep{fakeStep{name: "partial"}},
+		fakeStep{name: "next", result: NewSuccessfulResult("ok")},
+	})
+	policy := Policy{Timeout: 10 * time.Millisecond}
+	_, results := c.Run(context.Background(), NewDependencies(), &Config{Policies: map[string]Policy{"partial": policy}})
+	assert.Equal(t, []string{"partial=warn", "next=pass"}, summarize(results))
+}
+
+// stuckStep ignores its context and only returns once released
+type stuckStep struct {
+	fakeStep
+	release chan struct{}
+}
+
+func (s stuckStep) Run(ctx context.Context, deps *Deps) Results {
+	<-s.release
+	return NewResults(s, NewSuccessfulResult("ok"))
+}
+
+func TestCheck_RunCancelAbandonsStuckStep(t *testing.T) {
+	defer func(grace time.Duration) { timeoutGrace = grace }(timeoutGrace)
+	timeoutGrace = 10 * time.Millisecond
+	release := make(chan struct{})
+	defer close(release)
+	c := NewCheck("test", "test", []Step{stuckStep{fakeStep{name: "stuck"}, release}}).
+		WithFinalizers(fakeStep{name: "cleanup", result: NewSuccessfulResult("ok")})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, results := c.Run(ctx, NewDependencies(), &Config{})
+	assert.Equal(t, []string{"stuck=fail", "cleanup=pass"}, summarize(results))
+	assert.EqualError(t, results[0].Steps()[0].Err(), "stuck didn't return within 10ms of being cancelled")
+}
@@ -0,0 +1,61 @@
+package steps
+
+import "fmt"
+
+// Status is the outcome of a single result
+type Status int
+
+const (
+	// StatusPass means the step did what it set out to do
+	StatusPass Status = iota + 1
+	// StatusWarning is a problem that doesn't stop the check, like a collector that already exists
+	StatusWarning
+	// StatusFail means the step found a problem and the steps after it can't run
+	StatusFail
+	// StatusSkipped means the step never ran, usually because something it needed failed
+	StatusSkipped
+	// StatusError means the tool itself failed rather than the thing being checked
+	StatusError
+)
+
+var statusNames = map[Status]string{
+	StatusPass:    "pass",
+	StatusWarning: "warn",
+	StatusFail:    "fail",
+	StatusSkipped: "skip",
+	StatusError:   "error",
+}
+
+// severity orders statuses from best to worst when aggregating them
+var severity = map[Status]int{
+	StatusPass:    0,
+	StatusSkipped: 1,
+	StatusWarning: 2,
+	StatusFail:    3,
+	StatusError:   4,
+}
+
+func (s Status) String() string {
+	if name, ok := statusNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("Status(%d)", int(s))
+}
+
+// Stops reports whether steps that need this result must not run. Unknown statuses stop, so that a zero Result
+// is never mistaken for a success.
+func (s Status) Stops() bool {
+	return s != StatusPass && s != StatusWarning && s != StatusSkipped
+}
+
+// Worse reports whether s is more severe than other
+func (s Status) Worse(other Status) bool {
+	return s.severity() > other.severity()
+}
+
+func (s Status) severity() int {
+	if v, ok := severity[s]; ok {
+		return v
+	}
+	return severity[StatusError]
+}
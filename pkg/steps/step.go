@@ -2,22 +2,44 @@ package steps
 
 import (
 	"context"
+	"errors"
+	"time"
 )
 
 type Results struct {
 	results []Result
 	d       Describable
+
+	// attempts is how many times the step or dependency ran to produce these results
+	attempts int
+	// lastErr is the error of the last failed attempt, even when a retry succeeded
+	lastErr error
+	// start is when the first attempt started
+	start time.Time
+	// duration is how long every attempt took, backoff included
+	duration time.Duration
 }
 
 func (r Results) ShouldStop() bool {
 	for _, result := range r.results {
-		if !result.Successful() && !result.ShouldContinue() {
+		if result.Status().Stops() {
 			return true
 		}
 	}
 	return false
 }
 
+// Status is the worst status of every result
+func (r Results) Status() Status {
+	status := StatusPass
+	for _, result := range r.results {
+		if result.Status().Worse(status) {
+			status = result.Status()
+		}
+	}
+	return status
+}
+
 func (r Results) StepName() string {
 	return r.d.Name()
 }
@@ -26,79 +48,142 @@ func (r Results) Steps() []Result {
 	return r.results
 }
 
+func (r Results) Attempts() int {
+	return r.attempts
+}
+
+func (r Results) LastErr() error {
+	return r.lastErr
+}
+
+func (r Results) Start() time.Time {
+	return r.start
+}
+
+func (r Results) Duration() time.Duration {
+	return r.duration
+}
+
+// err is the error of the first result that stops the check
+func (r Results) err() error {
+	for _, result := range r.results {
+		if !result.Status().Stops() {
+			continue
+		}
+		if result.Err() != nil {
+			return result.Err()
+		}
+		return errors.New(result.Message())
+	}
+	return nil
+}
+
 func NewResults(s Describable, r ...Result) Results {
 	return Results{d: s, results: r}
 }
 
 type Result struct {
-	// successful if the check completed without error
-	successful bool
+	// status is the outcome of the step
+	status Status
 
 	// err is any error that the step encountered
 	err error
 
-	// shouldContinue is whether the check should continue
-	shouldContinue bool
-
 	// message is an optional string that informs something about this check
 	message string
+
+	// attributes are optional machine-readable details about the result, like the names of the pods that were found
+	attributes map[string]any
+}
+
+func (r Result) Status() Status {
+	return r.status
 }
 
+// Successful if the step completed without any problem
 func (r Result) Successful() bool {
-	return r.successful
+	return r.status == StatusPass
 }
 
 func (r Result) Err() error {
 	return r.err
 }
 
+// ShouldContinue is whether the steps that need this one can still run
 func (r Result) ShouldContinue() bool {
-	return r.shouldContinue
+	return !r.status.Stops()
 }
 
 func (r Result) Message() string {
 	return r.message
 }
 
+func (r Result) Attributes() map[string]any {
+	return r.attributes
+}
+
+// WithAttribute returns a copy of the result with the attribute set
+func (r Result) WithAttribute(key string, value any) Result {
+	attributes := make(map[string]any, len(r.attributes)+1)
+	for k, v := range r.attributes {
+		attributes[k] = v
+	}
+	attributes[key] = value
+	r.attributes = attributes
+	return r
+}
+
 func NewSuccessfulResult(message string) Result {
 	return Result{
-		successful:     true,
-		shouldContinue: true,
-		message:        message,
+		status:  StatusPass,
+		message: message,
 	}
 }
 
 func NewFailureResult(err error) Result {
 	return Result{
-		successful:     false,
-		err:            err,
-		shouldContinue: false,
+		status: StatusFail,
+		err:    err,
 	}
 }
 
+// NewAcceptableFailureResult is a warning, the step had a problem that the check can carry on from
 func NewAcceptableFailureResult(err error) Result {
 	return Result{
-		successful:     false,
-		err:            err,
-		shouldContinue: true,
+		status: StatusWarning,
+		err:    err,
 	}
 }
 
 func NewAcceptableFailureResultWithHelp(err error, help string) Result {
 	return Result{
-		successful:     false,
-		err:            err,
-		message:        help,
-		shouldContinue: true,
+		status:  StatusWarning,
+		err:     err,
+		message: help,
 	}
 }
 
 func NewFailureResultWithHelp(err error, help string) Result {
 	return Result{
-		successful:     false,
-		err:            err,
-		message:        help,
-		shouldContinue: false,
+		status:  StatusFail,
+		err:     err,
+		message: help,
+	}
+}
+
+// NewSkippedResult is for a step that never ran, the reason explains why
+func NewSkippedResult(reason string) Result {
+	return Result{
+		status:  StatusSkipped,
+		message: reason,
+	}
+}
+
+// NewErrorResult is for a step that couldn't check anything because the tool itself failed
+func NewErrorResult(err error) Result {
+	return Result{
+		status: StatusError,
+		err:    err,
 	}
 }
 
@@ -0,0 +1,50 @@
+package steps
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResult_WithAttribute(t *testing.T) {
+	base := NewSuccessfulResult("ok").WithAttribute("first", 1)
+	extended := base.WithAttribute("second", []string{"a", "b"})
+	assert.Equal(t, map[string]any{"first": 1}, base.Attributes())
+	assert.Equal(t, map[string]any{"first": 1, "second": []string{"a", "b"}}, extended.Attributes())
+	assert.Nil(t, NewSuccessfulResult("ok").Attributes())
+}
+
+func TestResults_Status(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []Result
+		want    Status
+	}{
+		{
+			name:    "all passed",
+			results: []Result{NewSuccessfulResult("ok"), NewSuccessfulResult("ok")},
+			want:    StatusPass,
+		},
+		{
+			name:    "warning",
+			results: []Result{NewSuccessfulResult("ok"), NewAcceptableFailureResult(fmt.Errorf("meh"))},
+			want:    StatusWarning,
+		},
+		{
+			name:    "failure wins over warning",
+			results: []Result{NewFailureResult(fmt.Errorf("boom")), NewAcceptableFailureResult(fmt.Errorf("meh"))},
+			want:    StatusFail,
+		},
+		{
+			name:    "error wins over failure",
+			results: []Result{NewFailureResult(fmt.Errorf("boom")), NewErrorResult(fmt.Errorf("oops"))},
+			want:    StatusError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewResults(fakeStep{name: "step"}, tt.results...).Status())
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package throughput
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lightstep/collector-cluster-check/pkg/load"
+	"github.com/lightstep/collector-cluster-check/pkg/otlp"
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+	"github.com/lightstep/collector-cluster-check/pkg/steps/dependencies"
+)
+
+const (
+	// minAchieved is the share of the asked for rate that has to be generated and exported for the load to pass
+	minAchieved = 0.9
+	// maxSaturation is how full the exporter queue may get before it's worth a warning
+	maxSaturation = 0.8
+)
+
+type GenerateLoad struct {
+	endpoint string
+	insecure bool
+}
+
+func NewGenerateLoad(endpoint string, insecure bool) GenerateLoad {
+	return GenerateLoad{endpoint: endpoint, insecure: insecure}
+}
+
+var _ steps.Step = GenerateLoad{}
+
+func (c GenerateLoad) Name() string {
+	if c.endpoint == "" {
+		return "Generate load"
+	}
+	return fmt.Sprintf("Generate load @ %s", c.endpoint)
+}
+
+func (c GenerateLoad) Description() string {
+	return "Sends spans, metric points and log records at the configured rates and measures how the exporters keep up"
+}
+
+func (c GenerateLoad) Run(ctx context.Context, deps *steps.Deps) steps.Results {
+	if deps.LoadGenerator == nil {
+		return steps.NewResults(c, steps.NewFailureResultWithHelp(nil, "load generator not set"))
+	}
+	results, err := deps.LoadGenerator.Run(ctx)
+	if err != nil {
+		return steps.NewResults(c, steps.NewFailureResult(err))
+	}
+	toReturn := make([]steps.Result, len(results))
+	for i, r := range results {
+		toReturn[i] = outcome(r)
+	}
+	return steps.NewResults(c, toReturn...)
+}
+
+// outcome fails a signal whose exports failed, and warns when items were dropped or the rate couldn't be kept up
+func outcome(r load.Result) steps.Result {
+	s := r.Stats
+	message := fmt.Sprintf("%s: generated %.0f/s of %d/s, exported %.0f/s, %d failed, %d dropped, p95 export latency %s",
+		r.Signal, r.Achieved, r.Rate, s.Throughput, s.Failed, s.Dropped, s.Latency.P95)
+	var result steps.Result
+	switch {
+	case s.Failed > 0:
+		result = steps.NewFailureResultWithHelp(fmt.Errorf("%d %s failed to export: %w", s.Failed, r.Signal, s.LastErr), otlp.Diagnose(s.LastErr).Help)
+	case s.Dropped > 0:
+		result = steps.NewAcceptableFailureResultWithHelp(fmt.Errorf("%d %s were dropped", s.Dropped, r.Signal), "the exporter queue filled up, the endpoint can't keep up with this rate")
+	case r.Saturation >= maxSaturation:
+		result = steps.NewAcceptableFailureResultWithHelp(nil, fmt.Sprintf("%s, the exporter queue was %.0f%% full", message, r.Saturation*100))
+	case r.Achieved < minAchieved*float64(r.Rate):
+		result = steps.NewAcceptableFailureResultWithHelp(nil, fmt.Sprintf("%s, this machine couldn't generate the load fast enough", message))
+	default:
+		result = steps.NewSuccessfulResult(message)
+	}
+	return result.WithAttribute("signal", string(r.Signal)).
+		WithAttribute("rate", fmt.Sprintf("%d/s", r.Rate)).
+		WithAttribute("achieved", fmt.Sprintf("%.1f/s", r.Achieved)).
+		WithAttribute("throughput", fmt.Sprintf("%.1f/s", s.Throughput)).
+		WithAttribute("generated", s.Generated).
+		WithAttribute("exported", s.Exported).
+		WithAttribute("failed", s.Failed).
+		WithAttribute("dropped", s.Dropped).
+		WithAttribute("exports", s.Exports).
+		WithAttribute("p50", s.Latency.P50.String()).
+		WithAttribute("p95", s.Latency.P95.String()).
+		WithAttribute("p99", s.Latency.P99.String()).
+		WithAttribute("max", s.Latency.Max.String()).
+		WithAttribute("saturation", fmt.Sprintf("%.0f%%", r.Saturation*100))
+}
+
+func (c GenerateLoad) Dependencies(config *steps.Config) []steps.Dependency {
+	if len(c.endpoint) > 0 {
+		return []steps.Dependency{dependencies.NewCreateLoadGenerator(c.endpoint, c.insecure, config.Http, config.Headers, config.Temporality, config.Load, config.RunID)}
+	}
+	return []steps.Dependency{dependencies.CreateLoadGeneratorFromConfig(config)}
+}
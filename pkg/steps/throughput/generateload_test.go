@@ -0,0 +1,65 @@
+package throughput
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lightstep/collector-cluster-check/pkg/load"
+	"github.com/lightstep/collector-cluster-check/pkg/otlp"
+	"github.com/lightstep/collector-cluster-check/pkg/steps"
+)
+
+func TestOutcome(t *testing.T) {
+	kept := load.Stats{Generated: 1000, Exported: 1000, Throughput: 100, Exports: 10, Latency: load.Percentiles{P95: 20 * time.Millisecond}}
+	tests := []struct {
+		name        string
+		result      load.Result
+		wantStatus  steps.Status
+		wantMessage string
+	}{
+		{
+			name:        "kept up",
+			result:      load.Result{Signal: load.SignalSpans, Rate: 100, Achieved: 100, Stats: kept, Saturation: 0.1},
+			wantStatus:  steps.StatusPass,
+			wantMessage: "spans: generated 100/s of 100/s, exported 100/s, 0 failed, 0 dropped, p95 export latency 20ms",
+		},
+		{
+			name: "failed exports",
+			result: load.Result{Signal: load.SignalLogRecords, Rate: 100, Achieved: 100, Stats: load.Stats{
+				Generated: 1000, Failed: 1000, LastErr: &otlp.HTTPError{StatusCode: 401, Status: "401 Unauthorized"},
+			}},
+			wantStatus:  steps.StatusFail,
+			wantMessage: otlp.Diagnose(&otlp.HTTPError{StatusCode: 401}).Help,
+		},
+		{
+			name:        "dropped",
+			result:      load.Result{Signal: load.SignalSpans, Rate: 100, Achieved: 100, Stats: load.Stats{Generated: 1000, Exported: 900, Dropped: 100}, Saturation: 1},
+			wantStatus:  steps.StatusWarning,
+			wantMessage: "the exporter queue filled up, the endpoint can't keep up with this rate",
+		},
+		{
+			name:        "saturated",
+			result:      load.Result{Signal: load.SignalSpans, Rate: 100, Achieved: 100, Stats: kept, Saturation: 0.9},
+			wantStatus:  steps.StatusWarning,
+			wantMessage: "spans: generated 100/s of 100/s, exported 100/s, 0 failed, 0 dropped, p95 export latency 20ms, the exporter queue was 90% full",
+		},
+		{
+			name:        "rate not reached",
+			result:      load.Result{Signal: load.SignalMetricPoints, Rate: 100, Achieved: 50, Stats: kept},
+			wantStatus:  steps.StatusWarning,
+			wantMessage: "metric points: generated 50/s of 100/s, exported 100/s, 0 failed, 0 dropped, p95 export latency 20ms, this machine couldn't generate the load fast enough",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := outcome(tt.result)
+			assert.Equal(t, tt.wantStatus, r.Status())
+			assert.Equal(t, tt.wantMessage, r.Message())
+			assert.Equal(t, string(tt.result.Signal), r.Attributes()["signal"])
+			assert.Equal(t, fmt.Sprintf("%.0f%%", tt.result.Saturation*100), r.Attributes()["saturation"])
+		})
+	}
+}
@@ -2,7 +2,6 @@ package traces
 
 import (
 	"context"
-	"strings"
 
 	"github.com/lightstep/collector-cluster-check/pkg/steps"
 	"github.com/lightstep/collector-cluster-check/pkg/steps/dependencies"
@@ -29,23 +28,15 @@ func (c ShutdownTracer) Description() string {
 
 func (c ShutdownTracer) Run(ctx context.Context, deps *steps.Deps) steps.Results {
 	err := deps.TracerProvider.ForceFlush(ctx)
-	if err != nil && strings.Contains(err.Error(), "DeadlineExceeded") {
-		return steps.NewResults(c, steps.NewFailureResultWithHelp(err, "A connection couldn't be established, check firewall rules"))
-	} else if err != nil {
-		return steps.NewResults(c, steps.NewFailureResultWithHelp(err, "This could mean an incorrect access token was used"))
+	if err == nil {
+		err = deps.TracerProvider.Shutdown(ctx)
 	}
-	err = deps.TracerProvider.Shutdown(ctx)
-	if err != nil && strings.Contains(err.Error(), "DeadlineExceeded") {
-		return steps.NewResults(c, steps.NewFailureResultWithHelp(err, "A connection couldn't be established, check firewall rules"))
-	} else if err != nil {
-		return steps.NewResults(c, steps.NewFailureResultWithHelp(err, "This could mean an incorrect access token was used"))
-	}
-	return steps.NewResults(c, steps.NewSuccessfulResult("shutdown tracer provider"))
+	return steps.NewResults(c, steps.ShutdownResults(err, deps.TraceExportErrors, "shutdown tracer provider")...)
 }
 
 func (c ShutdownTracer) Dependencies(config *steps.Config) []steps.Dependency {
 	if len(c.endpoint) > 0 {
-		return []steps.Dependency{dependencies.NewCreateTraceProvider(c.endpoint, c.insecure, config.Http, config.Token)}
+		return []steps.Dependency{dependencies.NewCreateTraceProvider(c.endpoint, c.insecure, config.Http, config.Headers, config.RunID)}
 	}
 	return []steps.Dependency{dependencies.CreateTracerProviderFromConfig(config)}
 }
@@ -3,6 +3,10 @@ package traces
 import (
 	"context"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/lightstep/collector-cluster-check/pkg/loopback"
 	"github.com/lightstep/collector-cluster-check/pkg/steps"
 	"github.com/lightstep/collector-cluster-check/pkg/steps/dependencies"
 )
@@ -20,7 +24,7 @@ var _ steps.Step = StartTrace{}
 
 const (
 	instrumentation = "collector-cluster-check"
-	operationName   = "traceChecker.Run"
+	OperationName   = "traceChecker.Run"
 )
 
 func (c StartTrace) Name() string {
@@ -32,14 +36,20 @@ func (c StartTrace) Description() string {
 }
 
 func (c StartTrace) Run(ctx context.Context, deps *steps.Deps) steps.Results {
-	_, span := deps.TracerProvider.Tracer(instrumentation).Start(ctx, operationName)
+	_, span := deps.TracerProvider.Tracer(instrumentation).Start(ctx, OperationName,
+		trace.WithAttributes(attribute.String(loopback.StepAttribute, c.Name())))
 	span.End()
+	if deps.LoopbackSent != nil {
+		sc := span.SpanContext()
+		traceID, spanID := sc.TraceID(), sc.SpanID()
+		deps.LoopbackSent.RecordSpan(loopback.Span{Name: OperationName, TraceID: traceID[:], SpanID: spanID[:], Attributes: map[string]string{loopback.StepAttribute: c.Name()}})
+	}
 	return steps.NewResults(c, steps.NewSuccessfulResult("started and ended trace"))
 }
 
 func (c StartTrace) Dependencies(config *steps.Config) []steps.Dependency {
 	if len(c.endpoint) > 0 {
-		return []steps.Dependency{dependencies.NewCreateTraceProvider(c.endpoint, c.insecure, config.Http, config.Token)}
+		return []steps.Dependency{dependencies.NewCreateTraceProvider(c.endpoint, c.insecure, config.Http, config.Headers, config.RunID)}
 	}
 	return []steps.Dependency{dependencies.CreateTracerProviderFromConfig(config)}
 }